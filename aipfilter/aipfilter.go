@@ -0,0 +1,391 @@
+// Package aipfilter compiles Google AIP-160 filter strings and AIP-132
+// order_by strings into qbr conditions and sorts.
+//
+// A filter such as
+//
+//	create_time > "2024-01-01T00:00:00Z" AND NOT labels.env:prod
+//
+// is compiled into a greater than condition on the field declared for
+// create_time and a negated equality condition on the field declared for
+// labels.env. Field paths are resolved through the declared fields, and values
+// are converted to the declared field types.
+//
+// As defined by AIP-160, adjacent terms are implicitly joined with AND, OR
+// binds tighter than AND, and NOT or "-" negates a term. The ":" (has)
+// comparator is equality for scalar fields, and "field:*" checks that the
+// field is set. String values with "*" wildcards are compared with LIKE.
+package aipfilter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tyrenix/qbr"
+	"github.com/tyrenix/qbr/domain"
+)
+
+// Type is the declared type of a filterable field.
+type Type int
+
+// Field types.
+const (
+	TypeString    Type = iota // string
+	TypeInt                   // int64
+	TypeFloat                 // float64
+	TypeBool                  // bool
+	TypeTimestamp             // time.Time, RFC 3339
+	TypeDuration              // time.Duration, e.g. "20s"
+)
+
+// Field declares a filterable field.
+type Field struct {
+	Field *domain.Field // Database field.
+	Type  Type          // Value type.
+}
+
+// Schema declares the fields that may be used in filters and order_by by
+// field path.
+type Schema struct {
+	Fields map[string]Field
+}
+
+// ParseFilter compiles the AIP-160 filter into conditions. An empty filter
+// yields no conditions.
+func (s *Schema) ParseFilter(filter string) ([]domain.Condition, error) {
+	// tokenize filter
+	tokens, err := tokenize(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// empty filter
+	if len(tokens) == 1 {
+		return nil, nil
+	}
+
+	// parse expression
+	p := &parser{schema: s, tokens: tokens}
+	cond, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	// check end of expression
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", t.value, t.pos)
+	}
+
+	// unwrap top level and
+	if cond.Operator == domain.OperatorAnd {
+		return cond.Value.([]domain.Condition), nil
+	}
+
+	// return condition
+	return []domain.Condition{cond}, nil
+}
+
+// ParseOrderBy compiles the AIP-132 order_by string, a comma separated list of
+// field paths optionally followed by "desc", into sorts.
+func (s *Schema) ParseOrderBy(orderBy string) ([]domain.Sort, error) {
+	// empty order by
+	if strings.TrimSpace(orderBy) == "" {
+		return nil, nil
+	}
+
+	// sorts
+	var sorts []domain.Sort
+	for _, item := range strings.Split(orderBy, ",") {
+		// split path and direction
+		parts := strings.Fields(item)
+		if len(parts) == 0 || len(parts) > 2 {
+			return nil, fmt.Errorf("invalid order_by item: %q", item)
+		}
+
+		// get field
+		field, err := s.field(parts[0])
+		if err != nil {
+			return nil, err
+		}
+
+		// get direction
+		sortType := domain.SortAsc
+		if len(parts) == 2 {
+			switch parts[1] {
+			case "asc":
+			case "desc":
+				sortType = domain.SortDesc
+			default:
+				return nil, fmt.Errorf("invalid order_by direction: %q", parts[1])
+			}
+		}
+
+		// add sort
+		sorts = append(sorts, domain.Sort{Field: field.Field, Type: sortType})
+	}
+
+	// return sorts
+	return sorts, nil
+}
+
+// field returns the declared field of the path, or an error if it is not
+// declared.
+func (s *Schema) field(path string) (Field, error) {
+	// get field
+	field, ok := s.Fields[path]
+	if !ok {
+		return Field{}, fmt.Errorf("%w: %q", qbr.ErrFieldNotAllowed, path)
+	}
+
+	// return field
+	return field, nil
+}
+
+// comparatorOperators maps comparators to operator types.
+var comparatorOperators = map[string]domain.OperatorType{
+	"=":  domain.OperatorEqual,
+	":":  domain.OperatorEqual,
+	"!=": domain.OperatorNotEqual,
+	"<":  domain.OperatorLessThan,
+	"<=": domain.OperatorLessThanOrEqual,
+	">":  domain.OperatorGreaterThan,
+	">=": domain.OperatorGreaterThanOrEqual,
+}
+
+// parser is a recursive descent parser of filter expressions.
+type parser struct {
+	schema *Schema
+	tokens []token
+	pos    int
+}
+
+// peek returns the current token.
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+// next returns the current token and advances to the next one.
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// isKeyword reports whether the current token is the given keyword.
+func (p *parser) isKeyword(keyword string) bool {
+	t := p.peek()
+	return t.kind == tokenText && t.value == keyword
+}
+
+// startsTerm reports whether the current token can start a term.
+func (p *parser) startsTerm() bool {
+	t := p.peek()
+	switch t.kind {
+	case tokenLParen, tokenMinus:
+		return true
+	case tokenText:
+		return t.value != "AND" && t.value != "OR"
+	}
+	return false
+}
+
+// parseExpression parses: sequence { "AND" sequence }.
+func (p *parser) parseExpression() (domain.Condition, error) {
+	// operands
+	var conds []domain.Condition
+	for {
+		// parse sequence
+		cond, err := p.parseSequence()
+		if err != nil {
+			return domain.Condition{}, err
+		}
+		conds = appendFlat(conds, cond, domain.OperatorAnd)
+
+		// check and keyword
+		if !p.isKeyword("AND") {
+			break
+		}
+		p.next()
+	}
+
+	// return condition
+	return join(conds, domain.OperatorAnd), nil
+}
+
+// parseSequence parses: factor { factor }, implicitly joined with AND.
+func (p *parser) parseSequence() (domain.Condition, error) {
+	// operands
+	var conds []domain.Condition
+	for {
+		// parse factor
+		cond, err := p.parseFactor()
+		if err != nil {
+			return domain.Condition{}, err
+		}
+		conds = appendFlat(conds, cond, domain.OperatorAnd)
+
+		// check next factor
+		if !p.startsTerm() {
+			break
+		}
+	}
+
+	// return condition
+	return join(conds, domain.OperatorAnd), nil
+}
+
+// parseFactor parses: term { "OR" term }.
+func (p *parser) parseFactor() (domain.Condition, error) {
+	// operands
+	var conds []domain.Condition
+	for {
+		// parse term
+		cond, err := p.parseTerm()
+		if err != nil {
+			return domain.Condition{}, err
+		}
+		conds = appendFlat(conds, cond, domain.OperatorOr)
+
+		// check or keyword
+		if !p.isKeyword("OR") {
+			break
+		}
+		p.next()
+	}
+
+	// return condition
+	return join(conds, domain.OperatorOr), nil
+}
+
+// parseTerm parses: [ "NOT" | "-" ] simple.
+func (p *parser) parseTerm() (domain.Condition, error) {
+	// negation
+	if p.isKeyword("NOT") || p.peek().kind == tokenMinus {
+		p.next()
+		cond, err := p.parseSimple()
+		if err != nil {
+			return domain.Condition{}, err
+		}
+		return qbr.Not(cond), nil
+	}
+
+	// simple
+	return p.parseSimple()
+}
+
+// parseSimple parses a parenthesized expression or a comparison.
+func (p *parser) parseSimple() (domain.Condition, error) {
+	// parenthesized expression
+	if p.peek().kind == tokenLParen {
+		p.next()
+		cond, err := p.parseExpression()
+		if err != nil {
+			return domain.Condition{}, err
+		}
+		if t := p.next(); t.kind != tokenRParen {
+			return domain.Condition{}, fmt.Errorf("expected ')' at position %d", t.pos)
+		}
+		return cond, nil
+	}
+
+	// get member
+	member := p.next()
+	if member.kind != tokenText {
+		return domain.Condition{}, fmt.Errorf("expected field at position %d", member.pos)
+	}
+
+	// get comparator
+	cmp := p.next()
+	if cmp.kind != tokenComparator {
+		return domain.Condition{}, fmt.Errorf("expected comparator after %q at position %d", member.value, cmp.pos)
+	}
+
+	// get field
+	field, err := p.schema.field(member.value)
+	if err != nil {
+		return domain.Condition{}, err
+	}
+
+	// get argument
+	arg := p.next()
+	if arg.kind != tokenText && arg.kind != tokenString {
+		return domain.Condition{}, fmt.Errorf("expected value at position %d", arg.pos)
+	}
+
+	// presence check
+	if cmp.value == ":" && arg.kind == tokenText && arg.value == "*" {
+		return qbr.NoEq(field.Field, domain.ValueNull), nil
+	}
+
+	// null comparison
+	op := comparatorOperators[cmp.value]
+	if arg.kind == tokenText && arg.value == "null" {
+		if op != domain.OperatorEqual && op != domain.OperatorNotEqual {
+			return domain.Condition{}, fmt.Errorf("comparator %q is not supported for null", cmp.value)
+		}
+		return domain.Condition{Field: field.Field, Operator: op, Value: domain.ValueNull}, nil
+	}
+
+	// wildcard string comparison
+	if field.Type == TypeString && strings.Contains(arg.value, "*") &&
+		(op == domain.OperatorEqual || op == domain.OperatorNotEqual) {
+		// create like pattern
+		like := qbr.Like(field.Field, strings.ReplaceAll(qbr.EscapeLike(arg.value), "*", "%"))
+
+		// negated wildcard
+		if op == domain.OperatorNotEqual {
+			return qbr.Not(like), nil
+		}
+		return like, nil
+	}
+
+	// convert value
+	value, err := convert(arg.value, field.Type)
+	if err != nil {
+		return domain.Condition{}, fmt.Errorf("invalid value for field %s: %w", member.value, err)
+	}
+
+	// return condition
+	return domain.Condition{Field: field.Field, Operator: op, Value: value}, nil
+}
+
+// appendFlat appends the condition, flattening it if it has the same logical
+// operator.
+func appendFlat(conds []domain.Condition, cond domain.Condition, op domain.OperatorType) []domain.Condition {
+	if cond.Operator == op {
+		return append(conds, cond.Value.([]domain.Condition)...)
+	}
+	return append(conds, cond)
+}
+
+// join joins the conditions with the logical operator, returning a single
+// condition as it is.
+func join(conds []domain.Condition, op domain.OperatorType) domain.Condition {
+	if len(conds) == 1 {
+		return conds[0]
+	}
+	return domain.Condition{Operator: op, Value: conds}
+}
+
+// convert converts the raw value to the given type.
+func convert(raw string, t Type) (any, error) {
+	switch t {
+	case TypeString:
+		return raw, nil
+	case TypeInt:
+		return strconv.ParseInt(raw, 10, 64)
+	case TypeFloat:
+		return strconv.ParseFloat(raw, 64)
+	case TypeBool:
+		return strconv.ParseBool(raw)
+	case TypeTimestamp:
+		return time.Parse(time.RFC3339Nano, raw)
+	case TypeDuration:
+		return time.ParseDuration(raw)
+	default:
+		return nil, fmt.Errorf("unsupported field type: %d", t)
+	}
+}
@@ -0,0 +1,151 @@
+package aipfilter
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tyrenix/qbr"
+	"github.com/tyrenix/qbr/domain"
+)
+
+var (
+	nameField    = qbr.NewField(qbr.WithDB("name"))
+	ageField     = qbr.NewField(qbr.WithDB("age"))
+	envField     = qbr.NewField(qbr.WithDB("env"))
+	createdField = qbr.NewField(qbr.WithDB("create_time"))
+	timeoutField = qbr.NewField(qbr.WithDB("timeout"))
+	schema       = &Schema{
+		Fields: map[string]Field{
+			"name":        {Field: nameField, Type: TypeString},
+			"age":         {Field: ageField, Type: TypeInt},
+			"labels.env":  {Field: envField, Type: TypeString},
+			"create_time": {Field: createdField, Type: TypeTimestamp},
+			"timeout":     {Field: timeoutField, Type: TypeDuration},
+		},
+	}
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  string
+		want    string
+		args    []any
+		wantErr error
+	}{
+		{
+			name:   "not and has",
+			filter: `create_time > "2024-01-01T00:00:00Z" AND NOT labels.env:prod`,
+			want:   "SELECT * FROM users WHERE create_time > $1 AND NOT (env = $2)",
+			args:   []any{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), "prod"},
+		},
+		{
+			name:   "or binds tighter than implicit and",
+			filter: "age >= 18 name = a OR name = b",
+			want:   "SELECT * FROM users WHERE age >= $1 AND (name = $2 OR name = $3)",
+			args:   []any{int64(18), "a", "b"},
+		},
+		{
+			name:   "minus negation and group",
+			filter: "-(age < 10 OR age > 20) AND timeout <= 20s",
+			want:   "SELECT * FROM users WHERE NOT ((age < $1 OR age > $2)) AND timeout <= $3",
+			args:   []any{int64(10), int64(20), 20 * time.Second},
+		},
+		{
+			name:   "presence and null",
+			filter: "labels.env:* AND name = null",
+			want:   "SELECT * FROM users WHERE env IS NOT NULL AND name IS NULL",
+		},
+		{
+			name:   "wildcard escapes like characters",
+			filter: `name = "50%_*" AND name != "*x"`,
+			want:   `SELECT * FROM users WHERE name LIKE $1 AND NOT (name LIKE $2)`,
+			args:   []any{`50\%\_%`, "%x"},
+		},
+		{
+			name:   "negative number",
+			filter: "age > -5",
+			want:   "SELECT * FROM users WHERE age > $1",
+			args:   []any{int64(-5)},
+		},
+		{
+			name:   "empty filter",
+			filter: "  ",
+			want:   "SELECT * FROM users",
+		},
+		{name: "unknown field", filter: "password = x", wantErr: qbr.ErrFieldNotAllowed},
+		{name: "invalid value", filter: "age = x", wantErr: errAny},
+		{name: "null comparator", filter: "age > null", wantErr: errAny},
+		{name: "missing comparator", filter: "age", wantErr: errAny},
+		{name: "unclosed group", filter: "(age = 1", wantErr: errAny},
+		{name: "unterminated string", filter: `name = "a`, wantErr: errAny},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conds, err := schema.ParseFilter(tt.filter)
+			if tt.wantErr != nil {
+				if err == nil || (tt.wantErr != errAny && !errors.Is(err, tt.wantErr)) {
+					t.Fatalf("ParseFilter() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseFilter() error = %v", err)
+			}
+
+			// build query
+			got, args, err := qbr.NewRead().Where(conds...).ToSql("users", qbr.SqlDollar)
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ToSql() = %q, want %q", got, tt.want)
+			}
+			if len(args) != 0 || len(tt.args) != 0 {
+				if !reflect.DeepEqual(args, tt.args) {
+					t.Errorf("args = %#v, want %#v", args, tt.args)
+				}
+			}
+		})
+	}
+}
+
+func TestParseOrderBy(t *testing.T) {
+	tests := []struct {
+		name    string
+		orderBy string
+		want    []domain.Sort
+		wantErr bool
+	}{
+		{
+			name:    "directions",
+			orderBy: "age desc, name",
+			want: []domain.Sort{
+				{Field: ageField, Type: domain.SortDesc},
+				{Field: nameField, Type: domain.SortAsc},
+			},
+		},
+		{name: "empty", orderBy: ""},
+		{name: "unknown field", orderBy: "password", wantErr: true},
+		{name: "invalid direction", orderBy: "age DESC", wantErr: true},
+		{name: "empty item", orderBy: "age,", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := schema.ParseOrderBy(tt.orderBy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseOrderBy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseOrderBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// errAny matches any non-nil error in test cases.
+var errAny = errors.New("any error")
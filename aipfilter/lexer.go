@@ -0,0 +1,112 @@
+package aipfilter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tokenKind is the kind of a lexical token.
+type tokenKind int
+
+// Token kinds.
+const (
+	tokenEOF        tokenKind = iota
+	tokenText                 // field paths, keywords, numbers and unquoted values
+	tokenString               // quoted string literal
+	tokenComparator           // =, !=, <, <=, >, >= and :
+	tokenMinus                // negation prefix
+	tokenLParen
+	tokenRParen
+)
+
+// token is a lexical token of a filter expression.
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+// comparators are the supported comparators, longest first.
+var comparators = []string{"<=", ">=", "!=", "=", "<", ">", ":"}
+
+// tokenize splits the filter expression into tokens.
+func tokenize(s string) ([]token, error) {
+	// tokens
+	var tokens []token
+
+	// scan expression
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLParen, value: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRParen, value: ")", pos: i})
+			i++
+		case c == '"' || c == '\'':
+			// read quoted string, backslash escapes the next character
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(s) && s[j] != c; j++ {
+				if s[j] == '\\' && j+1 < len(s) {
+					j++
+				}
+				b.WriteByte(s[j])
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+			tokens = append(tokens, token{kind: tokenString, value: b.String(), pos: i})
+			i = j + 1
+		case c == '-' && (i+1 >= len(s) || s[i+1] < '0' || s[i+1] > '9'):
+			tokens = append(tokens, token{kind: tokenMinus, value: "-", pos: i})
+			i++
+		case strings.IndexByte("=!<>:", c) >= 0:
+			// read comparator
+			matched := ""
+			for _, cmp := range comparators {
+				if strings.HasPrefix(s[i:], cmp) {
+					matched = cmp
+					break
+				}
+			}
+			if matched == "" {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+			}
+			tokens = append(tokens, token{kind: tokenComparator, value: matched, pos: i})
+			i += len(matched)
+		default:
+			// read text
+			j := i
+			for j < len(s) && isTextPart(s[j]) {
+				j++
+			}
+			if j == i {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+			}
+			tokens = append(tokens, token{kind: tokenText, value: s[i:j], pos: i})
+			i = j
+		}
+	}
+
+	// add end of expression
+	tokens = append(tokens, token{kind: tokenEOF, pos: len(s)})
+
+	// return tokens
+	return tokens, nil
+}
+
+// isTextPart reports whether c can be part of a text token.
+func isTextPart(c byte) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		return true
+	case c == '_' || c == '.' || c == '*' || c == '+' || c == '-':
+		return true
+	}
+	return false
+}
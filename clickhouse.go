@@ -0,0 +1,126 @@
+package qbr
+
+import (
+	"fmt"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+// Final adds the FINAL modifier to ClickHouse select queries, which merges
+// rows of ReplacingMergeTree and similar engines before returning them. The
+// query only builds with the ClickHouse dialect. Returns the modified
+// QueryBuilder instance for method chaining.
+func (qb *Query) Final() *Query {
+	// copy immutable query
+	qb = qb.mutable()
+
+	// set final
+	qb.final = true
+
+	// return query
+	return qb
+}
+
+// IsFinal returns true if the query has been set with the FINAL modifier.
+func (qb *Query) IsFinal() bool {
+	return qb.final
+}
+
+// Sample adds the SAMPLE clause to ClickHouse select queries. A ratio between
+// 0 and 1 samples that fraction of the data, a larger value samples at least
+// that number of rows. Zero means no sampling. The query only builds with the
+// ClickHouse dialect. Returns the modified QueryBuilder instance for method
+// chaining.
+func (qb *Query) Sample(k float64) *Query {
+	// check sample
+	if k < 0 {
+		return qb.setError(fmt.Errorf("invalid sample: %g", k))
+	}
+
+	// copy immutable query
+	qb = qb.mutable()
+
+	// set sample
+	qb.sample = k
+
+	// return query
+	return qb
+}
+
+// GetSample returns the sample set for the query, or 0 if no sample has been
+// set.
+func (qb *Query) GetSample() float64 {
+	return qb.sample
+}
+
+// Prewhere adds conditions to the PREWHERE clause of ClickHouse select
+// queries, which is evaluated before the other columns are read. The
+// conditions are joined with AND, like the conditions of Where. The query
+// only builds with the ClickHouse dialect. Returns the modified QueryBuilder
+// instance for method chaining.
+func (qb *Query) Prewhere(conds ...domain.Condition) *Query {
+	// copy immutable query
+	qb = qb.mutable()
+
+	// add conditions
+	qb.prewhere = append(qb.prewhere, removeZeroCondition(conds...)...)
+
+	// return query
+	return qb
+}
+
+// GetPrewhere returns a copy of the PREWHERE conditions of the query.
+func (qb *Query) GetPrewhere() []domain.Condition {
+	return cloneConditions(qb.prewhere)
+}
+
+// LimitBy adds the LIMIT n BY clause to ClickHouse select queries, which
+// returns at most n rows for each distinct combination of the given fields.
+// The query only builds with the ClickHouse dialect. Returns the modified
+// QueryBuilder instance for method chaining.
+func (qb *Query) LimitBy(n uint64, fields ...*domain.Field) *Query {
+	// check fields
+	if n > 0 && len(fields) == 0 {
+		return qb.setError(fmt.Errorf("limit by requires at least one field"))
+	}
+
+	// copy immutable query
+	qb = qb.mutable()
+
+	// set limit by
+	qb.limitBy = n
+	qb.limitByFields = nil
+	for _, field := range fields {
+		qb.limitByFields = append(qb.limitByFields, *field)
+	}
+
+	// return query
+	return qb
+}
+
+// GetLimitBy returns the LIMIT n BY count and fields of the query, or 0 and
+// nil if no limit has been set.
+func (qb *Query) GetLimitBy() (uint64, []domain.Field) {
+	return qb.limitBy, cloneFields(qb.limitByFields)
+}
+
+// Mutation builds ClickHouse delete queries as ALTER TABLE ... DELETE
+// mutations instead of lightweight DELETE FROM statements. Update queries are
+// always built as mutations. Like all ClickHouse statements, mutations have no
+// RETURNING clause. The query only builds with the ClickHouse dialect. Returns
+// the modified QueryBuilder instance for method chaining.
+func (qb *Query) Mutation() *Query {
+	// copy immutable query
+	qb = qb.mutable()
+
+	// set mutation
+	qb.mutation = true
+
+	// return query
+	return qb
+}
+
+// IsMutation returns true if the query has been set with Mutation.
+func (qb *Query) IsMutation() bool {
+	return qb.mutation
+}
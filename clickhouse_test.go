@@ -0,0 +1,106 @@
+package qbr
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+func TestClickHouse(t *testing.T) {
+	id := NewField(WithDB("id"))
+	name := NewField(WithDB("name"))
+	age := NewField(WithDB("age"))
+
+	tests := []struct {
+		name    string
+		qb      *Query
+		dialect domain.SqlDialect
+		want    string
+		args    []any
+		wantErr bool
+	}{
+		{
+			name: "select",
+			qb: NewRead().
+				Select(id, name).
+				Final().
+				Sample(0.1).
+				Prewhere(Eq(name, "a")).
+				Where(Gt(age, 18)).
+				Sort(NewSortDesc(age)).
+				LimitBy(2, name).
+				Limit(10),
+			dialect: SqlClickHouse,
+			want:    "SELECT id, name FROM users FINAL SAMPLE 0.1 PREWHERE name = ? WHERE age > ? ORDER BY age DESC LIMIT 2 BY name LIMIT 10",
+			args:    []any{"a", 18},
+		},
+		{
+			name:    "aggregations",
+			qb:      NewRead().Select(NewUniqField(id), NewQuantileField(age, 0.95)),
+			dialect: SqlClickHouse,
+			want:    "SELECT uniq(id), quantile(0.95)(age) FROM users",
+		},
+		{
+			name:    "update mutation",
+			qb:      NewUpdate().Set(NewData(name, "a")).Where(Eq(id, 1)).NoReturning(),
+			dialect: SqlClickHouse,
+			want:    "ALTER TABLE users UPDATE name = ? WHERE id = ?",
+			args:    []any{"a", 1},
+		},
+		{
+			name:    "lightweight delete",
+			qb:      NewDelete().Where(Eq(id, 1)).NoReturning(),
+			dialect: SqlClickHouse,
+			want:    "DELETE FROM users WHERE id = ?",
+			args:    []any{1},
+		},
+		{
+			name:    "delete mutation",
+			qb:      NewDelete().Where(Eq(id, 1)).Mutation().NoReturning(),
+			dialect: SqlClickHouse,
+			want:    "ALTER TABLE users DELETE WHERE id = ?",
+			args:    []any{1},
+		},
+		{
+			name:    "delete mutation full table",
+			qb:      NewDelete().Mutation().AllowFullTable().NoReturning(),
+			dialect: SqlClickHouse,
+			want:    "ALTER TABLE users DELETE WHERE 1",
+		},
+		{name: "default returning", qb: NewDelete().Where(Eq(id, 1)), dialect: SqlClickHouse, wantErr: true},
+		{name: "explicit returning", qb: NewDelete().Where(Eq(id, 1)).Mutation().Returning(id), dialect: SqlClickHouse, wantErr: true},
+		{name: "neutral final", qb: NewRead().Final(), wantErr: true},
+		{name: "neutral sample", qb: NewRead().Sample(0.5), wantErr: true},
+		{name: "neutral prewhere", qb: NewRead().Prewhere(Eq(id, 1)), wantErr: true},
+		{name: "neutral limit by", qb: NewRead().LimitBy(1, id), wantErr: true},
+		{name: "neutral mutation", qb: NewDelete().Where(Eq(id, 1)).Mutation(), wantErr: true},
+		{name: "neutral aggregation", qb: NewRead().Select(NewUniqField(id)), wantErr: true},
+		{name: "postgres final", qb: NewRead().Final(), dialect: SqlPostgres, wantErr: true},
+		{name: "mysql mutation", qb: NewDelete().Where(Eq(id, 1)).Mutation(), dialect: SqlMySQL, wantErr: true},
+		{name: "negative sample", qb: NewRead().Sample(-1), dialect: SqlClickHouse, wantErr: true},
+		{name: "limit by without fields", qb: NewRead().LimitBy(1), dialect: SqlClickHouse, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			var args []any
+			var err error
+			if tt.dialect == "" {
+				got, args, err = tt.qb.ToSql("users", SqlQuestion)
+			} else {
+				got, args, err = tt.qb.ToSqlDialect("users", tt.dialect)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ToSqlDialect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ToSqlDialect() = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %#v, want %#v", args, tt.args)
+			}
+		})
+	}
+}
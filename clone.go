@@ -0,0 +1,169 @@
+package qbr
+
+import "github.com/tyrenix/qbr/domain"
+
+// Clone returns a deep copy of the query. Conditions (including nested And and
+// Or groups), data, modifications, sorts and fields are copied, so the clone
+// can be modified without affecting the original query.
+//
+// An immutable query is cloned as immutable.
+func (qb *Query) Clone() *Query {
+	// copy query
+	c := *qb
+
+	// copy slices
+	c.selects = cloneFields(qb.selects)
+	c.returning = cloneFields(qb.returning)
+	c.conditions = cloneConditions(qb.conditions)
+	c.data = cloneData(qb.data)
+	c.sort = cloneSorts(qb.sort)
+	c.prewhere = cloneConditions(qb.prewhere)
+	c.limitByFields = cloneFields(qb.limitByFields)
+
+	// return clone
+	return &c
+}
+
+// Immutable returns an immutable copy of the query. Every builder method called
+// on an immutable query returns a new query and leaves the original unchanged,
+// so base queries can be defined once as package variables and safely shared
+// between goroutines:
+//
+//	var activeUsers = qbr.NewRead().Where(qbr.Eq(statusField, "active")).Immutable()
+//
+//	qb := activeUsers.Limit(10) // activeUsers is not modified
+func (qb *Query) Immutable() *Query {
+	// copy query
+	c := qb.Clone()
+
+	// set immutable
+	c.immutable = true
+
+	// return query
+	return c
+}
+
+// IsImmutable returns true if the query is immutable.
+func (qb *Query) IsImmutable() bool {
+	return qb.immutable
+}
+
+// mutable returns the query itself, or a clone of the query if it is
+// immutable. It is called by every builder method before modifying the query.
+func (qb *Query) mutable() *Query {
+	// mutable query
+	if !qb.immutable {
+		return qb
+	}
+
+	// return clone
+	return qb.Clone()
+}
+
+// cloneField returns a deep copy of the field, or nil if the field is nil.
+func cloneField(field *domain.Field) *domain.Field {
+	// check is nil
+	if field == nil {
+		return nil
+	}
+
+	// copy field
+	f := *field
+	f.IgnoreOn = append([]domain.OperationType(nil), field.IgnoreOn...)
+
+	// return field
+	return &f
+}
+
+// cloneFields returns a deep copy of the fields.
+func cloneFields(fields []domain.Field) []domain.Field {
+	// check is nil
+	if fields == nil {
+		return nil
+	}
+
+	// copy fields
+	result := make([]domain.Field, len(fields))
+	for i := range fields {
+		result[i] = *cloneField(&fields[i])
+	}
+
+	// return fields
+	return result
+}
+
+// cloneConditions returns a deep copy of the conditions, including nested
+// conditions and IN values.
+func cloneConditions(conds []domain.Condition) []domain.Condition {
+	// check is nil
+	if conds == nil {
+		return nil
+	}
+
+	// copy conditions
+	result := make([]domain.Condition, len(conds))
+	for i, cond := range conds {
+		// copy field
+		cond.Field = cloneField(cond.Field)
+
+		// copy value
+		switch v := cond.Value.(type) {
+		case []domain.Condition:
+			cond.Value = cloneConditions(v)
+		case []any:
+			cond.Value = append([]any(nil), v...)
+		}
+
+		// add condition
+		result[i] = cond
+	}
+
+	// return conditions
+	return result
+}
+
+// cloneData returns a deep copy of the data, including modifications.
+func cloneData(data []domain.Data) []domain.Data {
+	// check is nil
+	if data == nil {
+		return nil
+	}
+
+	// copy data
+	result := make([]domain.Data, len(data))
+	for i, d := range data {
+		// copy field
+		d.Field = cloneField(d.Field)
+
+		// copy modification
+		if mod, ok := d.Value.(*domain.Modification); ok {
+			m := *mod
+			m.Field = cloneField(mod.Field)
+			d.Value = &m
+		}
+
+		// add data
+		result[i] = d
+	}
+
+	// return data
+	return result
+}
+
+// cloneSorts returns a deep copy of the sorts.
+func cloneSorts(sorts []domain.Sort) []domain.Sort {
+	// check is nil
+	if sorts == nil {
+		return nil
+	}
+
+	// copy sorts
+	result := make([]domain.Sort, len(sorts))
+	for i, sort := range sorts {
+		sort.Field = cloneField(sort.Field)
+		result[i] = sort
+	}
+
+	// return sorts
+	return result
+}
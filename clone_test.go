@@ -0,0 +1,163 @@
+package qbr
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+// cloneBase builds the query that clone tests modify.
+func cloneBase() *Query {
+	id := NewField(WithDB("id"))
+	name := NewField(WithDB("name"), WithIgnoreOn(domain.OperationRead))
+	age := NewField(WithDB("age"))
+
+	return NewUpdate().
+		Select(id, name).
+		Where(In(id, 1, 2), Or(Eq(id, 5), And(Gt(age, 18), In(age, 20, 30)))).
+		Set(NewData(name, "b"), NewData(age, Add(age, 1))).
+		Sort(NewSortAsc(name)).
+		Returning(id).
+		Limit(10).
+		Prewhere(In(age, 1, 2)).
+		LimitBy(1, age)
+}
+
+func TestClone(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Query)
+	}{
+		{
+			name: "builder methods",
+			modify: func(c *Query) {
+				c.Where(Eq(NewField(WithDB("x")), 1)).
+					Set(NewData(NewField(WithDB("x")), 1)).
+					Sort(NewSortDesc(NewField(WithDB("x")))).
+					Select(NewField(WithDB("x"))).
+					Returning(NewField(WithDB("x"))).
+					Limit(1)
+			},
+		},
+		{
+			name: "in values",
+			modify: func(c *Query) {
+				c.conditions[0].Value.([]any)[0] = 9
+			},
+		},
+		{
+			name: "nested conditions",
+			modify: func(c *Query) {
+				or := c.conditions[1].Value.([]domain.Condition)
+				or[0].Field.DB = "x"
+				and := or[1].Value.([]domain.Condition)
+				and[0].Value = 99
+				and[1].Value.([]any)[1] = 99
+			},
+		},
+		{
+			name: "data and modifications",
+			modify: func(c *Query) {
+				c.data[0].Field.DB = "x"
+				c.data[0].Value = "x"
+				mod := c.data[1].Value.(*domain.Modification)
+				mod.Field.DB = "x"
+				mod.Value = 99
+			},
+		},
+		{
+			name: "prewhere and limit by",
+			modify: func(c *Query) {
+				c.prewhere[0].Field.DB = "x"
+				c.prewhere[0].Value.([]any)[0] = 99
+				c.limitByFields[0].DB = "x"
+			},
+		},
+		{
+			name: "sorts and fields",
+			modify: func(c *Query) {
+				c.sort[0].Field.DB = "x"
+				c.selects[1].IgnoreOn[0] = domain.OperationDelete
+				c.returning[0].DB = "x"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qb := cloneBase()
+
+			// modify clone
+			c := qb.Clone()
+			tt.modify(c)
+
+			// check original is unchanged
+			if !reflect.DeepEqual(qb, cloneBase()) {
+				t.Errorf("Clone() modification changed the original query")
+			}
+		})
+	}
+}
+
+func TestImmutable(t *testing.T) {
+	x := NewField(WithDB("x"))
+
+	tests := []struct {
+		name   string
+		modify func(qb *Query) *Query
+	}{
+		{name: "where", modify: func(qb *Query) *Query { return qb.Where(Eq(x, 1)) }},
+		{name: "set", modify: func(qb *Query) *Query { return qb.Set(NewData(x, 1)) }},
+		{name: "select", modify: func(qb *Query) *Query { return qb.Select(x) }},
+		{name: "select struct", modify: func(qb *Query) *Query { return qb.SelectStruct(tableNoPK{}) }},
+		{name: "sort", modify: func(qb *Query) *Query { return qb.Sort(NewSortDesc(x)) }},
+		{name: "limit and offset", modify: func(qb *Query) *Query { return qb.Limit(1).Offset(2) }},
+		{name: "returning", modify: func(qb *Query) *Query { return qb.NoReturning() }},
+		{name: "lock and suffix", modify: func(qb *Query) *Query { return qb.Lock().Suffix("x") }},
+		{name: "safety", modify: func(qb *Query) *Query { return qb.AllowFullTable().MaxAffected(1) }},
+		{name: "allow fields", modify: func(qb *Query) *Query { return qb.AllowFields(x) }},
+		{name: "clickhouse", modify: func(qb *Query) *Query { return qb.Prewhere(Eq(x, 1)).Final().Sample(0.5).LimitBy(1, x).Mutation() }},
+		{name: "error", modify: func(qb *Query) *Query { return qb.SelectStruct(1) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := cloneBase().Immutable()
+
+			// modify immutable query
+			got := tt.modify(base)
+			if got == base {
+				t.Fatalf("builder method returned the immutable query itself")
+			}
+			if !got.IsImmutable() {
+				t.Errorf("IsImmutable() = false for a query derived from an immutable query")
+			}
+
+			// check base is unchanged
+			if !reflect.DeepEqual(base, cloneBase().Immutable()) {
+				t.Errorf("builder method changed the immutable query")
+			}
+		})
+	}
+}
+
+func TestCloneAllowedFields(t *testing.T) {
+	id := NewField(WithDB("id"))
+	name := NewField(WithDB("name"))
+
+	// allow fields on a clone
+	base := NewRead().AllowFields(id)
+	clone := base.Clone().AllowFields(id, name)
+
+	// check base still denies the field
+	data := []byte(`{"version":1,"operation":"read","where":[{"field":"name","op":"eq","value":"a"}]}`)
+	if err := json.Unmarshal(data, base); !errors.Is(err, ErrFieldNotAllowed) {
+		t.Errorf("base Unmarshal() error = %v, want %v", err, ErrFieldNotAllowed)
+	}
+	if err := json.Unmarshal(data, clone); err != nil {
+		t.Errorf("clone Unmarshal() error = %v", err)
+	}
+}
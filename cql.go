@@ -0,0 +1,91 @@
+package qbr
+
+import (
+	"time"
+
+	"github.com/tyrenix/qbr/domain"
+	"github.com/tyrenix/qbr/internal/cqlbuilder"
+)
+
+// CqlOption is a function that configures the CqlOptions of a CQL query.
+type CqlOption func(*domain.CqlOptions)
+
+// CqlPrimaryKey sets the primary key columns of the table. Top-level
+// conditions on other columns of update and delete queries are rendered as IF
+// conditions of a lightweight transaction.
+func CqlPrimaryKey(columns ...string) CqlOption {
+	return func(o *domain.CqlOptions) {
+		o.PrimaryKey = append(o.PrimaryKey, columns...)
+	}
+}
+
+// CqlAllowFiltering adds ALLOW FILTERING to select queries.
+func CqlAllowFiltering() CqlOption {
+	return func(o *domain.CqlOptions) {
+		o.AllowFiltering = true
+	}
+}
+
+// CqlTTL adds USING TTL to insert and update queries. The TTL is truncated to
+// seconds.
+func CqlTTL(ttl time.Duration) CqlOption {
+	return func(o *domain.CqlOptions) {
+		o.TTL = ttl
+	}
+}
+
+// CqlTimestamp adds USING TIMESTAMP to insert, update and delete queries.
+func CqlTimestamp(t time.Time) CqlOption {
+	return func(o *domain.CqlOptions) {
+		o.Timestamp = t.UnixMicro()
+	}
+}
+
+// CqlIfNotExists adds IF NOT EXISTS to insert queries.
+func CqlIfNotExists() CqlOption {
+	return func(o *domain.CqlOptions) {
+		o.IfNotExists = true
+	}
+}
+
+// CqlIfExists adds IF EXISTS to update and delete queries.
+func CqlIfExists() CqlOption {
+	return func(o *domain.CqlOptions) {
+		o.IfExists = true
+	}
+}
+
+// ToCql builds a Cassandra CQL query from the query builder data, with ?
+// placeholders. It returns the query string, the parameters for the query,
+// and an error if the query could not be built.
+//
+// Conditions are joined with AND; Or and Not conditions are rejected, and !=
+// and null comparisons are only accepted in IF conditions. Update and delete
+// queries require conditions on the primary key columns. Add and Subtract
+// modifications become counter updates, other modifications are rejected.
+// Offsets, locks, explicit returning fields, MaxAffected and ClickHouse
+// features are not supported by CQL.
+//
+// The query is checked with Validate first, so insert and update queries
+// without data fail with ErrNoData, and update and delete queries without
+// conditions fail with ErrFullTable unless AllowFullTable is set.
+func (qb *Query) ToCql(table string, options ...CqlOption) (string, []any, error) {
+	// validate query
+	if err := qb.Validate(); err != nil {
+		return "", nil, err
+	}
+
+	// validate table
+	if err := validateTable(table); err != nil {
+		return "", nil, err
+	}
+
+	// apply options
+	var opts domain.CqlOptions
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	// build query
+	return cqlbuilder.CreateCql(qb, table, opts)
+}
@@ -0,0 +1,162 @@
+package qbr
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+func TestToCql(t *testing.T) {
+	id := NewField(WithDB("id"))
+	name := NewField(WithDB("name"))
+	age := NewField(WithDB("age"))
+	views := NewField(WithDB("views"))
+
+	tests := []struct {
+		name      string
+		qb        *Query
+		options   []CqlOption
+		wantQuery string
+		wantArgs  []any
+		wantErr   error
+	}{
+		{
+			name:      "select",
+			qb:        NewRead().Select(id, name).Where(Eq(id, 1), In(age, 18, 21)).Sort(NewSortDesc(age)).Limit(10),
+			wantQuery: "SELECT id, name FROM users WHERE id = ? AND age IN (?, ?) ORDER BY age DESC LIMIT 10",
+			wantArgs:  []any{1, 18, 21},
+		},
+		{
+			name:      "select allow filtering",
+			qb:        NewRead().Where(GtOrEq(age, 18)),
+			options:   []CqlOption{CqlAllowFiltering()},
+			wantQuery: "SELECT * FROM users WHERE age >= ? ALLOW FILTERING",
+			wantArgs:  []any{18},
+		},
+		{
+			name:      "select nested and",
+			qb:        NewRead().Where(And(Eq(id, 1), Lt(age, 30))),
+			wantQuery: "SELECT * FROM users WHERE id = ? AND age < ?",
+			wantArgs:  []any{1, 30},
+		},
+		{
+			name:      "insert if not exists with ttl",
+			qb:        NewCreate().Set(NewData(id, 1), NewData(name, "a")),
+			options:   []CqlOption{CqlIfNotExists(), CqlTTL(90 * time.Second)},
+			wantQuery: "INSERT INTO users (id, name) VALUES (?, ?) IF NOT EXISTS USING TTL 90",
+			wantArgs:  []any{1, "a"},
+		},
+		{
+			name:      "insert null",
+			qb:        NewCreate().Set(NewData(id, 1), NewData(name, domain.ValueNull)),
+			wantQuery: "INSERT INTO users (id, name) VALUES (?, ?)",
+			wantArgs:  []any{1, nil},
+		},
+		{
+			name:      "update with timestamp",
+			qb:        NewUpdate().Set(NewData(name, "a")).Where(Eq(id, 1)),
+			options:   []CqlOption{CqlTimestamp(time.UnixMicro(1000))},
+			wantQuery: "UPDATE users USING TIMESTAMP 1000 SET name = ? WHERE id = ?",
+			wantArgs:  []any{"a", 1},
+		},
+		{
+			name:      "update counters",
+			qb:        NewUpdate().Set(NewData(views, Add(views, 1)), NewData(age, Subtract(age, 2))).Where(Eq(id, 1)),
+			wantQuery: "UPDATE users SET views = views + ?, age = age - ? WHERE id = ?",
+			wantArgs:  []any{1, 2, 1},
+		},
+		{
+			name:      "update with if conditions",
+			qb:        NewUpdate().Set(NewData(name, "b")).Where(Eq(id, 1), Eq(name, "a"), NoEq(age, domain.ValueNull)),
+			options:   []CqlOption{CqlPrimaryKey("id")},
+			wantQuery: "UPDATE users SET name = ? WHERE id = ? IF name = ? AND age != ?",
+			wantArgs:  []any{"b", 1, "a", nil},
+		},
+		{
+			name:      "update if exists",
+			qb:        NewUpdate().Set(NewData(name, "a")).Where(Eq(id, 1)),
+			options:   []CqlOption{CqlPrimaryKey("id"), CqlIfExists()},
+			wantQuery: "UPDATE users SET name = ? WHERE id = ? IF EXISTS",
+			wantArgs:  []any{"a", 1},
+		},
+		{
+			name:      "delete",
+			qb:        NewDelete().Where(Eq(id, 1)),
+			wantQuery: "DELETE FROM users WHERE id = ?",
+			wantArgs:  []any{1},
+		},
+		{
+			name:      "delete columns",
+			qb:        NewDelete().Select(name, age).Where(Eq(id, 1)),
+			options:   []CqlOption{CqlIfExists()},
+			wantQuery: "DELETE name, age FROM users WHERE id = ? IF EXISTS",
+			wantArgs:  []any{1},
+		},
+		{name: "or", qb: NewRead().Where(Or(Eq(id, 1), Eq(id, 2))), wantErr: errAny},
+		{name: "not", qb: NewRead().Where(Not(Eq(id, 1))), wantErr: errAny},
+		{name: "not equal in where", qb: NewRead().Where(NoEq(id, 1)), wantErr: errAny},
+		{name: "null in where", qb: NewRead().Where(Eq(name, domain.ValueNull)), wantErr: errAny},
+		{name: "offset", qb: NewRead().Offset(10), wantErr: errAny},
+		{name: "lock", qb: NewRead().Lock(), wantErr: errAny},
+		{name: "explicit returning", qb: NewCreate().Set(NewData(id, 1)).Returning(id), wantErr: errAny},
+		{name: "max affected", qb: NewDelete().Where(Eq(id, 1)).MaxAffected(1), wantErr: errAny},
+		{name: "final", qb: NewRead().Final(), wantErr: errAny},
+		{name: "sample", qb: NewRead().Sample(0.5), wantErr: errAny},
+		{name: "prewhere", qb: NewRead().Prewhere(Eq(id, 1)), wantErr: errAny},
+		{name: "limit by", qb: NewRead().LimitBy(1, id), wantErr: errAny},
+		{name: "mutation", qb: NewDelete().Where(Eq(id, 1)).Mutation(), wantErr: errAny},
+		{name: "empty in", qb: NewRead().Where(In(id)), wantErr: ErrEmptyIn},
+		{name: "insert without data", qb: NewCreate(), wantErr: ErrNoData},
+		{name: "update without data", qb: NewUpdate().Where(Eq(id, 1)), wantErr: ErrNoData},
+		{name: "update full table", qb: NewUpdate().Set(NewData(name, "a")), wantErr: ErrFullTable},
+		{name: "delete full table", qb: NewDelete(), wantErr: ErrFullTable},
+		{
+			name:    "update without key conditions",
+			qb:      NewUpdate().Set(NewData(name, "a")).Where(Eq(name, "b")),
+			options: []CqlOption{CqlPrimaryKey("id")},
+			wantErr: errAny,
+		},
+		{
+			name:    "if exists with if conditions",
+			qb:      NewDelete().Where(Eq(id, 1), Eq(name, "a")),
+			options: []CqlOption{CqlPrimaryKey("id"), CqlIfExists()},
+			wantErr: errAny,
+		},
+		{
+			name:    "multiply modification",
+			qb:      NewUpdate().Set(NewData(views, Multiply(views, 2))).Where(Eq(id, 1)),
+			wantErr: errAny,
+		},
+		{
+			name:    "counter of another column",
+			qb:      NewUpdate().Set(NewData(views, Add(age, 1))).Where(Eq(id, 1)),
+			wantErr: errAny,
+		},
+		{name: "allow filtering on insert", qb: NewCreate().Set(NewData(id, 1)), options: []CqlOption{CqlAllowFiltering()}, wantErr: errAny},
+		{name: "ttl on delete", qb: NewDelete().Where(Eq(id, 1)), options: []CqlOption{CqlTTL(time.Minute)}, wantErr: errAny},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args, err := tt.qb.ToCql("users", tt.options...)
+			if tt.wantErr != nil {
+				if err == nil || (tt.wantErr != errAny && !errors.Is(err, tt.wantErr)) {
+					t.Fatalf("ToCql() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ToCql() error = %v", err)
+			}
+			if query != tt.wantQuery {
+				t.Errorf("ToCql() query = %q, want %q", query, tt.wantQuery)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("ToCql() args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
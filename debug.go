@@ -0,0 +1,41 @@
+package qbr
+
+import (
+	"github.com/tyrenix/qbr/domain"
+	"github.com/tyrenix/qbr/internal/sqlbuilder"
+)
+
+// debugHeader marks rendered debug queries as not intended for execution.
+const debugHeader = "-- qbr debug: parameters are inlined, not for execution\n"
+
+// Debug builds SQL query for the given dialect like ToSqlDialect and renders
+// it with the parameters inlined as SQL literals of the dialect: quoted and
+// escaped strings, formatted times, NULL, hex byte arrays and JSON strings for
+// struct values.
+//
+// The result is intended for logs and incident investigation, e.g. to be
+// pasted into psql. It starts with a comment line marking it as debug output
+// and must never be executed by the application, since inlining literals is
+// not a substitute for parameter binding.
+func (qb *Query) Debug(table string, dialect domain.SqlDialect) (string, error) {
+	// build query
+	query, params, err := qb.ToSqlDialect(table, dialect)
+	if err != nil {
+		return "", err
+	}
+
+	// get dialect placeholder
+	placeholder, err := sqlbuilder.GetDialectPlaceholder(dialect)
+	if err != nil {
+		return "", err
+	}
+
+	// inline parameters
+	query, err = sqlbuilder.Interpolate(query, params, placeholder, dialect)
+	if err != nil {
+		return "", err
+	}
+
+	// return query
+	return debugHeader + query, nil
+}
@@ -0,0 +1,93 @@
+package qbr
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+func TestDebug(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	field := func(db string) *domain.Field { return NewField(WithDB(db)) }
+
+	// insert with literals of every kind
+	insert := NewCreate().
+		Set(
+			NewData(field("name"), `it's a \ path`),
+			NewData(field("note"), domain.ValueNull),
+			NewData(field("seen"), at),
+			NewData(field("raw"), []byte{0xca, 0xfe}),
+			NewData(field("active"), true),
+		).
+		NoReturning()
+
+	// update with more than nine parameters
+	wide := NewUpdate().Where(Eq(field("id"), 0)).NoReturning()
+	for i := 1; i <= 10; i++ {
+		wide = wide.Set(NewData(field(fmt.Sprintf("c%d", i)), i))
+	}
+
+	tests := []struct {
+		name    string
+		qb      *Query
+		dialect domain.SqlDialect
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "postgres literals",
+			qb:      insert,
+			dialect: SqlPostgres,
+			want:    `INSERT INTO users (name, note, seen, raw, active) VALUES ('it''s a \ path', NULL, '2024-01-02 03:04:05+00:00', '\xcafe', TRUE)`,
+		},
+		{
+			name:    "mysql literals",
+			qb:      insert,
+			dialect: SqlMySQL,
+			want:    `INSERT INTO users (name, note, seen, raw, active) VALUES ('it''s a \\ path', NULL, '2024-01-02 03:04:05', X'CAFE', TRUE)`,
+		},
+		{
+			name:    "sqlite literals",
+			qb:      insert,
+			dialect: SqlSQLite,
+			want:    `INSERT INTO users (name, note, seen, raw, active) VALUES ('it''s a \ path', NULL, '2024-01-02 03:04:05', X'CAFE', TRUE)`,
+		},
+		{
+			name:    "sqlserver literals",
+			qb:      insert,
+			dialect: SqlSQLServer,
+			want:    `INSERT INTO users (name, note, seen, raw, active) VALUES (N'it''s a \ path', NULL, N'2024-01-02 03:04:05', 0xCAFE, 1)`,
+		},
+		{
+			name:    "postgres ten parameters",
+			qb:      wide,
+			dialect: SqlPostgres,
+			want:    "UPDATE users SET c1 = 1, c2 = 2, c3 = 3, c4 = 4, c5 = 5, c6 = 6, c7 = 7, c8 = 8, c9 = 9, c10 = 10 WHERE id = 0",
+		},
+		{
+			name:    "sqlserver ten parameters",
+			qb:      wide,
+			dialect: SqlSQLServer,
+			want:    "UPDATE users SET c1 = 1, c2 = 2, c3 = 3, c4 = 4, c5 = 5, c6 = 6, c7 = 7, c8 = 8, c9 = 9, c10 = 10 WHERE id = 0",
+		},
+		{name: "unsupported dialect", qb: insert, dialect: "oracle", wantErr: true},
+		{name: "invalid query", qb: NewDelete(), dialect: SqlPostgres, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.qb.Debug("users", tt.dialect)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Debug() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if want := debugHeader + tt.want; got != want {
+				t.Errorf("Debug() = %s, want %s", got, want)
+			}
+		})
+	}
+}
@@ -5,7 +5,13 @@ type QueryAnnotationType string
 
 // Query annotation types.
 const (
-	QueryQbr      QueryAnnotationType = "qbr"
-	QueryDB       QueryAnnotationType = "db"
-	QueryIgnoreOn QueryAnnotationType = "ignore_on"
+	QueryQbr       QueryAnnotationType = "qbr"
+	QueryDB        QueryAnnotationType = "db"
+	QueryIgnoreOn  QueryAnnotationType = "ignore_on"
+	QueryPK        QueryAnnotationType = "pk"
+	QueryGenerated QueryAnnotationType = "generated"
+	QueryTable     QueryAnnotationType = "table"
+	QuerySchema    QueryAnnotationType = "schema"
+	QueryOp        QueryAnnotationType = "op"
+	QueryOr        QueryAnnotationType = "or"
 )
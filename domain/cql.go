@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// CqlOptions model, options of a CQL query.
+type CqlOptions struct {
+	PrimaryKey     []string      // Primary key columns, conditions on other columns of update and delete queries become IF conditions.
+	AllowFiltering bool          // Add ALLOW FILTERING to select queries.
+	TTL            time.Duration // USING TTL of insert and update queries, 0 for none.
+	Timestamp      int64         // USING TIMESTAMP in microseconds, 0 for none.
+	IfNotExists    bool          // Add IF NOT EXISTS to insert queries.
+	IfExists       bool          // Add IF EXISTS to update and delete queries.
+}
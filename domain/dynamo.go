@@ -0,0 +1,16 @@
+package domain
+
+// DynamoQuery model, driver-agnostic DynamoDB expressions and attributes of a query.
+type DynamoQuery struct {
+	KeyCondition     string            // KeyConditionExpression, for read queries.
+	Filter           string            // FilterExpression, for read queries.
+	Condition        string            // ConditionExpression, for create, update and delete queries.
+	Update           string            // UpdateExpression, for update queries.
+	Projection       string            // ProjectionExpression, empty for all attributes.
+	Key              map[string]any    // Primary key attributes, for update and delete queries.
+	Item             map[string]any    // Item attributes, for create queries.
+	Names            map[string]string // ExpressionAttributeNames.
+	Values           map[string]any    // ExpressionAttributeValues.
+	ScanIndexForward *bool             // Sort key order, nil for default.
+	Limit            int32             // Limit, 0 for none.
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Query validation errors.
+var (
+	ErrNoData          = errors.New("no data to write")
+	ErrEmptyIn         = errors.New("empty IN condition")
+	ErrFullTable       = errors.New("update or delete without conditions")
+	ErrFieldNotAllowed = errors.New("field is not allowed")
+)
+
+// ErrUnsupportedOperator is returned when a condition uses an operator that is
+// not supported.
+type ErrUnsupportedOperator struct {
+	Op OperatorType
+}
+
+// Error implements the error interface.
+func (e ErrUnsupportedOperator) Error() string {
+	return fmt.Sprintf("unsupported operator: %d", e.Op)
+}
+
+// ErrInvalidIdentifier is returned when a table or field name is not a valid
+// SQL identifier.
+type ErrInvalidIdentifier struct {
+	Identifier string
+}
+
+// Error implements the error interface.
+func (e ErrInvalidIdentifier) Error() string {
+	return fmt.Sprintf("invalid identifier: %q", e.Identifier)
+}
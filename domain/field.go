@@ -8,11 +8,14 @@ const (
 	AggregationNone AggregationType = iota
 	AggregationCount
 	AggregationSum
+	AggregationUniq
+	AggregationQuantile
 )
 
 // Field model.
 type Field struct {
 	DB          string          // DB field name.
 	Aggregation AggregationType // Aggregation type.
+	Level       float64         // Level of parametric aggregations, such as quantile.
 	IgnoreOn    []OperationType // Slice with ignored operations.
 }
@@ -0,0 +1,18 @@
+package domain
+
+// MongoSort model, a single field of a MongoDB sort document.
+type MongoSort struct {
+	Key   string
+	Value int // 1 for ascending, -1 for descending
+}
+
+// MongoQuery model, driver-agnostic MongoDB documents and options of a query.
+type MongoQuery struct {
+	Filter     map[string]any // Query filter.
+	Document   map[string]any // Document to insert, for create queries.
+	Update     map[string]any // Update document, for update queries.
+	Projection map[string]any // Projection, nil for all fields.
+	Sort       []MongoSort    // Sort, ordered by priority.
+	Limit      int64          // Limit, 0 for none.
+	Skip       int64          // Skip, 0 for none.
+}
@@ -14,4 +14,7 @@ const (
 	OperatorAnd
 	OperatorOr
 	OperatorIn
+	OperatorLike
+	OperatorILike
+	OperatorNot
 )
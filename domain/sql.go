@@ -7,4 +7,17 @@ type SqlPlaceholder string
 const (
 	SqlDollar   SqlPlaceholder = "$"
 	SqlQuestion SqlPlaceholder = "?"
+	SqlAtP      SqlPlaceholder = "@p"
+)
+
+// SqlDialect type.
+type SqlDialect string
+
+// Sql dialects variables.
+const (
+	SqlPostgres   SqlDialect = "postgres"
+	SqlMySQL      SqlDialect = "mysql"
+	SqlSQLite     SqlDialect = "sqlite"
+	SqlSQLServer  SqlDialect = "sqlserver"
+	SqlClickHouse SqlDialect = "clickhouse"
 )
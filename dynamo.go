@@ -0,0 +1,38 @@
+package qbr
+
+import (
+	"github.com/tyrenix/qbr/domain"
+	"github.com/tyrenix/qbr/internal/dynamobuilder"
+)
+
+// ToDynamo builds driver-agnostic DynamoDB expressions from the query builder
+// data, with generated #name and :value placeholders collected into the Names
+// and Values maps. Keys are the table's key attribute names, partition key
+// first and then the optional sort key.
+//
+// For read queries with an equality condition on the partition key, top-level
+// conditions on key attributes become the key condition expression and the
+// rest the filter expression. The sort key may have a single comparison or a
+// prefix LIKE condition. Without a partition key condition all conditions
+// become the filter expression, suitable for a Scan. A sort by the sort key
+// sets the scan direction. For update and delete queries, top-level equality conditions on
+// key attributes become the Key map and the rest the condition expression. For
+// create queries, data becomes the Item map and conditions the condition
+// expression. Update data becomes an update expression, where Add and
+// Subtract modifications are rendered as SET x = x + :v and null values as
+// REMOVE. Select fields become the projection expression.
+//
+// The query is checked with Validate first. It returns an error if the query
+// uses a feature that cannot be expressed in DynamoDB, such as offsets, locks,
+// suffixes, explicit returning fields, MaxAffected, ClickHouse features,
+// aggregations, limits above the int32 range or LIKE patterns other than a
+// prefix or a substring.
+func (qb *Query) ToDynamo(keys ...string) (*domain.DynamoQuery, error) {
+	// validate query
+	if err := qb.Validate(); err != nil {
+		return nil, err
+	}
+
+	// build query
+	return dynamobuilder.CreateDynamoQuery(qb, keys)
+}
@@ -0,0 +1,125 @@
+package qbr
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+func TestToDynamo(t *testing.T) {
+	pk := NewField(WithDB("pk"))
+	sk := NewField(WithDB("sk"))
+	name := NewField(WithDB("name"))
+	age := NewField(WithDB("age"))
+	forward, backward := true, false
+
+	tests := []struct {
+		name    string
+		qb      *Query
+		want    *domain.DynamoQuery
+		wantErr error
+	}{
+		{
+			name: "query",
+			qb:   NewRead().Where(Eq(pk, "u1"), Gt(sk, 10), Eq(name, "a")).Sort(NewSortDesc(sk)).Limit(5),
+			want: &domain.DynamoQuery{
+				KeyCondition:     "#n0 = :v0 AND #n1 > :v1",
+				Filter:           "#n2 = :v2",
+				Names:            map[string]string{"#n0": "pk", "#n1": "sk", "#n2": "name"},
+				Values:           map[string]any{":v0": "u1", ":v1": 10, ":v2": "a"},
+				ScanIndexForward: &backward,
+				Limit:            5,
+			},
+		},
+		{
+			name: "sort key prefix",
+			qb:   NewRead().Where(Eq(pk, "u1"), Like(sk, EscapeLike("a%")+"%")).Sort(NewSortAsc(sk)),
+			want: &domain.DynamoQuery{
+				KeyCondition:     "#n0 = :v0 AND begins_with(#n1, :v1)",
+				Names:            map[string]string{"#n0": "pk", "#n1": "sk"},
+				Values:           map[string]any{":v0": "u1", ":v1": "a%"},
+				ScanIndexForward: &forward,
+			},
+		},
+		{
+			name: "scan without partition key",
+			qb:   NewRead().Select(name).Where(Gt(sk, 10), Or(Like(name, "%b%"), Eq(age, domain.ValueNull))),
+			want: &domain.DynamoQuery{
+				Filter:     "#n0 > :v0 AND (contains(#n1, :v1) OR attribute_not_exists(#n2))",
+				Projection: "#n1",
+				Names:      map[string]string{"#n0": "sk", "#n1": "name", "#n2": "age"},
+				Values:     map[string]any{":v0": 10, ":v1": "b"},
+			},
+		},
+		{
+			name: "create",
+			qb:   NewCreate().Set(NewData(pk, "u1"), NewData(name, "a")).Where(NoEq(pk, domain.ValueNull)),
+			want: &domain.DynamoQuery{
+				Item:      map[string]any{"pk": "u1", "name": "a"},
+				Condition: "attribute_exists(#n0)",
+				Names:     map[string]string{"#n0": "pk"},
+			},
+		},
+		{
+			name: "update",
+			qb: NewUpdate().
+				Set(NewData(name, "a"), NewData(age, Add(age, 1)), NewData(NewField(WithDB("tmp")), domain.ValueNull)).
+				Where(Eq(pk, "u1"), Eq(sk, 2), Gt(age, 0)),
+			want: &domain.DynamoQuery{
+				Key:       map[string]any{"pk": "u1", "sk": 2},
+				Condition: "#n0 > :v0",
+				Update:    "SET #n1 = :v1, #n0 = #n0 + :v2 REMOVE #n2",
+				Names:     map[string]string{"#n0": "age", "#n1": "name", "#n2": "tmp"},
+				Values:    map[string]any{":v0": 0, ":v1": "a", ":v2": 1},
+			},
+		},
+		{
+			name: "delete",
+			qb:   NewDelete().Where(Eq(pk, "u1"), Eq(sk, 2)),
+			want: &domain.DynamoQuery{Key: map[string]any{"pk": "u1", "sk": 2}},
+		},
+		{name: "partition key range", qb: NewRead().Where(Gt(pk, "a")), wantErr: errAny},
+		{name: "partition key in", qb: NewRead().Where(In(pk, "a", "b")), wantErr: errAny},
+		{name: "sort key contains", qb: NewRead().Where(Eq(pk, "a"), Like(sk, "%x%")), wantErr: errAny},
+		{name: "sort key not equal", qb: NewRead().Where(Eq(pk, "a"), NoEq(sk, 1)), wantErr: errAny},
+		{name: "two sort key conditions", qb: NewRead().Where(Eq(pk, "a"), Gt(sk, 1), Lt(sk, 5)), wantErr: errAny},
+		{name: "null partition key", qb: NewRead().Where(Eq(pk, domain.ValueNull)), wantErr: errAny},
+		{name: "sorted scan", qb: NewRead().Sort(NewSortAsc(sk)), wantErr: errAny},
+		{name: "limit overflow", qb: NewRead().Limit(math.MaxInt32 + 1), wantErr: errAny},
+		{name: "offset", qb: NewRead().Offset(1), wantErr: errAny},
+		{name: "lock", qb: NewRead().Lock(), wantErr: errAny},
+		{name: "suffix", qb: NewRead().Suffix("LIMIT 1"), wantErr: errAny},
+		{name: "explicit returning", qb: NewCreate().Set(NewData(name, "a")).Returning(name), wantErr: errAny},
+		{name: "max affected", qb: NewDelete().Where(Eq(pk, "a")).MaxAffected(1), wantErr: errAny},
+		{name: "final", qb: NewRead().Final(), wantErr: errAny},
+		{name: "sample", qb: NewRead().Sample(0.5), wantErr: errAny},
+		{name: "prewhere", qb: NewRead().Prewhere(Eq(pk, 1)), wantErr: errAny},
+		{name: "limit by", qb: NewRead().LimitBy(1, pk), wantErr: errAny},
+		{name: "mutation", qb: NewDelete().Where(Eq(pk, 1)).Mutation(), wantErr: errAny},
+		{name: "infix like", qb: NewRead().Where(Like(name, "a%b")), wantErr: errAny},
+		{name: "incomplete key", qb: NewDelete().Where(Eq(pk, "u1")), wantErr: errAny},
+		{name: "full table delete", qb: NewDelete(), wantErr: ErrFullTable},
+		{name: "update without data", qb: NewUpdate().Where(Eq(pk, "u1"), Eq(sk, 2)), wantErr: ErrNoData},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.qb.ToDynamo("pk", "sk")
+			if tt.wantErr != nil {
+				if err == nil || (tt.wantErr != errAny && !errors.Is(err, tt.wantErr)) {
+					t.Fatalf("ToDynamo() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ToDynamo() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ToDynamo() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
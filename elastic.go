@@ -0,0 +1,27 @@
+package qbr
+
+import "github.com/tyrenix/qbr/internal/elasticbuilder"
+
+// ToElastic builds an Elasticsearch (or OpenSearch) search request body in the
+// query DSL from a read query builder. The result can be encoded with
+// json.Marshal and sent to the _search endpoint.
+//
+// And conditions are rendered into bool must, Or into bool should and Not into
+// bool must_not clauses. Equality becomes a term query, IN a terms query and
+// comparisons a range query, while NULL checks become exists queries. LIKE
+// patterns are rendered into wildcard queries. Sorts, offset and limit become
+// sort, from and size, and select fields become the _source list.
+//
+// The query is checked with Validate first. It returns an error if the query
+// is not a read query or uses a feature that cannot be expressed in the query
+// DSL, such as locks, suffixes, returning fields, MaxAffected, ClickHouse
+// features or aggregations.
+func (qb *Query) ToElastic() (map[string]any, error) {
+	// validate query
+	if err := qb.Validate(); err != nil {
+		return nil, err
+	}
+
+	// build body
+	return elasticbuilder.CreateSearchBody(qb)
+}
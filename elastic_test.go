@@ -0,0 +1,115 @@
+package qbr
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+func TestToElastic(t *testing.T) {
+	id := NewField(WithDB("id"))
+	name := NewField(WithDB("name"))
+	age := NewField(WithDB("age"))
+
+	tests := []struct {
+		name    string
+		qb      *Query
+		want    map[string]any
+		wantErr error
+	}{
+		{
+			name: "match all",
+			qb:   NewRead(),
+			want: map[string]any{"query": map[string]any{"match_all": map[string]any{}}},
+		},
+		{
+			name: "single condition",
+			qb:   NewRead().Where(Eq(name, "a")),
+			want: map[string]any{"query": map[string]any{"term": map[string]any{"name": "a"}}},
+		},
+		{
+			name: "bool clauses",
+			qb:   NewRead().Where(GtOrEq(age, 18), Or(In(id, 1, 2), NoEq(name, "b"))),
+			want: map[string]any{"query": map[string]any{"bool": map[string]any{"must": []any{
+				map[string]any{"range": map[string]any{"age": map[string]any{"gte": 18}}},
+				map[string]any{"bool": map[string]any{
+					"should": []any{
+						map[string]any{"terms": map[string]any{"id": []any{1, 2}}},
+						map[string]any{"bool": map[string]any{"must_not": []any{
+							map[string]any{"term": map[string]any{"name": "b"}},
+						}}},
+					},
+					"minimum_should_match": 1,
+				}},
+			}}}},
+		},
+		{
+			name: "not and null",
+			qb:   NewRead().Where(Not(Eq(name, domain.ValueNull)), Not(Eq(id, 1), Eq(age, 2))),
+			want: map[string]any{"query": map[string]any{"bool": map[string]any{"must": []any{
+				map[string]any{"bool": map[string]any{"must_not": []any{
+					map[string]any{"bool": map[string]any{"must_not": []any{
+						map[string]any{"exists": map[string]any{"field": "name"}},
+					}}},
+				}}},
+				map[string]any{"bool": map[string]any{"must_not": []any{
+					map[string]any{"bool": map[string]any{"must": []any{
+						map[string]any{"term": map[string]any{"id": 1}},
+						map[string]any{"term": map[string]any{"age": 2}},
+					}}},
+				}}},
+			}}}},
+		},
+		{
+			name: "wildcards",
+			qb:   NewRead().Where(ILike(name, EscapeLike("a_*")+"%_")),
+			want: map[string]any{"query": map[string]any{"wildcard": map[string]any{
+				"name": map[string]any{"value": `a_\**?`, "case_insensitive": true},
+			}}},
+		},
+		{
+			name: "source sort and pagination",
+			qb:   NewRead().Select(id, name).Sort(NewSortDesc(age)).Limit(10).Offset(20),
+			want: map[string]any{
+				"query":   map[string]any{"match_all": map[string]any{}},
+				"_source": []string{"id", "name"},
+				"sort":    []any{map[string]any{"age": map[string]any{"order": "desc"}}},
+				"from":    uint64(20),
+				"size":    uint64(10),
+			},
+		},
+		{name: "empty in", qb: NewRead().Where(In(id)), wantErr: ErrEmptyIn},
+		{name: "invalid field", qb: NewRead().Where(Eq(NewField(WithDB("a b")), 1)), wantErr: errAny},
+		{name: "not read", qb: NewDelete().Where(Eq(id, 1)), wantErr: errAny},
+		{name: "lock", qb: NewRead().Lock(), wantErr: errAny},
+		{name: "suffix", qb: NewRead().Suffix("LIMIT 1"), wantErr: errAny},
+		{name: "returning", qb: NewRead().Returning(id), wantErr: errAny},
+		{name: "max affected", qb: NewRead().MaxAffected(1), wantErr: errAny},
+		{name: "final", qb: NewRead().Final(), wantErr: errAny},
+		{name: "sample", qb: NewRead().Sample(0.5), wantErr: errAny},
+		{name: "prewhere", qb: NewRead().Prewhere(Eq(id, 1)), wantErr: errAny},
+		{name: "limit by", qb: NewRead().LimitBy(1, id), wantErr: errAny},
+		{name: "mutation", qb: NewDelete().Where(Eq(id, 1)).Mutation(), wantErr: errAny},
+		{name: "aggregation", qb: NewRead().Select(NewCountField(id)), wantErr: errAny},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.qb.ToElastic()
+			if tt.wantErr != nil {
+				if err == nil || (tt.wantErr != errAny && !errors.Is(err, tt.wantErr)) {
+					t.Fatalf("ToElastic() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ToElastic() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ToElastic() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
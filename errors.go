@@ -0,0 +1,25 @@
+package qbr
+
+import "github.com/tyrenix/qbr/domain"
+
+// Query errors, returned by Validate, ToSql and query decoding.
+//
+// ErrNoData is returned for create and update queries without data,
+// ErrEmptyIn for IN conditions without values, and ErrFullTable for update and
+// delete queries without conditions unless AllowFullTable is set.
+// ErrFieldNotAllowed is returned when decoding a query that references a field
+// outside of the allowed fields.
+var (
+	ErrNoData          = domain.ErrNoData
+	ErrEmptyIn         = domain.ErrEmptyIn
+	ErrFullTable       = domain.ErrFullTable
+	ErrFieldNotAllowed = domain.ErrFieldNotAllowed
+)
+
+// ErrUnsupportedOperator is returned when a condition uses an operator that is
+// not supported. It can be matched with errors.As.
+type ErrUnsupportedOperator = domain.ErrUnsupportedOperator
+
+// ErrInvalidIdentifier is returned when a table or field name is not a valid
+// SQL identifier. It can be matched with errors.As.
+type ErrInvalidIdentifier = domain.ErrInvalidIdentifier
@@ -0,0 +1,69 @@
+// Package exec runs queries built by qbr against a database/sql handle.
+package exec
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/tyrenix/qbr"
+	"github.com/tyrenix/qbr/domain"
+)
+
+// Executor is the minimal database handle required to run queries built by qbr.
+//
+// It is satisfied by *sql.DB, *sql.Tx and *sql.Conn, so the same code can run
+// inside or outside of a transaction.
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Exec builds the query for the given table and dialect and executes it
+// without returning any rows. It is intended for INSERT, UPDATE and DELETE
+// queries whose RETURNING result is not needed.
+//
+// Returns the sql.Result of the execution, or an error if the query could not
+// be built or executed.
+func Exec(ctx context.Context, db Executor, qb *qbr.Query, table string, dialect domain.SqlDialect) (sql.Result, error) {
+	// build query
+	query, params, err := qb.ToSqlDialect(table, dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	// execute query
+	return db.ExecContext(ctx, query, params...)
+}
+
+// QueryRows builds the query for the given table and dialect and executes
+// it, returning the resulting rows. It works for SELECT queries as well as for
+// INSERT, UPDATE and DELETE queries with RETURNING fields.
+//
+// The caller is responsible for closing the returned rows.
+func QueryRows(ctx context.Context, db Executor, qb *qbr.Query, table string, dialect domain.SqlDialect) (*sql.Rows, error) {
+	// build query
+	query, params, err := qb.ToSqlDialect(table, dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	// execute query
+	return db.QueryContext(ctx, query, params...)
+}
+
+// QueryRow builds the query for the given table and dialect and executes
+// it, returning at most one row.
+//
+// An error is returned only if the query could not be built; execution errors
+// are deferred to the Scan method of the returned row, as with sql.DB.QueryRow.
+func QueryRow(ctx context.Context, db Executor, qb *qbr.Query, table string, dialect domain.SqlDialect) (*sql.Row, error) {
+	// build query
+	query, params, err := qb.ToSqlDialect(table, dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	// execute query
+	return db.QueryRowContext(ctx, query, params...), nil
+}
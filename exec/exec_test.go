@@ -0,0 +1,189 @@
+package exec
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	"github.com/tyrenix/qbr"
+	"github.com/tyrenix/qbr/domain"
+	"github.com/tyrenix/qbr/internal/fakedb"
+)
+
+var (
+	idField   = qbr.NewField(qbr.WithDB("id"))
+	nameField = qbr.NewField(qbr.WithDB("name"))
+)
+
+func TestExec(t *testing.T) {
+	tests := []struct {
+		name     string
+		qb       *qbr.Query
+		dialect  domain.SqlDialect
+		query    string
+		args     []any
+		affected int64
+	}{
+		{
+			name:     "postgres update",
+			qb:       qbr.NewUpdate().Set(qbr.NewData(nameField, "a")).Where(qbr.Eq(idField, 1)).NoReturning(),
+			dialect:  qbr.SqlPostgres,
+			query:    "UPDATE users SET name = $1 WHERE id = $2",
+			args:     []any{"a", int64(1)},
+			affected: 1,
+		},
+		{
+			name:     "mysql delete",
+			qb:       qbr.NewDelete().Where(qbr.Eq(idField, 1)).NoReturning(),
+			dialect:  qbr.SqlMySQL,
+			query:    "DELETE FROM users WHERE id = ?",
+			args:     []any{int64(1)},
+			affected: 2,
+		},
+		{
+			name:     "clickhouse update mutation",
+			qb:       qbr.NewUpdate().Set(qbr.NewData(nameField, "a")).Where(qbr.Eq(idField, 1)).NoReturning(),
+			dialect:  qbr.SqlClickHouse,
+			query:    "ALTER TABLE users UPDATE name = ? WHERE id = ?",
+			args:     []any{"a", int64(1)},
+			affected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, rec := fakedb.Open()
+			defer db.Close()
+			rec.Push(fakedb.Result{RowsAffected: tt.affected})
+
+			res, err := Exec(context.Background(), db, tt.qb, "users", tt.dialect)
+			if err != nil {
+				t.Fatalf("Exec() error = %v", err)
+			}
+			if n, _ := res.RowsAffected(); n != tt.affected {
+				t.Errorf("RowsAffected() = %d, want %d", n, tt.affected)
+			}
+
+			want := []fakedb.Statement{{Query: tt.query, Args: tt.args}}
+			if got := rec.Statements(); !reflect.DeepEqual(got, want) {
+				t.Errorf("statements = %#v, want %#v", got, want)
+			}
+		})
+	}
+}
+
+func TestQueryRows(t *testing.T) {
+	tests := []struct {
+		name    string
+		qb      *qbr.Query
+		dialect domain.SqlDialect
+		query   string
+	}{
+		{
+			name:    "postgres returning",
+			qb:      qbr.NewDelete().Where(qbr.Eq(idField, 1)).Returning(idField),
+			dialect: qbr.SqlPostgres,
+			query:   "DELETE FROM users WHERE id = $1 RETURNING id",
+		},
+		{
+			name:    "sqlserver output",
+			qb:      qbr.NewDelete().Where(qbr.Eq(idField, 1)).Returning(idField),
+			dialect: qbr.SqlSQLServer,
+			query:   "DELETE FROM users OUTPUT DELETED.id WHERE id = @p1",
+		},
+		{
+			name:    "select",
+			qb:      qbr.NewRead().Select(idField).Where(qbr.Eq(idField, 1)),
+			dialect: qbr.SqlSQLite,
+			query:   "SELECT id FROM users WHERE id = ?",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, rec := fakedb.Open()
+			defer db.Close()
+			rec.Push(fakedb.Result{Columns: []string{"id"}, Rows: [][]driver.Value{{int64(1)}, {int64(2)}}})
+
+			rows, err := QueryRows(context.Background(), db, tt.qb, "users", tt.dialect)
+			if err != nil {
+				t.Fatalf("QueryRows() error = %v", err)
+			}
+			defer rows.Close()
+
+			var ids []int64
+			for rows.Next() {
+				var id int64
+				if err := rows.Scan(&id); err != nil {
+					t.Fatalf("Scan() error = %v", err)
+				}
+				ids = append(ids, id)
+			}
+			if !reflect.DeepEqual(ids, []int64{1, 2}) {
+				t.Errorf("ids = %v, want [1 2]", ids)
+			}
+
+			if got := rec.Statements()[0].Query; got != tt.query {
+				t.Errorf("query = %q, want %q", got, tt.query)
+			}
+		})
+	}
+}
+
+func TestQueryRow(t *testing.T) {
+	db, rec := fakedb.Open()
+	defer db.Close()
+	rec.Push(fakedb.Result{Columns: []string{"count"}, Rows: [][]driver.Value{{int64(3)}}})
+
+	qb := qbr.NewRead().Select(qbr.NewCountField(qbr.NewAllField()))
+	row, err := QueryRow(context.Background(), db, qb, "users", qbr.SqlPostgres)
+	if err != nil {
+		t.Fatalf("QueryRow() error = %v", err)
+	}
+
+	var count int64
+	if err := row.Scan(&count); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if count != 3 {
+		t.Errorf("count = %d, want 3", count)
+	}
+	if got, want := rec.Statements()[0].Query, "SELECT COUNT(*) FROM users"; got != want {
+		t.Errorf("query = %q, want %q", got, want)
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		qb      *qbr.Query
+		dialect domain.SqlDialect
+	}{
+		{name: "unsupported dialect", qb: qbr.NewRead(), dialect: "oracle"},
+		{name: "mysql default returning", qb: qbr.NewDelete().Where(qbr.Eq(idField, 1)), dialect: qbr.SqlMySQL},
+		{name: "clickhouse default returning", qb: qbr.NewDelete().Where(qbr.Eq(idField, 1)), dialect: qbr.SqlClickHouse},
+		{name: "mysql explicit returning", qb: qbr.NewDelete().Where(qbr.Eq(idField, 1)).Returning(idField), dialect: qbr.SqlMySQL},
+		{name: "unsupported value type", qb: qbr.NewRead().Where(qbr.Eq(idField, domain.ValueType(100))), dialect: qbr.SqlPostgres},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, rec := fakedb.Open()
+			defer db.Close()
+
+			if _, err := Exec(context.Background(), db, tt.qb, "users", tt.dialect); err == nil {
+				t.Error("Exec() error = nil, want error")
+			}
+			if _, err := QueryRows(context.Background(), db, tt.qb, "users", tt.dialect); err == nil {
+				t.Error("QueryRows() error = nil, want error")
+			}
+			if _, err := QueryRow(context.Background(), db, tt.qb, "users", tt.dialect); err == nil {
+				t.Error("QueryRow() error = nil, want error")
+			}
+			if n := len(rec.Statements()); n != 0 {
+				t.Errorf("executed %d statements, want 0", n)
+			}
+		})
+	}
+}
@@ -124,6 +124,33 @@ func NewCountField(field *domain.Field) *domain.Field {
 	}
 }
 
+// NewUniqField creates a new Field model with ClickHouse uniq aggregation type,
+// which approximates the number of distinct values.
+//
+// It takes the existing Field model and creates a new one with the same
+// DB field and with AggregationType set to AggregationUniq.
+//
+// Returns the created Field model with uniq aggregation type.
+func NewUniqField(field *domain.Field) *domain.Field {
+	return &domain.Field{
+		DB:          field.DB,
+		Aggregation: domain.AggregationUniq,
+	}
+}
+
+// NewQuantileField creates a new Field model with ClickHouse quantile
+// aggregation type at the given level, for example 0.95. A level of 0 uses
+// the ClickHouse default, the median.
+//
+// Returns the created Field model with quantile aggregation type.
+func NewQuantileField(field *domain.Field, level float64) *domain.Field {
+	return &domain.Field{
+		DB:          field.DB,
+		Aggregation: domain.AggregationQuantile,
+		Level:       level,
+	}
+}
+
 // IsFieldEqual checks if two Field objects are equal by comparing their
 // DB field names. If either of the input Field objects is nil, the function
 // returns false.
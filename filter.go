@@ -0,0 +1,205 @@
+package qbr
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+// WhereStruct adds conditions built from the given filter struct, as described
+// by ConditionsFromStruct. If the conditions cannot be built, the error is
+// returned when the query is built. Returns the modified QueryBuilder instance
+// for method chaining.
+func (qb *Query) WhereStruct(filter any) *Query {
+	// create conditions
+	conds, err := ConditionsFromStruct(filter)
+	if err != nil {
+		return qb.setError(err)
+	}
+
+	// add conditions
+	return qb.Where(conds...)
+}
+
+// ConditionsFromStruct builds conditions from a filter struct, such as a request
+// DTO, using the same "db" and "qbr" tags as SetStruct:
+//
+//	type UserFilter struct {
+//		MinAge   *int     `db:"age" qbr:"op=gte"`
+//		Name     *string  `db:"name" qbr:"op=ilike"`
+//		Statuses []string `db:"status"`
+//		Contact  struct {
+//			Email *string `db:"email"`
+//			Phone *string `db:"phone"`
+//		} `qbr:"or"`
+//	}
+//
+// The operator of a field is set with qbr:"op=<name>", where name is an
+// operator name such as "eq", "gte" or "ilike", and defaults to equality. Nil
+// pointers, empty slices and zero non-pointer values are skipped, and pointers
+// are dereferenced. Slices become IN conditions. Nested structs without a "db"
+// tag are traversed, and their conditions are grouped with Or if tagged with
+// qbr:"or" or added as they are otherwise.
+//
+// The filter is dereferenced if it is a pointer. It returns an error if the
+// filter is not a struct or uses an unknown or unsupported operator.
+func ConditionsFromStruct(filter any) ([]domain.Condition, error) {
+	// struct value
+	val := reflect.ValueOf(filter)
+
+	// dereference pointer
+	if val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return nil, nil
+		}
+		val = val.Elem()
+	}
+
+	// check is struct
+	if val.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("unsupported filter type: %T", filter)
+	}
+
+	// build conditions
+	return extractConditionsFromStruct(val)
+}
+
+// extractConditionsFromStruct builds conditions from the fields of the struct
+// value.
+func extractConditionsFromStruct(val reflect.Value) ([]domain.Condition, error) {
+	// struct type
+	t := val.Type()
+
+	// conditions
+	var conds []domain.Condition
+
+	// we go through the fields of the structure
+	for i := 0; i < val.NumField(); i++ {
+		// field type
+		ft := t.Field(i)
+		// struct field
+		fv := val.Field(i)
+
+		// skip unexported fields
+		if !ft.IsExported() {
+			continue
+		}
+
+		// extract field
+		field := extractFieldFromStruct(ft)
+
+		// nested struct
+		if field == nil {
+			// dereference pointer
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+
+			// check is struct
+			if fv.Kind() != reflect.Struct {
+				continue
+			}
+
+			// build nested conditions
+			nested, err := extractConditionsFromStruct(fv)
+			if err != nil {
+				return nil, err
+			}
+
+			// check is nested conditions exists
+			if len(nested) == 0 {
+				continue
+			}
+
+			// group nested conditions
+			if hasAnnotation(ft, domain.QueryOr) {
+				conds = append(conds, Or(nested...))
+			} else {
+				conds = append(conds, nested...)
+			}
+			continue
+		}
+
+		// create condition
+		cond, ok, err := extractConditionFromField(ft, fv, field)
+		if err != nil {
+			return nil, err
+		}
+
+		// add condition
+		if ok {
+			conds = append(conds, cond)
+		}
+	}
+
+	// return conditions
+	return conds, nil
+}
+
+// extractConditionFromField builds a condition from a tagged filter field. It
+// returns false if the field value is nil, empty or zero and must be skipped.
+func extractConditionFromField(ft reflect.StructField, fv reflect.Value, field *domain.Field) (domain.Condition, bool, error) {
+	// get operator
+	op := domain.OperatorEqual
+	if name := getAnnotationValue(ft, domain.QueryOp); name != "" {
+		var err error
+		if op, err = ParseOperator(name); err != nil {
+			return domain.Condition{}, false, err
+		}
+	}
+
+	// check is operator supported
+	if IsLogicalOperator(op) {
+		return domain.Condition{}, false, ErrUnsupportedOperator{Op: op}
+	}
+
+	// check is zero
+	if isZero(fv.Interface()) {
+		return domain.Condition{}, false, nil
+	}
+
+	// dereference pointer
+	if fv.Kind() == reflect.Ptr {
+		fv = fv.Elem()
+	}
+
+	// slice values
+	if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+		// check operator
+		if op != domain.OperatorEqual && op != domain.OperatorIn {
+			return domain.Condition{}, false, fmt.Errorf(
+				"unsupported operator %q for slice field: %s", OperatorName(op), ft.Name,
+			)
+		}
+
+		// check is empty
+		if fv.Len() == 0 {
+			return domain.Condition{}, false, nil
+		}
+
+		// create values
+		vals := make([]any, fv.Len())
+		for i := range vals {
+			vals[i] = fv.Index(i).Interface()
+		}
+
+		// return in condition
+		return In(field, vals...), true, nil
+	}
+
+	// single in value
+	if op == domain.OperatorIn {
+		return In(field, fv.Interface()), true, nil
+	}
+
+	// return condition
+	return domain.Condition{
+		Field:    field,
+		Operator: op,
+		Value:    fv.Interface(),
+	}, true, nil
+}
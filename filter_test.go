@@ -0,0 +1,73 @@
+package qbr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWhereStruct(t *testing.T) {
+	type contact struct {
+		Email *string `db:"email"`
+		Phone *string `db:"phone"`
+	}
+	type filter struct {
+		MinAge   *int     `db:"age" qbr:"op=gte"`
+		Name     *string  `db:"name" qbr:"op=ilike"`
+		Statuses []string `db:"status"`
+		Contact  contact  `qbr:"or"`
+	}
+	type badOp struct {
+		Age *int `db:"age" qbr:"op=between"`
+	}
+	type badSlice struct {
+		Ages []int `db:"age" qbr:"op=gt"`
+	}
+
+	age, name, email, phone := 18, "a%", "a@b.c", "123"
+
+	tests := []struct {
+		name    string
+		filter  any
+		want    string
+		args    []any
+		wantErr bool
+	}{
+		{
+			name:   "all fields",
+			filter: filter{MinAge: &age, Name: &name, Statuses: []string{"x", "y"}, Contact: contact{Email: &email, Phone: &phone}},
+			want:   `SELECT * FROM users WHERE age >= $1 AND name ILIKE $2 AND status IN ($3, $4) AND (email = $5 OR phone = $6)`,
+			args:   []any{18, "a%", "x", "y", "a@b.c", "123"},
+		},
+		{
+			name:   "skip nil and empty",
+			filter: &filter{MinAge: &age, Statuses: []string{}},
+			want:   "SELECT * FROM users WHERE age >= $1",
+			args:   []any{18},
+		},
+		{
+			name:   "nil filter",
+			filter: (*filter)(nil),
+			want:   "SELECT * FROM users",
+		},
+		{name: "not struct", filter: 1, wantErr: true},
+		{name: "unknown operator", filter: badOp{Age: &age}, wantErr: true},
+		{name: "slice operator", filter: badSlice{Ages: []int{1}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, args, err := NewRead().WhereStruct(tt.filter).ToSql("users", SqlDollar)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ToSql() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ToSql() = %q, want %q", got, tt.want)
+			}
+			if len(args) != 0 || len(tt.args) != 0 {
+				if !reflect.DeepEqual(args, tt.args) {
+					t.Errorf("args = %#v, want %#v", args, tt.args)
+				}
+			}
+		})
+	}
+}
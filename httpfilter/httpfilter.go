@@ -0,0 +1,336 @@
+// Package httpfilter parses URL query strings into qbr conditions, sorts and
+// pagination against a declared schema.
+//
+// A query string such as
+//
+//	?age[gte]=18&status[in]=a,b&sort=-created_at&limit=20
+//
+// is parsed into the conditions age >= 18 AND status IN ('a', 'b'), a
+// descending sort by created_at and a limit of 20. Only fields and operators
+// declared in the schema are accepted, and values are coerced to the declared
+// Go types.
+package httpfilter
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tyrenix/qbr"
+	"github.com/tyrenix/qbr/domain"
+)
+
+// Reserved query parameter names.
+const (
+	ParamSort   = "sort"
+	ParamLimit  = "limit"
+	ParamOffset = "offset"
+)
+
+// Type is the Go value type that filter values are coerced to.
+type Type int
+
+// Value types.
+const (
+	TypeString Type = iota // string
+	TypeInt                // int64
+	TypeFloat              // float64
+	TypeBool               // bool
+	TypeTime               // time.Time, RFC 3339 or date only
+)
+
+// Field declares a filterable field of the schema.
+type Field struct {
+	Name      string                // Query parameter name.
+	Field     *domain.Field         // Database field.
+	Type      Type                  // Value type.
+	Operators []domain.OperatorType // Allowed operators, only equality if empty.
+	Sortable  bool                  // Field can be used in sort.
+}
+
+// Schema declares the fields, operators and pagination accepted by Parse.
+type Schema struct {
+	Fields        []Field
+	DefaultLimit  uint64 // Limit used when no limit is given, 0 for none.
+	MaxLimit      uint64 // Maximum accepted limit, 0 for no maximum.
+	IgnoreUnknown bool   // Ignore unknown parameters instead of failing.
+}
+
+// Result is the parsed filter.
+type Result struct {
+	Conditions []domain.Condition
+	Sort       []domain.Sort
+	Limit      uint64
+	Offset     uint64
+	HasLimit   bool // Limit was given or defaulted by the schema.
+	HasOffset  bool // Offset was given.
+}
+
+// FieldError is a validation error of a single query parameter.
+type FieldError struct {
+	Param   string // Query parameter name, including the operator.
+	Message string // Validation error message.
+}
+
+// Error implements the error interface.
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Param, e.Message)
+}
+
+// Errors is a list of field validation errors returned by Parse.
+type Errors []*FieldError
+
+// Error implements the error interface.
+func (e Errors) Error() string {
+	// error messages
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+
+	// return joined messages
+	return strings.Join(msgs, "; ")
+}
+
+// Parse parses the URL values into conditions, sorts, limit and offset.
+//
+// Filter parameters have the form name=value or name[op]=value, where op is an
+// operator name such as "eq", "ne", "lt", "gt", "lte", "gte" or "in". Values
+// of "in" are comma separated. The sort parameter is a comma separated list of
+// sortable field names, prefixed with "-" for descending order.
+//
+// All parameters are validated, and if any of them is invalid an Errors value
+// with an error for every invalid parameter is returned.
+func (s *Schema) Parse(values url.Values) (*Result, error) {
+	// result and errors
+	res := &Result{Limit: s.DefaultLimit, HasLimit: s.DefaultLimit > 0}
+	var errs Errors
+
+	// sorted parameter names for stable output
+	params := make([]string, 0, len(values))
+	for param := range values {
+		params = append(params, param)
+	}
+	slices.Sort(params)
+
+	// parse parameters
+	for _, param := range params {
+		for _, raw := range values[param] {
+			switch param {
+			case ParamSort:
+				sorts, err := s.parseSort(raw)
+				if err != nil {
+					errs = append(errs, &FieldError{Param: param, Message: err.Error()})
+					continue
+				}
+				res.Sort = append(res.Sort, sorts...)
+			case ParamLimit:
+				limit, err := strconv.ParseUint(raw, 10, 64)
+				if err != nil {
+					errs = append(errs, &FieldError{Param: param, Message: "must be a non-negative integer"})
+					continue
+				}
+				if s.MaxLimit > 0 && limit > s.MaxLimit {
+					errs = append(errs, &FieldError{Param: param, Message: fmt.Sprintf("must not exceed %d", s.MaxLimit)})
+					continue
+				}
+				res.Limit, res.HasLimit = limit, true
+			case ParamOffset:
+				offset, err := strconv.ParseUint(raw, 10, 64)
+				if err != nil {
+					errs = append(errs, &FieldError{Param: param, Message: "must be a non-negative integer"})
+					continue
+				}
+				res.Offset, res.HasOffset = offset, true
+			default:
+				cond, err := s.parseCondition(param, raw)
+				if err != nil {
+					errs = append(errs, &FieldError{Param: param, Message: err.Error()})
+					continue
+				}
+				if cond != nil {
+					res.Conditions = append(res.Conditions, *cond)
+				}
+			}
+		}
+	}
+
+	// check errors
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	// return result
+	return res, nil
+}
+
+// Apply adds the parsed conditions and sorts to the query, and sets the limit
+// and offset if they are present in the result, keeping those already set on
+// the query otherwise. Returns the modified query for method chaining.
+func (r *Result) Apply(qb *qbr.Query) *qbr.Query {
+	// add sorts
+	for i := range r.Sort {
+		qb = qb.Sort(&r.Sort[i])
+	}
+
+	// add conditions
+	qb = qb.Where(r.Conditions...)
+
+	// set limit
+	if r.HasLimit {
+		qb = qb.Limit(r.Limit)
+	}
+
+	// set offset
+	if r.HasOffset {
+		qb = qb.Offset(r.Offset)
+	}
+
+	// return query
+	return qb
+}
+
+// parseCondition parses a filter parameter into a condition. It returns nil if
+// the parameter is unknown and the schema ignores unknown parameters.
+func (s *Schema) parseCondition(param, raw string) (*domain.Condition, error) {
+	// split name and operator
+	name, opName := param, "eq"
+	if i := strings.IndexByte(param, '['); i >= 0 && strings.HasSuffix(param, "]") {
+		name, opName = param[:i], param[i+1:len(param)-1]
+	}
+
+	// get field
+	field := s.field(name)
+	if field == nil {
+		if s.IgnoreUnknown {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unknown field")
+	}
+
+	// get operator
+	op, err := qbr.ParseOperator(opName)
+	if err != nil || qbr.IsLogicalOperator(op) {
+		return nil, fmt.Errorf("unknown operator %q", opName)
+	}
+
+	// check is operator allowed
+	if !field.allows(op) {
+		return nil, fmt.Errorf("operator %q is not allowed", opName)
+	}
+
+	// in condition
+	if op == domain.OperatorIn {
+		// coerce values
+		var vals []any
+		for _, part := range strings.Split(raw, ",") {
+			v, err := coerce(part, field.Type)
+			if err != nil {
+				return nil, err
+			}
+			vals = append(vals, v)
+		}
+
+		// return condition
+		cond := qbr.In(field.Field, vals...)
+		return &cond, nil
+	}
+
+	// coerce value
+	v, err := coerce(raw, field.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	// return condition
+	return &domain.Condition{Field: field.Field, Operator: op, Value: v}, nil
+}
+
+// parseSort parses a sort parameter into sorts.
+func (s *Schema) parseSort(raw string) ([]domain.Sort, error) {
+	// sorts
+	var sorts []domain.Sort
+	for _, name := range strings.Split(raw, ",") {
+		// get sort type
+		sortType := domain.SortAsc
+		if n, ok := strings.CutPrefix(name, "-"); ok {
+			name, sortType = n, domain.SortDesc
+		}
+
+		// get field
+		field := s.field(name)
+		if field == nil || !field.Sortable {
+			return nil, fmt.Errorf("field %q is not sortable", name)
+		}
+
+		// add sort
+		sorts = append(sorts, domain.Sort{Field: field.Field, Type: sortType})
+	}
+
+	// return sorts
+	return sorts, nil
+}
+
+// field returns the schema field with the given name, or nil if not found.
+func (s *Schema) field(name string) *Field {
+	for i := range s.Fields {
+		if s.Fields[i].Name == name {
+			return &s.Fields[i]
+		}
+	}
+	return nil
+}
+
+// allows reports whether the operator is allowed for the field.
+func (f *Field) allows(op domain.OperatorType) bool {
+	// only equality by default
+	if len(f.Operators) == 0 {
+		return op == domain.OperatorEqual
+	}
+
+	// check allowed operators
+	return slices.Contains(f.Operators, op)
+}
+
+// coerce converts the raw string value to the given type.
+func coerce(raw string, t Type) (any, error) {
+	switch t {
+	case TypeString:
+		return raw, nil
+	case TypeInt:
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q", raw)
+		}
+		return v, nil
+	case TypeFloat:
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", raw)
+		}
+		return v, nil
+	case TypeBool:
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid boolean %q", raw)
+		}
+		return v, nil
+	case TypeTime:
+		// rfc 3339 time
+		if v, err := time.Parse(time.RFC3339Nano, raw); err == nil {
+			return v, nil
+		}
+
+		// date only
+		v, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time %q", raw)
+		}
+		return v, nil
+	default:
+		return nil, fmt.Errorf("unsupported value type: %d", t)
+	}
+}
@@ -0,0 +1,112 @@
+package httpfilter
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/tyrenix/qbr"
+	"github.com/tyrenix/qbr/domain"
+)
+
+var (
+	ageField    = qbr.NewField(qbr.WithDB("age"))
+	statusField = qbr.NewField(qbr.WithDB("status"))
+	schema      = &Schema{
+		Fields: []Field{
+			{Name: "age", Field: ageField, Type: TypeInt, Operators: []domain.OperatorType{domain.OperatorGreaterThanOrEqual}, Sortable: true},
+			{Name: "status", Field: statusField, Type: TypeString, Operators: []domain.OperatorType{domain.OperatorIn}},
+		},
+		DefaultLimit: 20,
+		MaxLimit:     100,
+	}
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		schema    *Schema
+		want      string
+		wantArgs  []any
+		wantParam []string
+	}{
+		{
+			name:     "conditions sort and pagination",
+			query:    "age[gte]=18&status[in]=a,b&sort=-age&limit=5&offset=10",
+			want:     "SELECT * FROM users WHERE age >= $1 AND status IN ($2, $3) ORDER BY age DESC LIMIT 5 OFFSET 10",
+			wantArgs: []any{int64(18), "a", "b"},
+		},
+		{
+			name:  "default limit keeps query offset",
+			query: "",
+			want:  "SELECT * FROM users LIMIT 20 OFFSET 7",
+		},
+		{
+			name:   "no default limit keeps query limit",
+			query:  "offset=3",
+			schema: &Schema{Fields: schema.Fields},
+			want:   "SELECT * FROM users LIMIT 50 OFFSET 3",
+		},
+		{
+			name:      "invalid parameters",
+			query:     "age=1&age[gte]=x&limit=1000&sort=status&unknown=1",
+			wantParam: []string{"age", "age[gte]", "limit", "sort", "unknown"},
+		},
+		{
+			name:   "ignore unknown",
+			query:  "unknown=1",
+			schema: &Schema{Fields: schema.Fields, IgnoreUnknown: true},
+			want:   "SELECT * FROM users LIMIT 50 OFFSET 7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := tt.schema
+			if s == nil {
+				s = schema
+			}
+
+			// parse query
+			res, err := s.Parse(values)
+			if tt.wantParam != nil {
+				var errs Errors
+				if !errors.As(err, &errs) {
+					t.Fatalf("Parse() error = %v, want Errors", err)
+				}
+				var params []string
+				for _, e := range errs {
+					params = append(params, e.Param)
+				}
+				if !reflect.DeepEqual(params, tt.wantParam) {
+					t.Errorf("error params = %v, want %v", params, tt.wantParam)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			// apply to query with existing pagination
+			qb := res.Apply(qbr.NewRead().Limit(50).Offset(7))
+			got, args, err := qb.ToSql("users", qbr.SqlDollar)
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ToSql() = %q, want %q", got, tt.want)
+			}
+			if len(args) != 0 || len(tt.wantArgs) != 0 {
+				if !reflect.DeepEqual(args, tt.wantArgs) {
+					t.Errorf("args = %#v, want %#v", args, tt.wantArgs)
+				}
+			}
+		})
+	}
+}
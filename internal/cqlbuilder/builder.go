@@ -0,0 +1,285 @@
+package cqlbuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tyrenix/qbr/domain"
+	"github.com/tyrenix/qbr/internal/pkg/unsupported"
+)
+
+// CreateCql creates a CQL query from the Query's data. It returns the query
+// string, the parameters for the query, and an error if the query could not be
+// built or uses a feature that CQL does not support.
+func CreateCql(qb Query, table string, opts domain.CqlOptions) (string, []any, error) {
+	// check unsupported features
+	if err := unsupported.Check(qb, "cql"); err != nil {
+		return "", nil, err
+	}
+	if qb.GetOffset() > 0 {
+		return "", nil, fmt.Errorf("offset is not supported by cql queries")
+	}
+
+	// check options by operation
+	op := qb.GetOperation()
+	if opts.AllowFiltering && op != domain.OperationRead {
+		return "", nil, fmt.Errorf("allow filtering is only supported by cql select queries")
+	}
+	if opts.TTL > 0 && op != domain.OperationCreate && op != domain.OperationUpdate {
+		return "", nil, fmt.Errorf("ttl is only supported by cql insert and update queries")
+	}
+	if opts.IfNotExists && op != domain.OperationCreate {
+		return "", nil, fmt.Errorf("if not exists is only supported by cql insert queries")
+	}
+	if opts.IfExists && op != domain.OperationUpdate && op != domain.OperationDelete {
+		return "", nil, fmt.Errorf("if exists is only supported by cql update and delete queries")
+	}
+
+	// build query by operation
+	var query string
+	var params []any
+	var err error
+	switch op {
+	case domain.OperationCreate:
+		query, params, err = createInsertCql(qb, table, opts)
+	case domain.OperationRead:
+		query, params, err = createSelectCql(qb, table, opts)
+	case domain.OperationUpdate:
+		query, params, err = createUpdateCql(qb, table, opts)
+	case domain.OperationDelete:
+		query, params, err = createDeleteCql(qb, table, opts)
+	default:
+		return "", nil, fmt.Errorf("unsupported operation type: %s", op)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	// add suffix
+	if suffix := qb.GetSuffix(); suffix != "" {
+		query += " " + suffix
+	}
+
+	// return query and params
+	return query, params, nil
+}
+
+// createSelectCql creates a CQL SELECT query.
+func createSelectCql(qb Query, table string, opts domain.CqlOptions) (string, []any, error) {
+	// create select fields
+	var selects []string
+	for _, field := range qb.GetSelects() {
+		format, ok := cqlAggregationFormats[field.Aggregation]
+		if !ok {
+			return "", nil, fmt.Errorf("unsupported aggregation type: %d", field.Aggregation)
+		}
+		selects = append(selects, fmt.Sprintf(format, field.DB))
+	}
+	if len(selects) == 0 {
+		selects = []string{"*"}
+	}
+
+	// create base query
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(selects, ", "), table)
+
+	// create conditions
+	where, params, err := buildConditions(qb.GetConditions(), cqlOperators, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	if where != "" {
+		query += " WHERE " + where
+	}
+
+	// create sort
+	if sorts := qb.GetSort(); len(sorts) > 0 {
+		orders := make([]string, len(sorts))
+		for i, s := range sorts {
+			orders[i] = fmt.Sprintf("%s %s", s.Field.DB, s.Type)
+		}
+		query += " ORDER BY " + strings.Join(orders, ", ")
+	}
+
+	// add limit
+	if limit := qb.GetLimit(); limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	// add allow filtering
+	if opts.AllowFiltering {
+		query += " ALLOW FILTERING"
+	}
+
+	// return query and params
+	return query, params, nil
+}
+
+// createInsertCql creates a CQL INSERT query.
+func createInsertCql(qb Query, table string, opts domain.CqlOptions) (string, []any, error) {
+	// check conditions
+	if len(qb.GetConditions()) > 0 {
+		return "", nil, fmt.Errorf("conditions are not supported by cql insert queries")
+	}
+
+	// create columns and values
+	var columns, values []string
+	var params []any
+	for _, d := range qb.GetData() {
+		if _, ok := d.Value.(*domain.Modification); ok {
+			return "", nil, fmt.Errorf("modification is not supported by cql insert queries")
+		}
+		columns = append(columns, d.Field.DB)
+		values = append(values, "?")
+		params = append(params, toCqlValue(d.Value))
+	}
+
+	// create query
+	query := fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (%s)",
+		table,
+		strings.Join(columns, ", "),
+		strings.Join(values, ", "),
+	)
+
+	// add if not exists
+	if opts.IfNotExists {
+		query += " IF NOT EXISTS"
+	}
+
+	// add using
+	if using := buildUsing(opts); using != "" {
+		query += " " + using
+	}
+
+	// return query and params
+	return query, params, nil
+}
+
+// createUpdateCql creates a CQL UPDATE query. Add and Subtract modifications
+// become counter updates.
+func createUpdateCql(qb Query, table string, opts domain.CqlOptions) (string, []any, error) {
+	// create base query
+	query := "UPDATE " + table
+	if using := buildUsing(opts); using != "" {
+		query += " " + using
+	}
+
+	// create sets
+	var sets []string
+	var params []any
+	for _, d := range qb.GetData() {
+		// plain value
+		mod, ok := d.Value.(*domain.Modification)
+		if !ok {
+			sets = append(sets, fmt.Sprintf("%s = ?", d.Field.DB))
+			params = append(params, toCqlValue(d.Value))
+			continue
+		}
+
+		// get counter operator
+		op, ok := cqlModifications[mod.Operator]
+		if !ok {
+			return "", nil, fmt.Errorf("unsupported modification operator for cql updates: %d", mod.Operator)
+		}
+
+		// check counter column
+		if mod.Field == nil || mod.Field.DB != d.Field.DB {
+			return "", nil, fmt.Errorf("counter column %s can only be modified by itself", d.Field.DB)
+		}
+
+		// add counter update
+		sets = append(sets, fmt.Sprintf("%s = %s %s ?", d.Field.DB, d.Field.DB, op))
+		params = append(params, mod.Value)
+	}
+	query += " SET " + strings.Join(sets, ", ")
+
+	// add where and if
+	return buildWhereIf(qb, query, params, opts)
+}
+
+// createDeleteCql creates a CQL DELETE query. Select fields delete single
+// columns instead of the whole row.
+func createDeleteCql(qb Query, table string, opts domain.CqlOptions) (string, []any, error) {
+	// create columns
+	var columns []string
+	for _, field := range qb.GetSelects() {
+		if field.DB != "*" {
+			columns = append(columns, field.DB)
+		}
+	}
+
+	// create base query
+	query := "DELETE"
+	if len(columns) > 0 {
+		query += " " + strings.Join(columns, ", ")
+	}
+	query += " FROM " + table
+	if using := buildUsing(opts); using != "" {
+		query += " " + using
+	}
+
+	// add where and if
+	return buildWhereIf(qb, query, nil, opts)
+}
+
+// buildWhereIf adds the WHERE clause from conditions on primary key columns and
+// the IF clause from the other conditions or the IF EXISTS option.
+func buildWhereIf(qb Query, query string, params []any, opts domain.CqlOptions) (string, []any, error) {
+	// split conditions
+	keys, rest := splitConditions(qb.GetConditions(), opts.PrimaryKey)
+
+	// check where conditions
+	if len(keys) == 0 {
+		return "", nil, fmt.Errorf("cql %s requires conditions on primary key columns", qb.GetOperation())
+	}
+
+	// add where
+	where, params, err := buildConditions(keys, cqlOperators, params)
+	if err != nil {
+		return "", nil, err
+	}
+	query += " WHERE " + where
+
+	// check if clause
+	if opts.IfExists && len(rest) > 0 {
+		return "", nil, fmt.Errorf("if exists cannot be combined with if conditions")
+	}
+
+	// add if exists
+	if opts.IfExists {
+		return query + " IF EXISTS", params, nil
+	}
+
+	// add if conditions
+	if len(rest) > 0 {
+		cond, ifParams, err := buildConditions(rest, cqlIfOperators, params)
+		if err != nil {
+			return "", nil, err
+		}
+		query += " IF " + cond
+		params = ifParams
+	}
+
+	// return query and params
+	return query, params, nil
+}
+
+// buildUsing creates the USING clause from the TTL and timestamp options.
+func buildUsing(opts domain.CqlOptions) string {
+	// parameters
+	var using []string
+	if opts.TTL > 0 {
+		using = append(using, fmt.Sprintf("TTL %d", int64(opts.TTL.Seconds())))
+	}
+	if opts.Timestamp > 0 {
+		using = append(using, fmt.Sprintf("TIMESTAMP %d", opts.Timestamp))
+	}
+
+	// no parameters
+	if len(using) == 0 {
+		return ""
+	}
+
+	// return clause
+	return "USING " + strings.Join(using, " AND ")
+}
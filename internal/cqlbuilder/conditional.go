@@ -0,0 +1,115 @@
+package cqlbuilder
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+// buildConditions builds conditions joined by AND into a CQL clause and
+// appends their values to params. Operators are looked up in the given map,
+// nested And conditions are flattened, and Or and Not conditions are rejected
+// as CQL does not support them.
+func buildConditions(conds []domain.Condition, operators map[domain.OperatorType]string, params []any) (string, []any, error) {
+	// parts
+	var parts []string
+
+	// create parts
+	for _, cond := range conds {
+		switch cond.Operator {
+		case domain.OperatorAnd:
+			// assert nested conditions
+			nested, ok := cond.Value.([]domain.Condition)
+			if !ok {
+				return "", nil, fmt.Errorf("invalid value for logical operator %d", cond.Operator)
+			}
+
+			// flatten nested conditions
+			part, nestedParams, err := buildConditions(nested, operators, params)
+			if err != nil {
+				return "", nil, err
+			}
+			parts = append(parts, part)
+			params = nestedParams
+			continue
+		case domain.OperatorOr, domain.OperatorNot:
+			return "", nil, domain.ErrUnsupportedOperator{Op: cond.Operator}
+		}
+
+		// get operator
+		op, ok := operators[cond.Operator]
+		if !ok {
+			return "", nil, domain.ErrUnsupportedOperator{Op: cond.Operator}
+		}
+
+		// in values
+		if cond.Operator == domain.OperatorIn {
+			values, ok := cond.Value.([]any)
+			if !ok {
+				values = []any{cond.Value}
+			}
+			plcs := make([]string, len(values))
+			for i, v := range values {
+				plcs[i] = "?"
+				params = append(params, v)
+			}
+			parts = append(parts, fmt.Sprintf("%s IN (%s)", cond.Field.DB, strings.Join(plcs, ", ")))
+			continue
+		}
+
+		// null value
+		value := cond.Value
+		if isNull(value) {
+			if _, ok := operators[domain.OperatorNotEqual]; !ok {
+				return "", nil, fmt.Errorf("null comparison of column %s is not supported in where clauses", cond.Field.DB)
+			}
+			value = nil
+		}
+
+		// add part
+		parts = append(parts, fmt.Sprintf("%s %s ?", cond.Field.DB, op))
+		params = append(params, value)
+	}
+
+	// return clause
+	return strings.Join(parts, " AND "), params, nil
+}
+
+// splitConditions splits top-level conditions into conditions on primary key
+// columns and the rest. Without primary key columns all conditions are key
+// conditions.
+func splitConditions(conds []domain.Condition, primaryKey []string) (keys, rest []domain.Condition) {
+	// no primary key
+	if len(primaryKey) == 0 {
+		return conds, nil
+	}
+
+	// split conditions
+	for _, cond := range conds {
+		if cond.Field != nil && slices.Contains(primaryKey, cond.Field.DB) {
+			keys = append(keys, cond)
+			continue
+		}
+		rest = append(rest, cond)
+	}
+
+	// return conditions
+	return keys, rest
+}
+
+// isNull reports whether the value is domain.ValueNull.
+func isNull(value any) bool {
+	v, ok := value.(domain.ValueType)
+	return ok && v == domain.ValueNull
+}
+
+// toCqlValue converts domain.ValueNull to nil and returns other values as
+// they are.
+func toCqlValue(value any) any {
+	if isNull(value) {
+		return nil
+	}
+	return value
+}
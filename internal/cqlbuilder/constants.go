@@ -0,0 +1,38 @@
+package cqlbuilder
+
+import "github.com/tyrenix/qbr/domain"
+
+// cqlAggregationFormats is a map that defines CQL aggregation formats for different AggregationTypes.
+var cqlAggregationFormats = map[domain.AggregationType]string{
+	domain.AggregationNone:  "%s",
+	domain.AggregationCount: "COUNT(%s)",
+	domain.AggregationSum:   "SUM(%s)",
+}
+
+// cqlOperators is a map that defines CQL operators for OperatorTypes allowed in WHERE clauses.
+var cqlOperators = map[domain.OperatorType]string{
+	domain.OperatorEqual:              "=",
+	domain.OperatorLessThan:           "<",
+	domain.OperatorGreaterThan:        ">",
+	domain.OperatorLessThanOrEqual:    "<=",
+	domain.OperatorGreaterThanOrEqual: ">=",
+	domain.OperatorIn:                 "IN",
+	domain.OperatorLike:               "LIKE",
+}
+
+// cqlIfOperators is a map that defines CQL operators for OperatorTypes allowed in IF clauses.
+var cqlIfOperators = map[domain.OperatorType]string{
+	domain.OperatorEqual:              "=",
+	domain.OperatorNotEqual:           "!=",
+	domain.OperatorLessThan:           "<",
+	domain.OperatorGreaterThan:        ">",
+	domain.OperatorLessThanOrEqual:    "<=",
+	domain.OperatorGreaterThanOrEqual: ">=",
+	domain.OperatorIn:                 "IN",
+}
+
+// cqlModifications is a map that defines CQL counter operators for ModificationTypes.
+var cqlModifications = map[domain.ModificationType]string{
+	domain.ModificationAdd:      "+",
+	domain.ModificationSubtract: "-",
+}
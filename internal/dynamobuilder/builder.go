@@ -0,0 +1,262 @@
+package dynamobuilder
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/tyrenix/qbr/domain"
+	"github.com/tyrenix/qbr/internal/pkg/unsupported"
+)
+
+// CreateDynamoQuery creates DynamoDB expressions from the Query's data. Keys
+// are the names of the table's key attributes, partition key first. It returns
+// the expressions and an error if the query cannot be expressed in DynamoDB.
+func CreateDynamoQuery(qb Query, keys []string) (*domain.DynamoQuery, error) {
+	// check unsupported features
+	if err := unsupported.Check(qb, "dynamodb"); err != nil {
+		return nil, err
+	}
+	if qb.GetSuffix() != "" {
+		return nil, fmt.Errorf("suffix is not supported by dynamodb queries")
+	}
+	if qb.GetOffset() > 0 {
+		return nil, fmt.Errorf("offset is not supported by dynamodb queries")
+	}
+
+	// check limit
+	if qb.GetLimit() > math.MaxInt32 {
+		return nil, fmt.Errorf("limit exceeds dynamodb maximum: %d", qb.GetLimit())
+	}
+
+	// create query
+	dq := &domain.DynamoQuery{Limit: int32(qb.GetLimit())}
+	ph := newPlaceholders()
+
+	// split key conditions
+	var keyConds, conds []domain.Condition
+	for _, cond := range qb.GetConditions() {
+		if cond.Field != nil && slices.Contains(keys, cond.Field.DB) {
+			keyConds = append(keyConds, cond)
+			continue
+		}
+		conds = append(conds, cond)
+	}
+
+	// create expressions by operation
+	var err error
+	switch qb.GetOperation() {
+	case domain.OperationRead:
+		// check key conditions, without partition key the query is a scan
+		var query bool
+		if query, err = checkKeyConditions(keyConds, keys); err != nil {
+			return nil, err
+		}
+		if !query {
+			// check sort
+			if len(qb.GetSort()) > 0 {
+				return nil, fmt.Errorf("dynamodb scans cannot be sorted")
+			}
+			keyConds, conds = nil, qb.GetConditions()
+		}
+
+		// create key condition
+		if dq.KeyCondition, err = buildExpression(keyConds, ph); err != nil {
+			return nil, err
+		}
+
+		// create filter
+		if dq.Filter, err = buildExpression(conds, ph); err != nil {
+			return nil, err
+		}
+
+		// create scan direction
+		if dq.ScanIndexForward, err = buildScanIndexForward(qb.GetSort(), keys); err != nil {
+			return nil, err
+		}
+	case domain.OperationCreate:
+		// create item
+		if dq.Item, err = buildItem(qb.GetData()); err != nil {
+			return nil, err
+		}
+
+		// create condition
+		if dq.Condition, err = buildExpression(qb.GetConditions(), ph); err != nil {
+			return nil, err
+		}
+	case domain.OperationUpdate, domain.OperationDelete:
+		// create key
+		dq.Key = map[string]any{}
+		for _, cond := range keyConds {
+			if cond.Operator != domain.OperatorEqual {
+				return nil, fmt.Errorf("key attribute %s must be compared by equality", cond.Field.DB)
+			}
+			dq.Key[cond.Field.DB] = cond.Value
+		}
+
+		// check key is complete
+		if len(keys) == 0 || len(dq.Key) != len(keys) {
+			return nil, fmt.Errorf("dynamodb %s requires equality conditions on all key attributes", qb.GetOperation())
+		}
+
+		// create condition
+		if dq.Condition, err = buildExpression(conds, ph); err != nil {
+			return nil, err
+		}
+
+		// create update
+		if qb.GetOperation() == domain.OperationUpdate {
+			if dq.Update, err = buildUpdate(qb.GetData(), ph); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// check sort
+	if qb.GetOperation() != domain.OperationRead && len(qb.GetSort()) > 0 {
+		return nil, fmt.Errorf("sort is only supported by dynamodb read queries")
+	}
+
+	// create projection
+	if dq.Projection, err = buildProjection(qb.GetSelects(), ph); err != nil {
+		return nil, err
+	}
+
+	// set placeholders
+	if len(ph.names) > 0 {
+		dq.Names = ph.names
+	}
+	if len(ph.values) > 0 {
+		dq.Values = ph.values
+	}
+
+	// return query
+	return dq, nil
+}
+
+// checkKeyConditions checks that the conditions on key attributes form a valid
+// key condition expression: a single equality on the partition key and at most
+// one condition on the sort key, where LIKE must be a prefix match. It reports
+// false if there is no condition on the partition key.
+func checkKeyConditions(keyConds []domain.Condition, keys []string) (bool, error) {
+	// count conditions by key
+	partition, sort := 0, 0
+	for _, cond := range keyConds {
+		// check null
+		if isNull(cond.Value) {
+			return false, fmt.Errorf("key attribute %s cannot be compared with null", cond.Field.DB)
+		}
+
+		// partition key condition
+		if cond.Field.DB == keys[0] {
+			if cond.Operator != domain.OperatorEqual {
+				return false, fmt.Errorf("partition key attribute %s must be compared by equality", cond.Field.DB)
+			}
+			partition++
+			continue
+		}
+
+		// check sort key operator
+		if !dynamoKeyOperators[cond.Operator] {
+			return false, fmt.Errorf("unsupported key condition operator for attribute %s: %d", cond.Field.DB, cond.Operator)
+		}
+
+		// check prefix pattern
+		if cond.Operator == domain.OperatorLike {
+			pattern, _ := cond.Value.(string)
+			if fn, _, err := likeToFunction(pattern); err != nil || fn != "begins_with" {
+				return false, fmt.Errorf("sort key attribute %s only supports prefix like patterns", cond.Field.DB)
+			}
+		}
+		sort++
+	}
+
+	// scan without partition key
+	if partition == 0 {
+		return false, nil
+	}
+
+	// check single condition per key
+	if partition > 1 || sort > 1 {
+		return false, fmt.Errorf("dynamodb key conditions support a single condition per key attribute")
+	}
+
+	// valid key conditions
+	return true, nil
+}
+
+// buildScanIndexForward creates the scan direction from the sorts. Only a
+// single sort by the sort key, the second key attribute, is supported.
+func buildScanIndexForward(sorts []domain.Sort, keys []string) (*bool, error) {
+	// no sort
+	if len(sorts) == 0 {
+		return nil, nil
+	}
+
+	// check sort
+	if len(sorts) > 1 || len(keys) < 2 || sorts[0].Field.DB != keys[1] {
+		return nil, fmt.Errorf("dynamodb queries can only be sorted by the sort key")
+	}
+
+	// return direction
+	forward := sorts[0].Type != domain.SortDesc
+	return &forward, nil
+}
+
+// buildProjection creates a projection expression from the select fields.
+// Selecting all fields creates no projection.
+func buildProjection(fields []domain.Field, ph *placeholders) (string, error) {
+	// projection
+	var projection []string
+	for _, field := range fields {
+		// check aggregation
+		if field.Aggregation != domain.AggregationNone {
+			return "", fmt.Errorf("aggregation is not supported by dynamodb projections")
+		}
+
+		// all fields
+		if field.DB == "*" {
+			return "", nil
+		}
+
+		// add field
+		projection = append(projection, ph.name(field.DB))
+	}
+
+	// return projection
+	return strings.Join(projection, ", "), nil
+}
+
+// buildItem creates an item from the data.
+func buildItem(data []domain.Data) (map[string]any, error) {
+	// item
+	item := map[string]any{}
+	for _, d := range data {
+		// check modification
+		if _, ok := d.Value.(*domain.Modification); ok {
+			return nil, fmt.Errorf("modification is not supported by dynamodb items")
+		}
+
+		// add value
+		item[d.Field.DB] = toDynamoValue(d.Value)
+	}
+
+	// return item
+	return item, nil
+}
+
+// toDynamoValue converts domain.ValueNull to nil and returns other values as
+// they are.
+func toDynamoValue(value any) any {
+	if isNull(value) {
+		return nil
+	}
+	return value
+}
+
+// isNull reports whether the value is domain.ValueNull.
+func isNull(value any) bool {
+	v, ok := value.(domain.ValueType)
+	return ok && v == domain.ValueNull
+}
@@ -0,0 +1,29 @@
+package dynamobuilder
+
+import "github.com/tyrenix/qbr/domain"
+
+// dynamoOperators is a map that defines DynamoDB comparators for comparison OperatorTypes.
+var dynamoOperators = map[domain.OperatorType]string{
+	domain.OperatorEqual:              "=",
+	domain.OperatorNotEqual:           "<>",
+	domain.OperatorLessThan:           "<",
+	domain.OperatorGreaterThan:        ">",
+	domain.OperatorLessThanOrEqual:    "<=",
+	domain.OperatorGreaterThanOrEqual: ">=",
+}
+
+// dynamoKeyOperators is a set of OperatorTypes that are allowed in key condition expressions.
+var dynamoKeyOperators = map[domain.OperatorType]bool{
+	domain.OperatorEqual:              true,
+	domain.OperatorLessThan:           true,
+	domain.OperatorGreaterThan:        true,
+	domain.OperatorLessThanOrEqual:    true,
+	domain.OperatorGreaterThanOrEqual: true,
+	domain.OperatorLike:               true,
+}
+
+// dynamoModifications is a map that defines DynamoDB arithmetic operators for ModificationTypes.
+var dynamoModifications = map[domain.ModificationType]string{
+	domain.ModificationAdd:      "+",
+	domain.ModificationSubtract: "-",
+}
@@ -0,0 +1,148 @@
+package dynamobuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+// buildExpression creates a condition expression from the conditions joined by
+// AND. No conditions create an empty expression.
+func buildExpression(conds []domain.Condition, ph *placeholders) (string, error) {
+	// create parts
+	parts := make([]string, 0, len(conds))
+	for _, cond := range conds {
+		part, err := buildCondition(cond, ph)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, part)
+	}
+
+	// return expression
+	return strings.Join(parts, " AND "), nil
+}
+
+// buildCondition creates an expression from a single condition.
+func buildCondition(cond domain.Condition, ph *placeholders) (string, error) {
+	// logical operators
+	switch cond.Operator {
+	case domain.OperatorAnd, domain.OperatorOr, domain.OperatorNot:
+		// assert nested conditions
+		nested, ok := cond.Value.([]domain.Condition)
+		if !ok {
+			return "", fmt.Errorf("invalid value for logical operator %d", cond.Operator)
+		}
+
+		// create nested parts
+		parts := make([]string, 0, len(nested))
+		for _, c := range nested {
+			part, err := buildCondition(c, ph)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, part)
+		}
+
+		// join parts
+		switch cond.Operator {
+		case domain.OperatorOr:
+			return "(" + strings.Join(parts, " OR ") + ")", nil
+		case domain.OperatorNot:
+			return "NOT (" + strings.Join(parts, " AND ") + ")", nil
+		default:
+			return "(" + strings.Join(parts, " AND ") + ")", nil
+		}
+	}
+
+	// attribute
+	name := ph.name(cond.Field.DB)
+
+	// null checks
+	if isNull(cond.Value) {
+		switch cond.Operator {
+		case domain.OperatorEqual:
+			return fmt.Sprintf("attribute_not_exists(%s)", name), nil
+		case domain.OperatorNotEqual:
+			return fmt.Sprintf("attribute_exists(%s)", name), nil
+		}
+		return "", fmt.Errorf("unsupported null comparison for attribute %s", cond.Field.DB)
+	}
+
+	// select operator
+	switch cond.Operator {
+	case domain.OperatorIn:
+		// in values
+		values, ok := cond.Value.([]any)
+		if !ok {
+			values = []any{cond.Value}
+		}
+
+		// create value placeholders
+		phs := make([]string, len(values))
+		for i, v := range values {
+			phs[i] = ph.value(v)
+		}
+
+		// return in expression
+		return fmt.Sprintf("%s IN (%s)", name, strings.Join(phs, ", ")), nil
+	case domain.OperatorLike:
+		// get pattern
+		pattern, ok := cond.Value.(string)
+		if !ok {
+			return "", fmt.Errorf("invalid like pattern for attribute %s", cond.Field.DB)
+		}
+
+		// select function
+		fn, value, err := likeToFunction(pattern)
+		if err != nil {
+			return "", fmt.Errorf("attribute %s: %w", cond.Field.DB, err)
+		}
+
+		// return function expression
+		return fmt.Sprintf("%s(%s, %s)", fn, name, ph.value(value)), nil
+	}
+
+	// comparison operators
+	op, ok := dynamoOperators[cond.Operator]
+	if !ok {
+		return "", domain.ErrUnsupportedOperator{Op: cond.Operator}
+	}
+
+	// return comparison
+	return fmt.Sprintf("%s %s %s", name, op, ph.value(cond.Value)), nil
+}
+
+// likeToFunction converts a LIKE pattern into a DynamoDB function. A trailing
+// % becomes begins_with, and a pattern enclosed in % becomes contains. Other
+// patterns are not supported.
+func likeToFunction(pattern string) (string, string, error) {
+	// unescape pattern
+	var b strings.Builder
+	leading, trailing := false, false
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			b.WriteByte(pattern[i])
+		case c == '%' && i == 0:
+			leading = true
+		case c == '%' && i == len(pattern)-1:
+			trailing = true
+		case c == '%' || c == '_':
+			return "", "", fmt.Errorf("unsupported like pattern for dynamodb: %q", pattern)
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	// select function
+	switch {
+	case leading && trailing:
+		return "contains", b.String(), nil
+	case trailing:
+		return "begins_with", b.String(), nil
+	}
+	return "", "", fmt.Errorf("unsupported like pattern for dynamodb: %q", pattern)
+}
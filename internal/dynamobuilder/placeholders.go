@@ -0,0 +1,52 @@
+package dynamobuilder
+
+import (
+	"fmt"
+	"strings"
+)
+
+// placeholders generates expression attribute name and value placeholders.
+type placeholders struct {
+	names  map[string]string // placeholder to name
+	values map[string]any    // placeholder to value
+	byName map[string]string // name to placeholder
+}
+
+// newPlaceholders creates empty placeholders.
+func newPlaceholders() *placeholders {
+	return &placeholders{
+		names:  map[string]string{},
+		values: map[string]any{},
+		byName: map[string]string{},
+	}
+}
+
+// name returns the placeholder path of an attribute. Dotted attributes are
+// treated as document paths, each element getting its own placeholder.
+func (p *placeholders) name(attr string) string {
+	// path elements
+	parts := strings.Split(attr, ".")
+	for i, part := range parts {
+		// get existing placeholder
+		if ph, ok := p.byName[part]; ok {
+			parts[i] = ph
+			continue
+		}
+
+		// create placeholder
+		ph := fmt.Sprintf("#n%d", len(p.names))
+		p.names[ph] = part
+		p.byName[part] = ph
+		parts[i] = ph
+	}
+
+	// return path
+	return strings.Join(parts, ".")
+}
+
+// value returns a new placeholder for the value.
+func (p *placeholders) value(v any) string {
+	ph := fmt.Sprintf(":v%d", len(p.values))
+	p.values[ph] = v
+	return ph
+}
@@ -0,0 +1,60 @@
+package dynamobuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+// buildUpdate creates an update expression from the data. Values are set with
+// SET, additions and subtractions become SET x = y + :v, and null values
+// remove the attribute with REMOVE.
+func buildUpdate(data []domain.Data, ph *placeholders) (string, error) {
+	// actions
+	var set, remove []string
+
+	// create actions
+	for _, d := range data {
+		// attribute
+		name := ph.name(d.Field.DB)
+
+		// plain value
+		mod, ok := d.Value.(*domain.Modification)
+		if !ok {
+			if isNull(d.Value) {
+				remove = append(remove, name)
+				continue
+			}
+			set = append(set, fmt.Sprintf("%s = %s", name, ph.value(d.Value)))
+			continue
+		}
+
+		// get operator
+		op, ok := dynamoModifications[mod.Operator]
+		if !ok {
+			return "", fmt.Errorf("unsupported modification operator for dynamodb updates: %d", mod.Operator)
+		}
+
+		// source attribute
+		source := name
+		if mod.Field != nil {
+			source = ph.name(mod.Field.DB)
+		}
+
+		// add arithmetic action
+		set = append(set, fmt.Sprintf("%s = %s %s %s", name, source, op, ph.value(mod.Value)))
+	}
+
+	// create expression
+	var clauses []string
+	if len(set) > 0 {
+		clauses = append(clauses, "SET "+strings.Join(set, ", "))
+	}
+	if len(remove) > 0 {
+		clauses = append(clauses, "REMOVE "+strings.Join(remove, ", "))
+	}
+
+	// return expression
+	return strings.Join(clauses, " "), nil
+}
@@ -0,0 +1,88 @@
+package elasticbuilder
+
+import (
+	"fmt"
+
+	"github.com/tyrenix/qbr/domain"
+	"github.com/tyrenix/qbr/internal/pkg/unsupported"
+)
+
+// CreateSearchBody creates an Elasticsearch search request body from the
+// Query's data. It returns the body and an error if the query cannot be
+// expressed in the query DSL.
+func CreateSearchBody(qb Query) (map[string]any, error) {
+	// check operation
+	if qb.GetOperation() != domain.OperationRead {
+		return nil, fmt.Errorf("elasticsearch search body requires read operation")
+	}
+
+	// check unsupported features
+	if err := unsupported.Check(qb, "elasticsearch"); err != nil {
+		return nil, err
+	}
+	if qb.GetSuffix() != "" {
+		return nil, fmt.Errorf("suffix is not supported by elasticsearch queries")
+	}
+
+	// create query
+	query, err := buildBool(qb.GetConditions(), "must")
+	if err != nil {
+		return nil, err
+	}
+	body := map[string]any{"query": query}
+
+	// create source
+	source, err := buildSource(qb.GetSelects())
+	if err != nil {
+		return nil, err
+	}
+	if source != nil {
+		body["_source"] = source
+	}
+
+	// create sort
+	if sorts := qb.GetSort(); len(sorts) > 0 {
+		sort := make([]any, 0, len(sorts))
+		for _, s := range sorts {
+			sort = append(sort, map[string]any{
+				s.Field.DB: map[string]any{"order": elasticSortOrders[s.Type]},
+			})
+		}
+		body["sort"] = sort
+	}
+
+	// create pagination
+	if offset := qb.GetOffset(); offset > 0 {
+		body["from"] = offset
+	}
+	if limit := qb.GetLimit(); limit > 0 {
+		body["size"] = limit
+	}
+
+	// return body
+	return body, nil
+}
+
+// buildSource creates the _source field list from the select fields. Selecting
+// all fields creates no list.
+func buildSource(fields []domain.Field) ([]string, error) {
+	// source
+	var source []string
+	for _, field := range fields {
+		// check aggregation
+		if field.Aggregation != domain.AggregationNone {
+			return nil, fmt.Errorf("aggregation is not supported by elasticsearch _source")
+		}
+
+		// all fields
+		if field.DB == "*" {
+			return nil, nil
+		}
+
+		// add field
+		source = append(source, field.DB)
+	}
+
+	// return source
+	return source, nil
+}
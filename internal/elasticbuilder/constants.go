@@ -0,0 +1,17 @@
+package elasticbuilder
+
+import "github.com/tyrenix/qbr/domain"
+
+// elasticRangeOperators is a map that defines Elasticsearch range parameters for comparison OperatorTypes.
+var elasticRangeOperators = map[domain.OperatorType]string{
+	domain.OperatorLessThan:           "lt",
+	domain.OperatorGreaterThan:        "gt",
+	domain.OperatorLessThanOrEqual:    "lte",
+	domain.OperatorGreaterThanOrEqual: "gte",
+}
+
+// elasticSortOrders is a map that defines Elasticsearch sort orders for SortTypes.
+var elasticSortOrders = map[domain.SortType]string{
+	domain.SortAsc:  "asc",
+	domain.SortDesc: "desc",
+}
@@ -0,0 +1,171 @@
+package elasticbuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+// buildBool creates a query from the conditions joined by the given bool
+// occurrence type. No conditions create a match_all query, and a single
+// condition of a must clause is returned as it is.
+func buildBool(conds []domain.Condition, occur string) (map[string]any, error) {
+	// no conditions
+	if len(conds) == 0 {
+		if occur == "must_not" {
+			return map[string]any{"bool": map[string]any{"must_not": []any{matchAll()}}}, nil
+		}
+		return matchAll(), nil
+	}
+
+	// create clauses
+	clauses := make([]any, 0, len(conds))
+	for _, cond := range conds {
+		clause, err := buildCondition(cond)
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, clause)
+	}
+
+	// single must clause
+	if occur == "must" && len(clauses) == 1 {
+		return clauses[0].(map[string]any), nil
+	}
+
+	// create bool query
+	boolQuery := map[string]any{occur: clauses}
+	if occur == "should" {
+		boolQuery["minimum_should_match"] = 1
+	}
+
+	// return bool query
+	return map[string]any{"bool": boolQuery}, nil
+}
+
+// buildCondition creates a query from a single condition.
+func buildCondition(cond domain.Condition) (map[string]any, error) {
+	// logical operators
+	switch cond.Operator {
+	case domain.OperatorAnd, domain.OperatorOr, domain.OperatorNot:
+		// assert nested conditions
+		nested, ok := cond.Value.([]domain.Condition)
+		if !ok {
+			return nil, fmt.Errorf("invalid value for logical operator %d", cond.Operator)
+		}
+
+		// create bool query
+		switch cond.Operator {
+		case domain.OperatorOr:
+			return buildBool(nested, "should")
+		case domain.OperatorNot:
+			// not of several conditions negates their conjunction
+			if len(nested) > 1 {
+				inner, err := buildBool(nested, "must")
+				if err != nil {
+					return nil, err
+				}
+				return mustNot(inner), nil
+			}
+			return buildBool(nested, "must_not")
+		default:
+			return buildBool(nested, "must")
+		}
+	}
+
+	// field
+	field := cond.Field.DB
+
+	// null checks
+	if v, ok := cond.Value.(domain.ValueType); ok && v == domain.ValueNull {
+		exists := map[string]any{"exists": map[string]any{"field": field}}
+		switch cond.Operator {
+		case domain.OperatorEqual:
+			return mustNot(exists), nil
+		case domain.OperatorNotEqual:
+			return exists, nil
+		}
+		return nil, fmt.Errorf("unsupported null comparison for field %s", field)
+	}
+
+	// select operator
+	switch cond.Operator {
+	case domain.OperatorEqual:
+		return term(field, cond.Value), nil
+	case domain.OperatorNotEqual:
+		return mustNot(term(field, cond.Value)), nil
+	case domain.OperatorIn:
+		values, ok := cond.Value.([]any)
+		if !ok {
+			values = []any{cond.Value}
+		}
+		return map[string]any{"terms": map[string]any{field: values}}, nil
+	case domain.OperatorLike, domain.OperatorILike:
+		pattern, ok := cond.Value.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid like pattern for field %s", field)
+		}
+		wildcard := map[string]any{"value": likeToWildcard(pattern)}
+		if cond.Operator == domain.OperatorILike {
+			wildcard["case_insensitive"] = true
+		}
+		return map[string]any{"wildcard": map[string]any{field: wildcard}}, nil
+	}
+
+	// range operators
+	op, ok := elasticRangeOperators[cond.Operator]
+	if !ok {
+		return nil, domain.ErrUnsupportedOperator{Op: cond.Operator}
+	}
+
+	// return range query
+	return map[string]any{"range": map[string]any{field: map[string]any{op: cond.Value}}}, nil
+}
+
+// term creates a term query.
+func term(field string, value any) map[string]any {
+	return map[string]any{"term": map[string]any{field: value}}
+}
+
+// mustNot negates a query.
+func mustNot(query map[string]any) map[string]any {
+	return map[string]any{"bool": map[string]any{"must_not": []any{query}}}
+}
+
+// matchAll creates a match_all query.
+func matchAll() map[string]any {
+	return map[string]any{"match_all": map[string]any{}}
+}
+
+// likeToWildcard converts a LIKE pattern into a wildcard pattern. Backslash
+// escaped LIKE wildcards are matched literally, and wildcard characters of the
+// pattern itself are escaped.
+func likeToWildcard(pattern string) string {
+	// wildcard
+	var b strings.Builder
+
+	// convert pattern
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			if pattern[i] == '*' || pattern[i] == '?' || pattern[i] == '\\' {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(pattern[i])
+		case c == '%':
+			b.WriteByte('*')
+		case c == '_':
+			b.WriteByte('?')
+		case c == '*' || c == '?':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	// return wildcard
+	return b.String()
+}
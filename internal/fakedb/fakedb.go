@@ -0,0 +1,195 @@
+// Package fakedb provides a fake database/sql driver for tests. It records the
+// executed statements and returns results queued by the test, without parsing
+// SQL.
+package fakedb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+)
+
+// Statement is an executed statement.
+type Statement struct {
+	Query string
+	Args  []any
+}
+
+// Result is the result of a statement.
+type Result struct {
+	Columns      []string         // Columns of the returned rows.
+	Rows         [][]driver.Value // Returned rows.
+	RowsAffected int64            // Number of affected rows of Exec.
+	LastInsertId int64            // Last inserted id of Exec.
+	Err          error            // Error returned by the statement.
+}
+
+// Recorder records executed statements and returns queued results.
+type Recorder struct {
+	mu         sync.Mutex
+	statements []Statement
+	results    []Result
+}
+
+// Open creates a database handle backed by a new recorder.
+func Open() (*sql.DB, *Recorder) {
+	r := &Recorder{}
+	return sql.OpenDB(connector{r}), r
+}
+
+// Push queues results, returned by the next statements in order. Statements
+// without a queued result return no rows.
+func (r *Recorder) Push(results ...Result) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results = append(r.results, results...)
+}
+
+// Statements returns the executed statements.
+func (r *Recorder) Statements() []Statement {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Statement(nil), r.statements...)
+}
+
+// record records the statement and returns its result.
+func (r *Recorder) record(query string, args []driver.NamedValue) Result {
+	// lock recorder
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// record statement
+	values := make([]any, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	r.statements = append(r.statements, Statement{Query: query, Args: values})
+
+	// pop result
+	if len(r.results) == 0 {
+		return Result{}
+	}
+	res := r.results[0]
+	r.results = r.results[1:]
+	return res
+}
+
+// connector implements driver.Connector.
+type connector struct {
+	r *Recorder
+}
+
+// Connect implements driver.Connector.
+func (c connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{r: c.r}, nil
+}
+
+// Driver implements driver.Connector.
+func (c connector) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+// fakeDriver implements driver.Driver. Connections are created by the
+// connector only.
+type fakeDriver struct{}
+
+// Open implements driver.Driver.
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("fakedb: use fakedb.Open")
+}
+
+// conn implements driver.Conn with context aware Exec and Query.
+type conn struct {
+	r *Recorder
+}
+
+// Prepare implements driver.Conn. Prepared statements are not supported.
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("fakedb: prepared statements are not supported")
+}
+
+// Close implements driver.Conn.
+func (c *conn) Close() error {
+	return nil
+}
+
+// Begin implements driver.Conn.
+func (c *conn) Begin() (driver.Tx, error) {
+	return tx{}, nil
+}
+
+// ExecContext implements driver.ExecerContext.
+func (c *conn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	res := c.r.record(query, args)
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	return result{lastInsertId: res.LastInsertId, rowsAffected: res.RowsAffected}, nil
+}
+
+// QueryContext implements driver.QueryerContext.
+func (c *conn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	res := c.r.record(query, args)
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	return &rows{columns: res.Columns, values: res.Rows}, nil
+}
+
+// result implements driver.Result.
+type result struct {
+	lastInsertId int64
+	rowsAffected int64
+}
+
+// LastInsertId implements driver.Result.
+func (r result) LastInsertId() (int64, error) {
+	return r.lastInsertId, nil
+}
+
+// RowsAffected implements driver.Result.
+func (r result) RowsAffected() (int64, error) {
+	return r.rowsAffected, nil
+}
+
+// tx implements driver.Tx.
+type tx struct{}
+
+// Commit implements driver.Tx.
+func (tx) Commit() error {
+	return nil
+}
+
+// Rollback implements driver.Tx.
+func (tx) Rollback() error {
+	return nil
+}
+
+// rows implements driver.Rows.
+type rows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+// Columns implements driver.Rows.
+func (r *rows) Columns() []string {
+	return r.columns
+}
+
+// Close implements driver.Rows.
+func (r *rows) Close() error {
+	return nil
+}
+
+// Next implements driver.Rows.
+func (r *rows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
@@ -0,0 +1,119 @@
+package mongobuilder
+
+import (
+	"fmt"
+
+	"github.com/tyrenix/qbr/domain"
+	"github.com/tyrenix/qbr/internal/pkg/unsupported"
+)
+
+// CreateMongoQuery creates MongoDB documents from the Query's data. It returns
+// the documents and an error if the query cannot be expressed in MongoDB.
+func CreateMongoQuery(qb Query) (*domain.MongoQuery, error) {
+	// check unsupported features
+	if err := unsupported.Check(qb, "mongo"); err != nil {
+		return nil, err
+	}
+	if qb.GetSuffix() != "" {
+		return nil, fmt.Errorf("suffix is not supported by mongo queries")
+	}
+
+	// create query
+	mq := &domain.MongoQuery{
+		Limit: int64(qb.GetLimit()),
+		Skip:  int64(qb.GetOffset()),
+	}
+
+	// create filter
+	filter, err := buildFilter(qb.GetConditions())
+	if err != nil {
+		return nil, err
+	}
+	mq.Filter = filter
+
+	// create projection
+	projection, err := buildProjection(qb.GetSelects())
+	if err != nil {
+		return nil, err
+	}
+	mq.Projection = projection
+
+	// create sort
+	for _, sort := range qb.GetSort() {
+		value := 1
+		if sort.Type == domain.SortDesc {
+			value = -1
+		}
+		mq.Sort = append(mq.Sort, domain.MongoSort{Key: sort.Field.DB, Value: value})
+	}
+
+	// create documents
+	switch qb.GetOperation() {
+	case domain.OperationCreate:
+		mq.Document, err = buildDocument(qb.GetData())
+	case domain.OperationUpdate:
+		mq.Update, err = buildUpdate(qb.GetData())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// return query
+	return mq, nil
+}
+
+// buildProjection creates a projection from the select fields. Selecting all
+// fields creates no projection.
+func buildProjection(fields []domain.Field) (map[string]any, error) {
+	// projection
+	projection := map[string]any{}
+	for _, field := range fields {
+		// check aggregation
+		if field.Aggregation != domain.AggregationNone {
+			return nil, fmt.Errorf("aggregation is not supported by mongo projections")
+		}
+
+		// all fields
+		if field.DB == "*" {
+			return nil, nil
+		}
+
+		// add field
+		projection[field.DB] = 1
+	}
+
+	// no fields
+	if len(projection) == 0 {
+		return nil, nil
+	}
+
+	// return projection
+	return projection, nil
+}
+
+// buildDocument creates an insert document from the data.
+func buildDocument(data []domain.Data) (map[string]any, error) {
+	// document
+	doc := map[string]any{}
+	for _, d := range data {
+		// check modification
+		if _, ok := d.Value.(*domain.Modification); ok {
+			return nil, fmt.Errorf("modification is not supported by mongo inserts")
+		}
+
+		// add value
+		doc[d.Field.DB] = toMongoValue(d.Value)
+	}
+
+	// return document
+	return doc, nil
+}
+
+// toMongoValue converts domain.ValueNull to nil and returns other values as
+// they are.
+func toMongoValue(value any) any {
+	if v, ok := value.(domain.ValueType); ok && v == domain.ValueNull {
+		return nil
+	}
+	return value
+}
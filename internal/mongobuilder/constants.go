@@ -0,0 +1,22 @@
+package mongobuilder
+
+import "github.com/tyrenix/qbr/domain"
+
+// mongoOperators is a map that defines MongoDB query operators for different OperatorTypes.
+// LIKE operators and logical operators are rendered separately.
+var mongoOperators = map[domain.OperatorType]string{
+	domain.OperatorEqual:              "$eq",
+	domain.OperatorNotEqual:           "$ne",
+	domain.OperatorLessThan:           "$lt",
+	domain.OperatorGreaterThan:        "$gt",
+	domain.OperatorLessThanOrEqual:    "$lte",
+	domain.OperatorGreaterThanOrEqual: "$gte",
+	domain.OperatorIn:                 "$in",
+}
+
+// mongoBitOperators is a map that defines MongoDB $bit update operators for bitwise ModificationTypes.
+var mongoBitOperators = map[domain.ModificationType]string{
+	domain.ModificationBitwiseAnd: "and",
+	domain.ModificationBitwiseOr:  "or",
+	domain.ModificationBitwiseXor: "xor",
+}
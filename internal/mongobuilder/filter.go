@@ -0,0 +1,144 @@
+package mongobuilder
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+// buildFilter creates a filter document from the conditions. Conditions on
+// different fields are merged into a single document, otherwise they are
+// joined with $and.
+func buildFilter(conds []domain.Condition) (map[string]any, error) {
+	// create condition documents
+	docs := make([]map[string]any, 0, len(conds))
+	for _, cond := range conds {
+		doc, err := buildCondition(cond)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+
+	// merge documents
+	return mergeAnd(docs), nil
+}
+
+// buildCondition creates a filter document from a single condition.
+func buildCondition(cond domain.Condition) (map[string]any, error) {
+	switch cond.Operator {
+	case domain.OperatorAnd, domain.OperatorOr, domain.OperatorNot:
+		// assert nested conditions
+		nested, ok := cond.Value.([]domain.Condition)
+		if !ok {
+			return nil, fmt.Errorf("invalid value for logical operator %d", cond.Operator)
+		}
+
+		// create nested documents
+		docs := make([]any, 0, len(nested))
+		for _, c := range nested {
+			doc, err := buildCondition(c)
+			if err != nil {
+				return nil, err
+			}
+			docs = append(docs, doc)
+		}
+
+		// create logical document
+		switch cond.Operator {
+		case domain.OperatorOr:
+			return map[string]any{"$or": docs}, nil
+		case domain.OperatorNot:
+			if len(docs) == 1 {
+				return map[string]any{"$nor": docs}, nil
+			}
+			return map[string]any{"$nor": []any{map[string]any{"$and": docs}}}, nil
+		default:
+			return map[string]any{"$and": docs}, nil
+		}
+	case domain.OperatorLike, domain.OperatorILike:
+		// get pattern
+		pattern, ok := cond.Value.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid like pattern for field %s", cond.Field.DB)
+		}
+
+		// create regex document
+		regex := map[string]any{"$regex": likeToRegex(pattern)}
+		if cond.Operator == domain.OperatorILike {
+			regex["$options"] = "i"
+		}
+		return map[string]any{cond.Field.DB: regex}, nil
+	}
+
+	// get operator
+	op, ok := mongoOperators[cond.Operator]
+	if !ok {
+		return nil, domain.ErrUnsupportedOperator{Op: cond.Operator}
+	}
+
+	// in values
+	value := toMongoValue(cond.Value)
+	if cond.Operator == domain.OperatorIn {
+		if v, ok := value.([]any); ok {
+			value = v
+		}
+	}
+
+	// return condition document
+	return map[string]any{cond.Field.DB: map[string]any{op: value}}, nil
+}
+
+// mergeAnd merges documents with distinct keys into a single document, or
+// joins them with $and if keys collide.
+func mergeAnd(docs []map[string]any) map[string]any {
+	// merged document
+	merged := map[string]any{}
+	for _, doc := range docs {
+		for k, v := range doc {
+			// key collision
+			if _, ok := merged[k]; ok {
+				and := make([]any, len(docs))
+				for i, d := range docs {
+					and[i] = d
+				}
+				return map[string]any{"$and": and}
+			}
+
+			// add key
+			merged[k] = v
+		}
+	}
+
+	// return merged document
+	return merged
+}
+
+// likeToRegex converts a LIKE pattern into an anchored regular expression.
+// Backslash escaped wildcards are matched literally.
+func likeToRegex(pattern string) string {
+	// regex
+	var b strings.Builder
+	b.WriteByte('^')
+
+	// convert pattern
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		case c == '%':
+			b.WriteString(".*")
+		case c == '_':
+			b.WriteByte('.')
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	// return regex
+	b.WriteByte('$')
+	return b.String()
+}
@@ -0,0 +1,22 @@
+package mongobuilder
+
+import "github.com/tyrenix/qbr/domain"
+
+type Query interface {
+	GetOperation() domain.OperationType
+	GetSelects() []domain.Field
+	GetConditions() []domain.Condition
+	GetData() []domain.Data
+	GetSort() []domain.Sort
+	GetLimit() uint64
+	GetOffset() uint64
+	GetSuffix() string
+	IsReturningExplicit() bool
+	GetMaxAffected() uint64
+	IsLock() bool
+	IsFinal() bool
+	GetSample() float64
+	GetPrewhere() []domain.Condition
+	GetLimitBy() (uint64, []domain.Field)
+	IsMutation() bool
+}
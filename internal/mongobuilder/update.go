@@ -0,0 +1,94 @@
+package mongobuilder
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+// buildUpdate creates an update document from the data. Plain values are set
+// with $set, additions and subtractions with $inc, multiplications and float
+// divisions with $mul, and bitwise modifications with $bit.
+func buildUpdate(data []domain.Data) (map[string]any, error) {
+	// update operators
+	update := map[string]any{}
+
+	// add sets a field of an update operator
+	add := func(op, field string, value any) {
+		doc, ok := update[op].(map[string]any)
+		if !ok {
+			doc = map[string]any{}
+			update[op] = doc
+		}
+		doc[field] = value
+	}
+
+	// create update
+	for _, d := range data {
+		// plain value
+		mod, ok := d.Value.(*domain.Modification)
+		if !ok {
+			add("$set", d.Field.DB, toMongoValue(d.Value))
+			continue
+		}
+
+		// check modification field
+		if mod.Field == nil || mod.Field.DB != d.Field.DB {
+			return nil, fmt.Errorf("modification of field %s from another field is not supported by mongo updates", d.Field.DB)
+		}
+
+		// select modification
+		switch mod.Operator {
+		case domain.ModificationAdd:
+			add("$inc", d.Field.DB, mod.Value)
+		case domain.ModificationSubtract:
+			v, err := negate(mod.Value)
+			if err != nil {
+				return nil, err
+			}
+			add("$inc", d.Field.DB, v)
+		case domain.ModificationMultiply:
+			add("$mul", d.Field.DB, mod.Value)
+		case domain.ModificationDivide:
+			v, err := reciprocal(mod.Value)
+			if err != nil {
+				return nil, err
+			}
+			add("$mul", d.Field.DB, v)
+		case domain.ModificationBitwiseAnd, domain.ModificationBitwiseOr, domain.ModificationBitwiseXor:
+			add("$bit", d.Field.DB, map[string]any{mongoBitOperators[mod.Operator]: mod.Value})
+		default:
+			return nil, fmt.Errorf("unsupported modification operator for mongo updates: %d", mod.Operator)
+		}
+	}
+
+	// return update
+	return update, nil
+}
+
+// negate returns the negated numeric value.
+func negate(value any) (any, error) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return reflect.ValueOf(-v.Int()).Convert(v.Type()).Interface(), nil
+	case reflect.Float32, reflect.Float64:
+		return reflect.ValueOf(-v.Float()).Convert(v.Type()).Interface(), nil
+	}
+	return nil, fmt.Errorf("unsupported subtraction value type for mongo updates: %T", value)
+}
+
+// reciprocal returns the reciprocal of a float value. Integer division cannot
+// be expressed with $mul.
+func reciprocal(value any) (any, error) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		if v.Float() == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return 1 / v.Float(), nil
+	}
+	return nil, fmt.Errorf("unsupported division value type for mongo updates: %T", value)
+}
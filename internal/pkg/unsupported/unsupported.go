@@ -0,0 +1,55 @@
+package unsupported
+
+import (
+	"fmt"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+// Query is the query data checked for features that builders of non-SQL
+// targets cannot express.
+type Query interface {
+	IsReturningExplicit() bool
+	GetMaxAffected() uint64
+	IsLock() bool
+	IsFinal() bool
+	GetSample() float64
+	GetPrewhere() []domain.Condition
+	GetLimitBy() (uint64, []domain.Field)
+	IsMutation() bool
+}
+
+// Check returns an error naming the first feature of the query that is not
+// supported by the target, such as "mongo". Locks, explicit returning fields
+// and MaxAffected are SQL features and FINAL, SAMPLE, PREWHERE, LIMIT BY and
+// mutations are ClickHouse features, so a query using them is rejected
+// instead of silently built without them.
+func Check(qb Query, target string) error {
+	// check sql features
+	switch {
+	case qb.IsLock():
+		return fmt.Errorf("lock is not supported by %s queries", target)
+	case qb.IsReturningExplicit():
+		return fmt.Errorf("returning is not supported by %s queries", target)
+	case qb.GetMaxAffected() > 0:
+		return fmt.Errorf("max affected is not supported by %s queries", target)
+	}
+
+	// check clickhouse features
+	limitBy, limitByFields := qb.GetLimitBy()
+	switch {
+	case qb.IsFinal():
+		return fmt.Errorf("final is not supported by %s queries", target)
+	case qb.GetSample() != 0:
+		return fmt.Errorf("sample is not supported by %s queries", target)
+	case len(qb.GetPrewhere()) > 0:
+		return fmt.Errorf("prewhere is not supported by %s queries", target)
+	case limitBy > 0 || len(limitByFields) > 0:
+		return fmt.Errorf("limit by is not supported by %s queries", target)
+	case qb.IsMutation():
+		return fmt.Errorf("mutation is not supported by %s queries", target)
+	}
+
+	// return success
+	return nil
+}
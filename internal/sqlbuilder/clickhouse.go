@@ -0,0 +1,102 @@
+package sqlbuilder
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+// checkClickHouse returns an error if the given ClickHouse specific feature is
+// used with another dialect, including dialect-neutral queries.
+func checkClickHouse(feature string, dialect domain.SqlDialect) error {
+	if dialect == "" {
+		return fmt.Errorf("%s requires sql dialect: %s", feature, domain.SqlClickHouse)
+	}
+	if dialect != domain.SqlClickHouse {
+		return fmt.Errorf("%s is not supported by sql dialect: %s", feature, dialect)
+	}
+	return nil
+}
+
+// buildClickHouseFrom creates the FINAL, SAMPLE and PREWHERE clauses that
+// follow the table of a select query, and appends the PREWHERE parameters to
+// params. It returns the clauses string.
+func buildClickHouseFrom(qb Query, placeholder domain.SqlPlaceholder, dialect domain.SqlDialect, params []any) (string, []any, error) {
+	// clauses
+	var clauses []string
+
+	// add final
+	if qb.IsFinal() {
+		if err := checkClickHouse("final", dialect); err != nil {
+			return "", nil, err
+		}
+		clauses = append(clauses, "FINAL")
+	}
+
+	// add sample
+	if sample := qb.GetSample(); sample > 0 {
+		if err := checkClickHouse("sample", dialect); err != nil {
+			return "", nil, err
+		}
+		clauses = append(clauses, "SAMPLE "+strconv.FormatFloat(sample, 'g', -1, 64))
+	}
+
+	// add prewhere
+	if prewhere := qb.GetPrewhere(); len(prewhere) > 0 {
+		if err := checkClickHouse("prewhere", dialect); err != nil {
+			return "", nil, err
+		}
+
+		// create conditions
+		cond, condParams, err := buildConditions(prewhere, placeholder, dialect, params)
+		if err != nil {
+			return "", nil, err
+		}
+
+		// add conditions
+		clauses = append(clauses, "PREWHERE "+cond)
+		params = condParams
+	}
+
+	// return clauses
+	return strings.Join(clauses, " "), params, nil
+}
+
+// buildLimitBy creates the LIMIT n BY clause of a select query. It returns the
+// clause string.
+func buildLimitBy(qb Query, dialect domain.SqlDialect) (string, error) {
+	// get limit by
+	n, fields := qb.GetLimitBy()
+	if n == 0 {
+		return "", nil
+	}
+
+	// check dialect
+	if err := checkClickHouse("limit by", dialect); err != nil {
+		return "", err
+	}
+
+	// create fields
+	names := make([]string, len(fields))
+	for i := range fields {
+		names[i] = getFieldName(&fields[i])
+	}
+
+	// return clause
+	return fmt.Sprintf("LIMIT %d BY %s", n, strings.Join(names, ", ")), nil
+}
+
+// checkAggregations returns an error if the fields use ClickHouse specific
+// aggregations with another dialect.
+func checkAggregations(fields []domain.Field, dialect domain.SqlDialect) error {
+	for _, field := range fields {
+		if sqlClickHouseAggregations[field.Aggregation] {
+			if err := checkClickHouse("aggregation", dialect); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
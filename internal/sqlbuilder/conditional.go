@@ -8,10 +8,11 @@ import (
 )
 
 // buildConditions translates a condition slice to a SQL query string and its params.
-// plc is the placeholder character to use, and params is the parameter slice to append to.
-// join is the operator to use to join the condition strings, default is "AND".
-// It returns the query string, the updated parameter slice, and an error if any.
-func buildConditions(conds []domain.Condition, plc domain.SqlPlaceholder, params []any, join ...string) (string, []any, error) {
+// plc is the placeholder character to use, dialect is the target SQL dialect, and params
+// is the parameter slice to append to. join is the operator to use to join the condition
+// strings, default is "AND". It returns the query string, the updated parameter slice,
+// and an error if any.
+func buildConditions(conds []domain.Condition, plc domain.SqlPlaceholder, dialect domain.SqlDialect, params []any, join ...string) (string, []any, error) {
 	// check check conditions count
 	if len(conds) == 0 {
 		return "", nil, nil
@@ -29,20 +30,24 @@ func buildConditions(conds []domain.Condition, plc domain.SqlPlaceholder, params
 	// condition join
 	for _, cond := range conds {
 		switch cond.Operator {
-		case domain.OperatorAnd, domain.OperatorOr: // for logical operator: OR, AND
+		case domain.OperatorAnd, domain.OperatorOr, domain.OperatorNot: // for logical operator: OR, AND, NOT
 			// create sub query and params
-			subQuery, subParams, err := handleLogicalCondition(cond, params, plc, cond.Operator)
+			subQuery, subParams, err := handleLogicalCondition(cond, params, plc, dialect, cond.Operator)
 			if err != nil {
 				return "", nil, err
 			}
 
 			// add sub query
-			condStrs = append(condStrs, fmt.Sprintf("(%s)", subQuery))
+			if cond.Operator == domain.OperatorNot {
+				condStrs = append(condStrs, fmt.Sprintf("NOT (%s)", subQuery))
+			} else {
+				condStrs = append(condStrs, fmt.Sprintf("(%s)", subQuery))
+			}
 			// add sub params
 			params = subParams
 		default: // for simple operator, >, <, <=, and so on
 			// create condition
-			conditionStr, subParams, err := handleSimpleCondition(cond, params, plc)
+			conditionStr, subParams, err := handleSimpleCondition(cond, params, plc, dialect)
 			if err != nil {
 				return "", nil, err
 			}
@@ -68,17 +73,17 @@ func buildConditions(conds []domain.Condition, plc domain.SqlPlaceholder, params
 	return query, params, nil
 }
 
-// handleLogicalCondition processes a logical condition (AND/OR) within a query,
+// handleLogicalCondition processes a logical condition (AND/OR/NOT) within a query,
 // generating a SQL sub-query and its corresponding parameters.
 //
 // It takes a Condition object representing the logical condition, a slice of
-// current parameter values, a placeholder for SQL parameter substitution, and
-// the logical operator type (AND/OR). The function validates the condition's
+// current parameter values, a placeholder for SQL parameter substitution, the
+// target SQL dialect and the logical operator type (AND/OR/NOT). The function validates the condition's
 // value as a slice of sub-conditions, then recursively builds SQL sub-queries
 // for each condition within the logical group. The resulting SQL string and
 // updated parameter list are returned, along with an error if any occurs
 // during the process.
-func handleLogicalCondition(cond domain.Condition, params []any, plc domain.SqlPlaceholder, lgOp domain.OperatorType) (string, []any, error) {
+func handleLogicalCondition(cond domain.Condition, params []any, plc domain.SqlPlaceholder, dialect domain.SqlDialect, lgOp domain.OperatorType) (string, []any, error) {
 	// assert type
 	value, ok := cond.Value.([]domain.Condition)
 	if !ok {
@@ -95,7 +100,7 @@ func handleLogicalCondition(cond domain.Condition, params []any, plc domain.SqlP
 	}
 
 	// create sub query
-	subQuery, subParams, err := buildConditions(value, plc, params, subJoin)
+	subQuery, subParams, err := buildConditions(value, plc, dialect, params, subJoin)
 	if err != nil {
 		return "", nil, err
 	}
@@ -112,13 +117,14 @@ func handleLogicalCondition(cond domain.Condition, params []any, plc domain.SqlP
 // handleSimpleCondition processes a simple condition within a SQL query, generating a SQL condition string
 // and its corresponding parameter.
 //
-// It takes a Condition object, a domain.SqlPlaceholder for parameter substitution, and a parameter index.
-// The function checks if the condition's value is of type ValueType and handles null values accordingly.
-// It retrieves the SQL operator for the given condition's operator, and constructs the SQL condition string
-// with the placeholder. If the value type or operator is not supported, it returns an error.
+// It takes a Condition object, a domain.SqlPlaceholder for parameter substitution, and the target SQL
+// dialect. The function checks if the condition's value is of type ValueType and handles null values
+// accordingly. It retrieves the SQL operator for the given condition's operator, and constructs the SQL
+// condition string with the placeholder. LIKE and ILIKE are built by buildLike. If the value type
+// or operator is not supported, it returns an error.
 //
 // The function returns the SQL condition string, the condition's value as a parameter, and an error if any.
-func handleSimpleCondition(cond domain.Condition, params []any, plc domain.SqlPlaceholder) (string, []any, error) {
+func handleSimpleCondition(cond domain.Condition, params []any, plc domain.SqlPlaceholder, dialect domain.SqlDialect) (string, []any, error) {
 	// check if the value type is ValueType
 	if v, ok := cond.Value.(domain.ValueType); ok {
 		if v == domain.ValueNull {
@@ -138,7 +144,7 @@ func handleSimpleCondition(cond domain.Condition, params []any, plc domain.SqlPl
 	// get SQL operator
 	operator := getSqlOperator(cond.Operator)
 	if operator == "" {
-		return "", nil, fmt.Errorf("unsupported operator: %d", cond.Operator)
+		return "", nil, domain.ErrUnsupportedOperator{Op: cond.Operator}
 	}
 
 	// create value
@@ -162,8 +168,43 @@ func handleSimpleCondition(cond domain.Condition, params []any, plc domain.SqlPl
 	}
 
 	// create condition string with placeholder
-	condStr := fmt.Sprintf("%s %s %s", getFieldName(cond.Field), operator, val)
+	var condStr string
+	if cond.Operator == domain.OperatorLike || cond.Operator == domain.OperatorILike {
+		condStr = buildLike(getFieldName(cond.Field), val, cond.Operator == domain.OperatorILike, dialect)
+	} else {
+		condStr = fmt.Sprintf("%s %s %s", getFieldName(cond.Field), operator, val)
+	}
 
 	// return condition string, value and success
 	return condStr, params, nil
 }
+
+// buildLike creates a LIKE or, if insensitive is set, a case-insensitive LIKE condition for the
+// given SQL dialect. PostgreSQL supports ILIKE, ClickHouse has the ilike function, and other
+// dialects compare lowercased values with LIKE.
+//
+// Wildcards are escaped with a backslash, see qbr.EscapeLike. Known dialects without the backslash
+// as default LIKE escape character get an explicit ESCAPE clause; without a dialect the condition
+// is left as is.
+func buildLike(field, val string, insensitive bool, dialect domain.SqlDialect) string {
+	// create condition
+	var cond string
+	switch {
+	case !insensitive:
+		cond = fmt.Sprintf("%s LIKE %s", field, val)
+	case dialect == "" || dialect == domain.SqlPostgres:
+		cond = fmt.Sprintf("%s ILIKE %s", field, val)
+	case dialect == domain.SqlClickHouse:
+		cond = fmt.Sprintf("ilike(%s, %s)", field, val)
+	default:
+		cond = fmt.Sprintf("LOWER(%s) LIKE LOWER(%s)", field, val)
+	}
+
+	// default escape character
+	if !sqlDialectsWithLikeEscape[dialect] {
+		return cond
+	}
+
+	// return condition with escape character
+	return cond + ` ESCAPE '\'`
+}
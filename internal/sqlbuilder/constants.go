@@ -5,9 +5,23 @@ import "github.com/tyrenix/qbr/domain"
 // sqlAggregationFormats is a map that defines SQL aggregation formats for different AggregationTypes.
 // It currently supports all supported aggregation types.
 var sqlAggregationFormats = map[domain.AggregationType]string{
-	domain.AggregationNone:  "%s",
-	domain.AggregationCount: "COUNT(%s)",
-	domain.AggregationSum:   "SUM(%s)",
+	domain.AggregationNone:     "%s",
+	domain.AggregationCount:    "COUNT(%s)",
+	domain.AggregationSum:      "SUM(%s)",
+	domain.AggregationUniq:     "uniq(%s)",
+	domain.AggregationQuantile: "quantile(%s)",
+}
+
+// sqlParametricAggregationFormats is a map that defines SQL formats for aggregation types
+// with a level parameter, used when the field's Level is set.
+var sqlParametricAggregationFormats = map[domain.AggregationType]string{
+	domain.AggregationQuantile: "quantile(%s)(%s)",
+}
+
+// sqlClickHouseAggregations is a set of AggregationTypes that are only supported by ClickHouse.
+var sqlClickHouseAggregations = map[domain.AggregationType]bool{
+	domain.AggregationUniq:     true,
+	domain.AggregationQuantile: true,
 }
 
 // sqlOperators is a map that defines SQL operators for different OperatorTypes.
@@ -22,6 +36,9 @@ var sqlOperators = map[domain.OperatorType]string{
 	domain.OperatorLessThanOrEqual:    "<=",
 	domain.OperatorGreaterThanOrEqual: ">=",
 	domain.OperatorIn:                 "IN",
+	domain.OperatorLike:               "LIKE",
+	domain.OperatorILike:              "ILIKE",
+	domain.OperatorNot:                "NOT",
 }
 
 // sqlModifications is a map that defines SQL modifications for different ModificationTypes.
@@ -37,3 +54,28 @@ var sqlModifications = map[domain.ModificationType]string{
 	domain.ModificationShiftLeft:  "<<",
 	domain.ModificationShiftRight: ">>",
 }
+
+// sqlDialectPlaceholders is a map that defines SQL placeholders for different SqlDialects.
+// It currently supports all supported dialects.
+var sqlDialectPlaceholders = map[domain.SqlDialect]domain.SqlPlaceholder{
+	domain.SqlPostgres:   domain.SqlDollar,
+	domain.SqlMySQL:      domain.SqlQuestion,
+	domain.SqlSQLite:     domain.SqlQuestion,
+	domain.SqlSQLServer:  domain.SqlAtP,
+	domain.SqlClickHouse: domain.SqlQuestion,
+}
+
+// sqlDialectsWithoutReturning is a set of SqlDialects that cannot return the
+// affected rows of a query, neither by RETURNING nor by OUTPUT.
+var sqlDialectsWithoutReturning = map[domain.SqlDialect]bool{
+	domain.SqlMySQL:      true,
+	domain.SqlClickHouse: true,
+}
+
+// sqlDialectsWithLikeEscape is a set of SqlDialects that need an explicit
+// ESCAPE clause to use the backslash as LIKE escape character.
+var sqlDialectsWithLikeEscape = map[domain.SqlDialect]bool{
+	domain.SqlPostgres:  true,
+	domain.SqlSQLite:    true,
+	domain.SqlSQLServer: true,
+}
@@ -8,19 +8,38 @@ import (
 
 // CreateDeleteSql creates a SQL DELETE query from the Query's data. It returns the query string,
 // the parameters for the query, and an error if the query could not be built.
-func CreateDeleteSql(qb Query, table string, placeholder domain.SqlPlaceholder) (string, []any, error) {
+func CreateDeleteSql(qb Query, table string, placeholder domain.SqlPlaceholder, dialect domain.SqlDialect) (string, []any, error) {
 	var params []any
 
 	// create base query
 	query := fmt.Sprintf("DELETE FROM %s", table)
 
+	// create clickhouse mutation
+	if qb.IsMutation() {
+		if err := checkClickHouse("mutation", dialect); err != nil {
+			return "", nil, err
+		}
+		query = fmt.Sprintf("ALTER TABLE %s DELETE", table)
+	}
+
 	// conditionals
 	conds := qb.GetConditions()
 
+	// create returning fields
+	returning, output, err := buildReturning(qb, dialect, "DELETED")
+	if err != nil {
+		return "", nil, err
+	}
+
+	// add output fields
+	if output != "" {
+		query += " " + output
+	}
+
 	// if exists conditions add to query
 	if len(conds) > 0 {
 		// create conditions
-		conds, condsParams, err := buildConditions(conds, placeholder, nil)
+		conds, condsParams, err := buildConditions(conds, placeholder, dialect, nil)
 		if err != nil {
 			return "", nil, err
 		}
@@ -29,12 +48,23 @@ func CreateDeleteSql(qb Query, table string, placeholder domain.SqlPlaceholder)
 		query += " WHERE " + conds
 		// add condition params to params
 		params = append(params, condsParams...)
+	} else if dialect == domain.SqlClickHouse {
+		// clickhouse requires where clause
+		query += " WHERE 1"
 	}
 
-	// build returning fields
-	if len(conds) > 0 {
-		// create returning fields
-		query += " RETURNING " + buildSelects(qb.GetSelects())
+	// add returning fields
+	if returning != "" {
+		query += " " + returning
+	}
+
+	// add max affected rows limit
+	maxAffected, err := buildMaxAffected(qb.GetMaxAffected(), dialect)
+	if err != nil {
+		return "", nil, err
+	}
+	if maxAffected != "" {
+		query += " " + maxAffected
 	}
 
 	// add suffix
@@ -9,13 +9,11 @@ import (
 
 // CreateInsertSql creates a SQL INSERT query from the Query's data. It returns the query string,
 // the parameters for the query, and an error if the query could not be built.
-func CreateInsertSql(qb Query, table string, placeholder domain.SqlPlaceholder) (string, []any, error) {
+func CreateInsertSql(qb Query, table string, placeholder domain.SqlPlaceholder, dialect domain.SqlDialect) (string, []any, error) {
 	var columns []string
 	var values []string
 	var params []any
 
-	// select fields
-	selects := qb.GetSelects()
 	// data
 	setData := qb.GetData()
 
@@ -35,18 +33,30 @@ func CreateInsertSql(qb Query, table string, placeholder domain.SqlPlaceholder)
 		params = append(params, value)
 	}
 
+	// create returning fields
+	returning, output, err := buildReturning(qb, dialect, "INSERTED")
+	if err != nil {
+		return "", nil, err
+	}
+
 	// create query
 	query := fmt.Sprintf(
-		"INSERT INTO %s (%s) VALUES (%s)",
+		"INSERT INTO %s (%s)",
 		table,
 		strings.Join(columns, ", "),
-		strings.Join(values, ", "),
 	)
 
-	// build returning fields
-	if len(selects) > 0 {
-		// create returning fields
-		query += " RETURNING " + buildSelects(selects)
+	// add output fields
+	if output != "" {
+		query += " " + output
+	}
+
+	// add values
+	query += fmt.Sprintf(" VALUES (%s)", strings.Join(values, ", "))
+
+	// add returning fields
+	if returning != "" {
+		query += " " + returning
 	}
 
 	// add suffix
@@ -0,0 +1,221 @@
+package sqlbuilder
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+// Interpolate replaces the placeholders of the query with the given parameters
+// rendered as SQL literals of the dialect. Placeholders inside quoted strings
+// and identifiers are left untouched.
+//
+// The result is intended for logs and debugging only and must never be
+// executed, since literal rendering is not a substitute for parameter binding.
+func Interpolate(query string, params []any, placeholder domain.SqlPlaceholder, dialect domain.SqlDialect) (string, error) {
+	// result query
+	var b strings.Builder
+
+	// next sequential parameter
+	next := 0
+
+	// scan query
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			// copy quoted string
+			end := strings.IndexByte(query[i+1:], c)
+			if end < 0 {
+				b.WriteString(query[i:])
+				return b.String(), nil
+			}
+			b.WriteString(query[i : i+end+2])
+			i += end + 1
+		case strings.HasPrefix(query[i:], string(placeholder)):
+			// get parameter index
+			index := next
+			length := len(placeholder)
+			if placeholder == domain.SqlDollar || placeholder == domain.SqlAtP {
+				// parse placeholder number
+				j := i + length
+				for j < len(query) && query[j] >= '0' && query[j] <= '9' {
+					j++
+				}
+				n, err := strconv.Atoi(query[i+length : j])
+				if err != nil {
+					b.WriteByte(c)
+					continue
+				}
+				index, length = n-1, j-i
+			}
+
+			// check parameter exists
+			if index < 0 || index >= len(params) {
+				return "", fmt.Errorf("missing parameter for placeholder %s", query[i:i+length])
+			}
+
+			// render literal
+			lit, err := formatLiteral(params[index], dialect)
+			if err != nil {
+				return "", err
+			}
+
+			// add literal
+			b.WriteString(lit)
+			i += length - 1
+			next++
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	// return query
+	return b.String(), nil
+}
+
+// formatLiteral renders the value as a SQL literal of the dialect.
+//
+// Strings are quoted and escaped, times are formatted as timestamps, nil as
+// NULL and byte slices as hex literals. Byte slices that hold a JSON object or
+// array, as produced for struct values written to the database, are rendered
+// as quoted JSON strings.
+func formatLiteral(value any, dialect domain.SqlDialect) (string, error) {
+	// resolve driver values
+	if v, ok := value.(driver.Valuer); ok {
+		// check is nil pointer
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			return "NULL", nil
+		}
+
+		// get driver value
+		dv, err := v.Value()
+		if err != nil {
+			return "", err
+		}
+		value = dv
+	}
+
+	// select value type
+	switch v := value.(type) {
+	case nil:
+		return "NULL", nil
+	case string:
+		return quoteString(v, dialect), nil
+	case []byte:
+		// json string
+		if isJSON(v) {
+			return quoteString(string(v), dialect), nil
+		}
+
+		// hex literal
+		return formatBytes(v, dialect), nil
+	case bool:
+		// sql server has no boolean literals
+		if dialect == domain.SqlSQLServer {
+			if v {
+				return "1", nil
+			}
+			return "0", nil
+		}
+
+		// boolean literal
+		if v {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case time.Time:
+		return quoteString(formatTime(v, dialect), dialect), nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(v), nil
+	}
+
+	// dereference pointers
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return "NULL", nil
+		}
+		return formatLiteral(rv.Elem().Interface(), dialect)
+	}
+
+	// render numeric kinds of named types
+	switch rv.Kind() {
+	case reflect.Bool:
+		return formatLiteral(rv.Bool(), dialect)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'g', -1, 64), nil
+	case reflect.String:
+		return quoteString(rv.String(), dialect), nil
+	}
+
+	// render other values as quoted strings
+	return quoteString(fmt.Sprint(value), dialect), nil
+}
+
+// quoteString quotes the string as a SQL string literal of the dialect.
+func quoteString(s string, dialect domain.SqlDialect) string {
+	// escape backslashes for mysql and clickhouse
+	if dialect == domain.SqlMySQL || dialect == domain.SqlClickHouse {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+	}
+
+	// escape quotes
+	s = strings.ReplaceAll(s, "'", "''")
+
+	// sql server unicode string
+	if dialect == domain.SqlSQLServer {
+		return "N'" + s + "'"
+	}
+
+	// return quoted string
+	return "'" + s + "'"
+}
+
+// formatBytes renders the bytes as a hex literal of the dialect.
+func formatBytes(v []byte, dialect domain.SqlDialect) string {
+	switch dialect {
+	case domain.SqlPostgres:
+		return `'\x` + hex.EncodeToString(v) + `'`
+	case domain.SqlSQLServer:
+		return "0x" + strings.ToUpper(hex.EncodeToString(v))
+	default:
+		return "X'" + strings.ToUpper(hex.EncodeToString(v)) + "'"
+	}
+}
+
+// formatTime formats the time as a timestamp string of the dialect. Postgres
+// timestamps keep the time zone offset, other dialects use UTC.
+func formatTime(t time.Time, dialect domain.SqlDialect) string {
+	// postgres timestamp with time zone
+	if dialect == domain.SqlPostgres {
+		return t.Format("2006-01-02 15:04:05.999999-07:00")
+	}
+
+	// utc timestamp
+	return t.UTC().Format("2006-01-02 15:04:05.999999")
+}
+
+// isJSON reports whether the bytes hold a JSON object or array.
+func isJSON(v []byte) bool {
+	// get first non space byte
+	s := strings.TrimSpace(string(v))
+	if s == "" || (s[0] != '{' && s[0] != '[') {
+		return false
+	}
+
+	// check json
+	return json.Valid(v)
+}
@@ -0,0 +1,160 @@
+package sqlbuilder
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+func TestFormatLiteral(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 600000000, time.FixedZone("", 3600))
+	var nilTime *time.Time
+	type status string
+
+	tests := []struct {
+		name    string
+		value   any
+		dialect domain.SqlDialect
+		want    string
+	}{
+		// strings
+		{name: "postgres quote", value: `it's a \ path`, dialect: domain.SqlPostgres, want: `'it''s a \ path'`},
+		{name: "mysql quote", value: `it's a \ path`, dialect: domain.SqlMySQL, want: `'it''s a \\ path'`},
+		{name: "sqlite quote", value: `it's a \ path`, dialect: domain.SqlSQLite, want: `'it''s a \ path'`},
+		{name: "sqlserver quote", value: `it's a \ path`, dialect: domain.SqlSQLServer, want: `N'it''s a \ path'`},
+		{name: "named string", value: status("o'k"), dialect: domain.SqlPostgres, want: `'o''k'`},
+
+		// null
+		{name: "nil", value: nil, dialect: domain.SqlPostgres, want: "NULL"},
+		{name: "nil pointer", value: nilTime, dialect: domain.SqlMySQL, want: "NULL"},
+		{name: "null valuer", value: sql.NullString{}, dialect: domain.SqlSQLite, want: "NULL"},
+		{name: "valid valuer", value: sql.NullString{String: "a", Valid: true}, dialect: domain.SqlSQLite, want: "'a'"},
+
+		// times
+		{name: "postgres time", value: at, dialect: domain.SqlPostgres, want: "'2024-01-02 03:04:05.6+01:00'"},
+		{name: "mysql time", value: at, dialect: domain.SqlMySQL, want: "'2024-01-02 02:04:05.6'"},
+		{name: "sqlserver time pointer", value: &at, dialect: domain.SqlSQLServer, want: "N'2024-01-02 02:04:05.6'"},
+
+		// bytes
+		{name: "postgres bytes", value: []byte{0xde, 0xad}, dialect: domain.SqlPostgres, want: `'\xdead'`},
+		{name: "mysql bytes", value: []byte{0xde, 0xad}, dialect: domain.SqlMySQL, want: "X'DEAD'"},
+		{name: "sqlite bytes", value: []byte{0xde, 0xad}, dialect: domain.SqlSQLite, want: "X'DEAD'"},
+		{name: "sqlserver bytes", value: []byte{0xde, 0xad}, dialect: domain.SqlSQLServer, want: "0xDEAD"},
+		{name: "json bytes", value: []byte(`{"a":"it's"}`), dialect: domain.SqlPostgres, want: `'{"a":"it''s"}'`},
+
+		// booleans
+		{name: "postgres bool", value: true, dialect: domain.SqlPostgres, want: "TRUE"},
+		{name: "mysql bool", value: false, dialect: domain.SqlMySQL, want: "FALSE"},
+		{name: "sqlserver true", value: true, dialect: domain.SqlSQLServer, want: "1"},
+		{name: "sqlserver false", value: false, dialect: domain.SqlSQLServer, want: "0"},
+
+		// numbers
+		{name: "int", value: -3, dialect: domain.SqlPostgres, want: "-3"},
+		{name: "float", value: 1.5, dialect: domain.SqlMySQL, want: "1.5"},
+		{name: "int pointer", value: new(int64), dialect: domain.SqlSQLite, want: "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatLiteral(tt.value, tt.dialect)
+			if err != nil {
+				t.Fatalf("formatLiteral() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("formatLiteral() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	// eleven parameters
+	params := make([]any, 11)
+	for i := range params {
+		params[i] = i + 1
+	}
+
+	// placeholders for the eleven parameters
+	placeholders := func(format func(i int) string) string {
+		s := make([]string, len(params))
+		for i := range s {
+			s[i] = format(i + 1)
+		}
+		return strings.Join(s, ", ")
+	}
+
+	tests := []struct {
+		name        string
+		query       string
+		params      []any
+		placeholder domain.SqlPlaceholder
+		dialect     domain.SqlDialect
+		want        string
+		wantErr     bool
+	}{
+		{
+			name:        "dollar placeholders past nine",
+			query:       "SELECT * FROM t WHERE id IN (" + placeholders(func(i int) string { return "$" + strconv.Itoa(i) }) + ")",
+			params:      params,
+			placeholder: domain.SqlDollar,
+			dialect:     domain.SqlPostgres,
+			want:        "SELECT * FROM t WHERE id IN (1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11)",
+		},
+		{
+			name:        "dollar placeholders out of order",
+			query:       "SELECT * FROM t WHERE a = $11 AND b = $1 AND c = $10",
+			params:      params,
+			placeholder: domain.SqlDollar,
+			dialect:     domain.SqlPostgres,
+			want:        "SELECT * FROM t WHERE a = 11 AND b = 1 AND c = 10",
+		},
+		{
+			name:        "at p placeholders past nine",
+			query:       "SELECT * FROM t WHERE id IN (" + placeholders(func(i int) string { return "@p" + strconv.Itoa(i) }) + ")",
+			params:      params,
+			placeholder: domain.SqlAtP,
+			dialect:     domain.SqlSQLServer,
+			want:        "SELECT * FROM t WHERE id IN (1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11)",
+		},
+		{
+			name:        "question placeholders",
+			query:       "SELECT * FROM t WHERE id IN (" + placeholders(func(int) string { return "?" }) + ")",
+			params:      params,
+			placeholder: domain.SqlQuestion,
+			dialect:     domain.SqlMySQL,
+			want:        "SELECT * FROM t WHERE id IN (1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11)",
+		},
+		{
+			name:        "placeholders in quotes are kept",
+			query:       "SELECT '?', \"?\", `?` FROM t WHERE a = ?",
+			params:      []any{"it's?"},
+			placeholder: domain.SqlQuestion,
+			dialect:     domain.SqlMySQL,
+			want:        "SELECT '?', \"?\", `?` FROM t WHERE a = 'it''s?'",
+		},
+		{
+			name:        "missing parameter",
+			query:       "SELECT * FROM t WHERE a = $2",
+			params:      []any{1},
+			placeholder: domain.SqlDollar,
+			dialect:     domain.SqlPostgres,
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Interpolate(tt.query, tt.params, tt.placeholder, tt.dialect)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Interpolate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Interpolate() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
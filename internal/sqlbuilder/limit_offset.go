@@ -3,14 +3,41 @@ package sqlbuilder
 import (
 	"fmt"
 	"strings"
+
+	"github.com/tyrenix/qbr/domain"
 )
 
 // buildLimitAndOffset creates a LIMIT and OFFSET SQL clause from the given limit and offset values.
-// It returns the clause string.
-func buildLimitAndOffset(limit, offset uint64) string {
+// For SQL Server it creates an OFFSET ... FETCH clause instead, which requires an ORDER BY, so
+// sorted reports whether the query already has one. It returns the clause string.
+func buildLimitAndOffset(limit, offset uint64, sorted bool, dialect domain.SqlDialect) string {
 	// sql query
 	query := ""
 
+	// sql server offset and fetch
+	if dialect == domain.SqlSQLServer {
+		// check is limit or offset exists
+		if limit == 0 && offset == 0 {
+			return ""
+		}
+
+		// add order by if not exists
+		if !sorted {
+			query += "ORDER BY (SELECT NULL) "
+		}
+
+		// add offset
+		query += fmt.Sprintf("OFFSET %d ROWS", offset)
+
+		// add fetch
+		if limit > 0 {
+			query += fmt.Sprintf(" FETCH NEXT %d ROWS ONLY", limit)
+		}
+
+		// return offset and fetch
+		return query
+	}
+
 	// add limit
 	if limit > 0 {
 		query += fmt.Sprintf(" LIMIT %d", limit)
@@ -24,3 +51,21 @@ func buildLimitAndOffset(limit, offset uint64) string {
 	// create limit and offset
 	return strings.TrimSpace(query)
 }
+
+// buildMaxAffected creates a LIMIT SQL clause for update and delete queries from the given
+// maximum number of affected rows. It returns an error if the dialect does not support
+// LIMIT on update and delete statements.
+func buildMaxAffected(maxAffected uint64, dialect domain.SqlDialect) (string, error) {
+	// no limit
+	if maxAffected == 0 {
+		return "", nil
+	}
+
+	// check is dialect supported
+	if dialect != domain.SqlMySQL && dialect != domain.SqlSQLite {
+		return "", fmt.Errorf("max affected rows is not supported by sql dialect: %s", dialect)
+	}
+
+	// create limit
+	return fmt.Sprintf("LIMIT %d", maxAffected), nil
+}
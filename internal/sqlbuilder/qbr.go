@@ -10,5 +10,13 @@ type Query interface {
 	GetLimit() uint64
 	GetOffset() uint64
 	GetSuffix() string
+	GetReturning() []domain.Field
+	IsReturningExplicit() bool
+	GetMaxAffected() uint64
 	IsLock() bool
+	IsFinal() bool
+	GetSample() float64
+	GetPrewhere() []domain.Condition
+	GetLimitBy() (uint64, []domain.Field)
+	IsMutation() bool
 }
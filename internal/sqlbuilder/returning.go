@@ -0,0 +1,46 @@
+package sqlbuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+// buildReturning creates the clause that returns the given fields of the
+// affected rows for the dialect. The returning string is appended to the end
+// of the query as a RETURNING clause, while the output string is an OUTPUT
+// clause that SQL Server expects inside the statement, with the fields taken
+// from the given pseudo table (INSERTED or DELETED).
+//
+// Dialects without RETURNING return an error whenever there are fields to
+// return, including the default select fields, since the rows could not be
+// read back. Such queries must use NoReturning.
+func buildReturning(qb Query, dialect domain.SqlDialect, pseudoTable string) (returning, output string, err error) {
+	// returning fields
+	fields := qb.GetReturning()
+	if len(fields) == 0 {
+		return "", "", nil
+	}
+
+	// check is returning supported
+	if !SupportsReturning(dialect) {
+		return "", "", fmt.Errorf("returning is not supported by sql dialect: %s, use NoReturning", dialect)
+	}
+
+	// select dialect
+	switch dialect {
+	case domain.SqlSQLServer:
+		// create output fields
+		outputs := make([]string, len(fields))
+		for i, field := range fields {
+			outputs[i] = fmt.Sprintf("%s.%s", pseudoTable, getFieldName(&field))
+		}
+
+		// return output clause
+		return "", "OUTPUT " + strings.Join(outputs, ", "), nil
+	default:
+		// return returning clause
+		return "RETURNING " + buildSelects(fields), "", nil
+	}
+}
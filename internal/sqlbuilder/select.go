@@ -10,7 +10,17 @@ import (
 // CreateSelectSql creates a SQL SELECT query from the Query's select list, conditions,
 // sort, limit, and offset. It returns the query string, the parameters for the query,
 // and an error if the query could not be built.
-func CreateSelectSql(qb Query, table string, placeholder domain.SqlPlaceholder) (string, []any, error) {
+func CreateSelectSql(qb Query, table string, placeholder domain.SqlPlaceholder, dialect domain.SqlDialect) (string, []any, error) {
+	// check returning
+	if qb.IsReturningExplicit() {
+		return "", nil, fmt.Errorf("returning is not supported for read queries")
+	}
+
+	// check aggregations
+	if err := checkAggregations(qb.GetSelects(), dialect); err != nil {
+		return "", nil, err
+	}
+
 	// create main query
 	query := fmt.Sprintf(
 		"SELECT %s FROM %s",
@@ -18,8 +28,14 @@ func CreateSelectSql(qb Query, table string, placeholder domain.SqlPlaceholder)
 		table,
 	)
 
-	// create params
-	var params []any
+	// add final, sample and prewhere
+	from, params, err := buildClickHouseFrom(qb, placeholder, dialect, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	if from != "" {
+		query += " " + from
+	}
 
 	// conditionals
 	conds := qb.GetConditions()
@@ -33,14 +49,14 @@ func CreateSelectSql(qb Query, table string, placeholder domain.SqlPlaceholder)
 	// is conditions exists add conditions and params
 	if len(conds) > 0 {
 		// create conditions
-		cond, condParams, err := buildConditions(conds, placeholder, nil)
+		cond, condParams, err := buildConditions(conds, placeholder, dialect, params)
 		if err != nil {
 			return "", nil, err
 		}
 
 		// add conditions
 		query += " WHERE " + cond
-		params = append(params, condParams...)
+		params = condParams
 	}
 
 	// add sort
@@ -62,8 +78,17 @@ func CreateSelectSql(qb Query, table string, placeholder domain.SqlPlaceholder)
 		query += orderByStr + " " + strings.Join(sortClauses, ", ")
 	}
 
+	// add limit by
+	limitBy, err := buildLimitBy(qb, dialect)
+	if err != nil {
+		return "", nil, err
+	}
+	if limitBy != "" {
+		query += " " + limitBy
+	}
+
 	// add limit and offset
-	if v := buildLimitAndOffset(limit, offset); v != "" {
+	if v := buildLimitAndOffset(limit, offset, len(sorts) > 0, dialect); v != "" {
 		// add limit and offset
 		query += " " + v
 	}
@@ -73,6 +98,11 @@ func CreateSelectSql(qb Query, table string, placeholder domain.SqlPlaceholder)
 
 	// add lock is need
 	if qb.IsLock() {
+		// check is lock supported
+		if dialect == domain.SqlSQLServer || dialect == domain.SqlClickHouse {
+			return "", nil, fmt.Errorf("lock is not supported by sql dialect: %s", dialect)
+		}
+
 		// add lock
 		query += " FOR UPDATE"
 	}
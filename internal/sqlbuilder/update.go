@@ -9,15 +9,17 @@ import (
 
 // CreateUpdateSql creates a SQL UPDATE query from the Query's data. It returns the query string,
 // the parameters for the query, and an error if the query could not be built.
-func CreateUpdateSql(qb Query, table string, placeholder domain.SqlPlaceholder) (string, []any, error) {
+func CreateUpdateSql(qb Query, table string, placeholder domain.SqlPlaceholder, dialect domain.SqlDialect) (string, []any, error) {
 	var sets []string
 	var params []any
 
 	// create base query
 	query := fmt.Sprintf("UPDATE %s SET ", table)
+	if dialect == domain.SqlClickHouse {
+		// clickhouse updates are mutations
+		query = fmt.Sprintf("ALTER TABLE %s UPDATE ", table)
+	}
 
-	// select fields
-	selects := qb.GetSelects()
 	// conditionals
 	conds := qb.GetConditions()
 	// data
@@ -40,10 +42,21 @@ func CreateUpdateSql(qb Query, table string, placeholder domain.SqlPlaceholder)
 	// add to query set data
 	query += strings.Join(sets, ", ")
 
+	// create returning fields
+	returning, output, err := buildReturning(qb, dialect, "INSERTED")
+	if err != nil {
+		return "", nil, err
+	}
+
+	// add output fields
+	if output != "" {
+		query += " " + output
+	}
+
 	// if exists conditions add to query
 	if len(conds) > 0 {
 		// create conditions
-		conds, condsParams, err := buildConditions(conds, placeholder, params)
+		conds, condsParams, err := buildConditions(conds, placeholder, dialect, params)
 		if err != nil {
 			return "", nil, err
 		}
@@ -52,12 +65,23 @@ func CreateUpdateSql(qb Query, table string, placeholder domain.SqlPlaceholder)
 		query += " WHERE " + conds
 		// add condition params to params
 		params = condsParams
+	} else if dialect == domain.SqlClickHouse {
+		// clickhouse requires where clause
+		query += " WHERE 1"
+	}
+
+	// add returning fields
+	if returning != "" {
+		query += " " + returning
 	}
 
-	// build returning fields
-	if len(selects) > 0 {
-		// create returning fields
-		query += " RETURNING " + buildSelects(selects)
+	// add max affected rows limit
+	maxAffected, err := buildMaxAffected(qb.GetMaxAffected(), dialect)
+	if err != nil {
+		return "", nil, err
+	}
+	if maxAffected != "" {
+		query += " " + maxAffected
 	}
 
 	// add suffix
@@ -1,10 +1,14 @@
 package sqlbuilder
 
 import (
+	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/tyrenix/qbr/domain"
 )
@@ -22,6 +26,30 @@ func getSqlOperator(op domain.OperatorType) string {
 	return v
 }
 
+// GetDialectPlaceholder returns the SQL placeholder used by the given dialect.
+// If the dialect is not supported, it returns an error.
+func GetDialectPlaceholder(dialect domain.SqlDialect) (domain.SqlPlaceholder, error) {
+	// get placeholder
+	plc, ok := sqlDialectPlaceholders[dialect]
+	if !ok {
+		return "", fmt.Errorf("unsupported sql dialect: %s", dialect)
+	}
+
+	// return placeholder
+	return plc, nil
+}
+
+// SupportsReturning reports whether the given dialect can return the affected
+// rows of create, update and delete queries.
+func SupportsReturning(dialect domain.SqlDialect) bool {
+	return !sqlDialectsWithoutReturning[dialect]
+}
+
+// IsOperatorSupported reports whether the given condition operator is supported.
+func IsOperatorSupported(op domain.OperatorType) bool {
+	return getSqlOperator(op) != ""
+}
+
 // getFieldName takes a Field object and returns the string value of its DB
 // field. This is the field name in the database that the field corresponds to.
 func getFieldName(field *domain.Field) string {
@@ -30,12 +58,13 @@ func getFieldName(field *domain.Field) string {
 
 // getPlaceholder generates a SQL placeholder string based on the specified
 // placeholder type and index. If the placeholder type is ToSqlDollar, it returns
-// a parameterized string using the dollar sign format (e.g., $1, $2). Otherwise,
-// it returns the placeholder type as a string.
+// a parameterized string using the dollar sign format (e.g., $1, $2), and for
+// SqlAtP it returns a named parameter (e.g., @p1, @p2). Otherwise, it returns
+// the placeholder type as a string.
 func getPlaceholder(plc domain.SqlPlaceholder, index int) string {
-	// dollar placeholder
-	if plc == domain.SqlDollar {
-		return fmt.Sprintf("$%d", index)
+	// indexed placeholder
+	if plc == domain.SqlDollar || plc == domain.SqlAtP {
+		return fmt.Sprintf("%s%d", plc, index)
 	}
 
 	// return default placeholder
@@ -44,9 +73,9 @@ func getPlaceholder(plc domain.SqlPlaceholder, index int) string {
 
 // valueToDBValue takes a value and returns a value that can be used in a
 // SQL query. If the value is a ValueType, it returns an error if it is not a
-// null value. If the value is a struct or a pointer to a struct, it marshals
-// the struct to JSON and returns it as a string. Otherwise, it returns the
-// original value.
+// null value. If the value is of a JSON type, as reported by IsJSONType, it
+// marshals the value to JSON, a nil pointer becoming NULL. Otherwise, it
+// returns the original value.
 func valueToDBValue(value any) (any, error) {
 	// is value is ValueType
 	if v, ok := value.(domain.ValueType); ok {
@@ -62,11 +91,15 @@ func valueToDBValue(value any) (any, error) {
 	// get reflect value
 	v := reflect.ValueOf(value)
 
-	// check if the value is a struct or a pointer to a struct
-	if v.Kind() == reflect.Ptr &&
-		v.Elem().Kind() == reflect.Struct {
+	// check if the value is stored as JSON
+	if v.IsValid() && IsJSONType(v.Type()) {
+		// nil pointer is null
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return nil, nil
+		}
+
 		// convert struct to JSON
-		j, err := json.Marshal(v.Elem().Interface())
+		j, err := json.Marshal(value)
 		if err != nil {
 			return nil, err
 		}
@@ -75,10 +108,39 @@ func valueToDBValue(value any) (any, error) {
 		return j, nil
 	}
 
-	// return the original value if not a struct or pointer to a struct
+	// return the original value if not stored as JSON
 	return value, nil
 }
 
+// IsJSONType reports whether values of type t are stored as JSON: structs and
+// pointers to structs, except time.Time and types that handle their own
+// conversion by implementing driver.Valuer or sql.Scanner. It is used both
+// when values are written and when columns are scanned, so both directions
+// agree.
+func IsJSONType(t reflect.Type) bool {
+	// dereference pointer
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	// check is valuer or scanner
+	for _, it := range []reflect.Type{t, reflect.PointerTo(t)} {
+		if it.Implements(valuerType) || it.Implements(scannerType) {
+			return false
+		}
+	}
+
+	// check is struct and not time
+	return t.Kind() == reflect.Struct && t != reflect.TypeOf(time.Time{})
+}
+
+// valuerType and scannerType are the reflect types of the driver.Valuer and
+// sql.Scanner interfaces.
+var (
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+)
+
 // buildSelectFields formats a slice of Field objects into a SQL select statement string.
 // It iterates over the provided fields, and for each field, it checks if there is an
 // associated SQL format in the sqlFieldFormats map based on the field's type. If a format
@@ -91,6 +153,19 @@ func buildSelects(fields []domain.Field) string {
 
 	// iterate over the slice of fields
 	for _, field := range fields {
+		// parametric aggregation
+		if format, ok := sqlParametricAggregationFormats[field.Aggregation]; ok && field.Level > 0 {
+			result = append(
+				result,
+				fmt.Sprintf(
+					format,
+					strconv.FormatFloat(field.Level, 'g', -1, 64), // level parameter
+					getFieldName(&field),                          // get database field name
+				),
+			)
+			continue
+		}
+
 		// check is contains in map
 		format, ok := sqlAggregationFormats[field.Aggregation]
 		if !ok {
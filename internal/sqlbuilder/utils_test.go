@@ -0,0 +1,62 @@
+package sqlbuilder
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+type jsonMeta struct {
+	Tags []string `json:"tags"`
+}
+
+// scannedMeta converts itself when scanned, so it is never stored as JSON.
+type scannedMeta struct {
+	Tags string
+}
+
+func (m *scannedMeta) Scan(src any) error {
+	m.Tags, _ = src.(string)
+	return nil
+}
+
+func TestValueToDBValue(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	note := sql.NullString{String: "hi", Valid: true}
+
+	tests := []struct {
+		name    string
+		value   any
+		want    any
+		wantErr bool
+	}{
+		{name: "null", value: domain.ValueNull, want: nil},
+		{name: "unsupported value type", value: domain.ValueType(100), wantErr: true},
+		{name: "nil", value: nil, want: nil},
+		{name: "int", value: 1, want: 1},
+		{name: "string", value: "a", want: "a"},
+		{name: "bytes", value: []byte("a"), want: []byte("a")},
+		{name: "struct", value: jsonMeta{Tags: []string{"a"}}, want: []byte(`{"tags":["a"]}`)},
+		{name: "struct pointer", value: &jsonMeta{Tags: []string{"b"}}, want: []byte(`{"tags":["b"]}`)},
+		{name: "nil struct pointer", value: (*jsonMeta)(nil), want: nil},
+		{name: "time", value: now, want: now},
+		{name: "time pointer", value: &now, want: &now},
+		{name: "valuer", value: note, want: note},
+		{name: "scanner", value: scannedMeta{Tags: "a"}, want: scannedMeta{Tags: "a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := valueToDBValue(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("valueToDBValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("valueToDBValue() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,598 @@
+package qbr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+// JSONVersion is the version of the JSON representation of queries produced by
+// MarshalJSON. UnmarshalJSON rejects other versions.
+const JSONVersion = 1
+
+// jsonQuery is the JSON representation of a query.
+//
+// The suffix is raw SQL and is never serialized.
+type jsonQuery struct {
+	Version        int                  `json:"version"`
+	Operation      domain.OperationType `json:"operation"`
+	Select         []jsonField          `json:"select,omitempty"`
+	Returning      []jsonField          `json:"returning,omitempty"`
+	NoReturning    bool                 `json:"no_returning,omitempty"`
+	Where          []jsonCondition      `json:"where,omitempty"`
+	Sort           []jsonSort           `json:"sort,omitempty"`
+	Data           []jsonData           `json:"data,omitempty"`
+	Limit          uint64               `json:"limit,omitempty"`
+	Offset         uint64               `json:"offset,omitempty"`
+	Lock           bool                 `json:"lock,omitempty"`
+	AllowFullTable bool                 `json:"allow_full_table,omitempty"`
+	MaxAffected    uint64               `json:"max_affected,omitempty"`
+	Final          bool                 `json:"final,omitempty"`
+	Sample         float64              `json:"sample,omitempty"`
+	Prewhere       []jsonCondition      `json:"prewhere,omitempty"`
+	LimitBy        uint64               `json:"limit_by,omitempty"`
+	LimitByFields  []string             `json:"limit_by_fields,omitempty"`
+	Mutation       bool                 `json:"mutation,omitempty"`
+}
+
+// jsonField is the JSON representation of a field.
+type jsonField struct {
+	Field       string  `json:"field"`
+	Aggregation string  `json:"aggregation,omitempty"`
+	Level       float64 `json:"level,omitempty"`
+}
+
+// jsonCondition is the JSON representation of a condition. Logical conditions
+// have nested conditions instead of a field and value.
+type jsonCondition struct {
+	Field      string          `json:"field,omitempty"`
+	Op         string          `json:"op"`
+	Value      json.RawMessage `json:"value,omitempty"`
+	Conditions []jsonCondition `json:"conditions,omitempty"`
+}
+
+// jsonSort is the JSON representation of a sort.
+type jsonSort struct {
+	Field string `json:"field"`
+	Order string `json:"order"`
+}
+
+// jsonData is the JSON representation of data. Modifications have a modify
+// object instead of a value.
+type jsonData struct {
+	Field  string            `json:"field"`
+	Value  json.RawMessage   `json:"value,omitempty"`
+	Modify *jsonModification `json:"modify,omitempty"`
+}
+
+// jsonModification is the JSON representation of a modification.
+type jsonModification struct {
+	Field string          `json:"field"`
+	Op    string          `json:"op"`
+	Value json.RawMessage `json:"value"`
+}
+
+// AllowFields sets the fields that may be referenced by a query decoded with
+// UnmarshalJSON. Decoded field names are resolved to the given fields, and any
+// other field name is rejected with ErrFieldNotAllowed. The ignored operations
+// of the fields apply to decoded data as with Set, so data on a field ignored
+// for the query's operation is skipped. The "*" field is always allowed in
+// selects.
+//
+// Without allowed fields, every field name is rejected. The method returns the
+// QueryBuilder instance to support method chaining:
+//
+//	qb := qbr.NewRead().AllowFields(nameField, ageField)
+//	err := json.Unmarshal(data, qb)
+func (qb *Query) AllowFields(fields ...*domain.Field) *Query {
+	// copy immutable query
+	qb = qb.mutable()
+
+	// set allowed fields
+	qb.allowedFields = make(map[string]*domain.Field, len(fields))
+	for _, field := range fields {
+		qb.allowedFields[field.DB] = field
+	}
+
+	// return query
+	return qb
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+//
+// The query is encoded in a stable, versioned JSON representation with
+// operator, aggregation and modification names instead of numeric values.
+// The suffix is raw SQL and is not encoded.
+func (qb *Query) MarshalJSON() ([]byte, error) {
+	// create json query
+	j := jsonQuery{
+		Version:        JSONVersion,
+		Operation:      qb.operation,
+		NoReturning:    qb.returningMode == returningNone,
+		Limit:          qb.limit,
+		Offset:         qb.offset,
+		Lock:           qb.lock,
+		AllowFullTable: qb.allowFullTable,
+		MaxAffected:    qb.maxAffected,
+		Final:          qb.final,
+		Sample:         qb.sample,
+		LimitBy:        qb.limitBy,
+		Mutation:       qb.mutation,
+	}
+
+	// encode fields
+	j.Select = encodeJSONFields(qb.selects)
+	if qb.returningMode == returningExplicit {
+		j.Returning = encodeJSONFields(qb.returning)
+	}
+
+	// encode conditions
+	where, err := encodeJSONConditions(qb.conditions)
+	if err != nil {
+		return nil, err
+	}
+	j.Where = where
+
+	// encode prewhere conditions
+	prewhere, err := encodeJSONConditions(qb.prewhere)
+	if err != nil {
+		return nil, err
+	}
+	j.Prewhere = prewhere
+
+	// encode limit by fields
+	for _, field := range qb.limitByFields {
+		j.LimitByFields = append(j.LimitByFields, field.DB)
+	}
+
+	// encode sorts
+	for _, sort := range qb.sort {
+		j.Sort = append(j.Sort, jsonSort{
+			Field: sort.Field.DB,
+			Order: string(sort.Type),
+		})
+	}
+
+	// encode data
+	for _, d := range qb.data {
+		// encode modification
+		if mod, ok := d.Value.(*domain.Modification); ok {
+			// get modification name
+			op, ok := modificationNames[mod.Operator]
+			if !ok {
+				return nil, fmt.Errorf("unsupported modification operator: %d", mod.Operator)
+			}
+
+			// encode value
+			value, err := json.Marshal(mod.Value)
+			if err != nil {
+				return nil, err
+			}
+
+			// add data
+			j.Data = append(j.Data, jsonData{
+				Field:  d.Field.DB,
+				Modify: &jsonModification{Field: mod.Field.DB, Op: op, Value: value},
+			})
+			continue
+		}
+
+		// encode value
+		value, err := encodeJSONValue(d.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		// add data
+		j.Data = append(j.Data, jsonData{Field: d.Field.DB, Value: value})
+	}
+
+	// encode query
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+//
+// It decodes the JSON representation produced by MarshalJSON into the query,
+// replacing its contents. Unknown keys, versions and operator names are
+// rejected, and field names must be among the fields set with AllowFields.
+// The value of an IN condition must be an array. Numbers are decoded as int64
+// when integral and float64 otherwise, and JSON objects in data values are
+// kept as raw JSON.
+//
+// The operation, lock, mutation and safety settings (AllowFullTable and
+// MaxAffected) are kept from the query itself, since they must not be
+// controlled by untrusted input: a JSON query with a different operation or
+// different settings is rejected. Decoded data is added as with Set.
+func (qb *Query) UnmarshalJSON(data []byte) error {
+	// decode json query
+	var j jsonQuery
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&j); err != nil {
+		return err
+	}
+
+	// check version
+	if j.Version != JSONVersion {
+		return fmt.Errorf("unsupported query json version: %d", j.Version)
+	}
+
+	// check operation
+	if j.Operation != qb.operation {
+		return fmt.Errorf("query json operation %q does not match query operation %q", j.Operation, qb.operation)
+	}
+
+	// check settings
+	switch {
+	case j.Lock != qb.lock:
+		return fmt.Errorf("query json lock does not match query: %v", j.Lock)
+	case j.AllowFullTable != qb.allowFullTable:
+		return fmt.Errorf("query json allow_full_table does not match query: %v", j.AllowFullTable)
+	case j.MaxAffected != qb.maxAffected:
+		return fmt.Errorf("query json max_affected does not match query: %d", j.MaxAffected)
+	case j.Mutation != qb.mutation:
+		return fmt.Errorf("query json mutation does not match query: %v", j.Mutation)
+	}
+
+	// create decoded query
+	q := New(qb.operation)
+	q.allowedFields = qb.allowedFields
+	q.limit = j.Limit
+	q.offset = j.Offset
+	q.lock = qb.lock
+	q.allowFullTable = qb.allowFullTable
+	q.maxAffected = qb.maxAffected
+	q.final = j.Final
+	q.sample = j.Sample
+	q.limitBy = j.LimitBy
+	q.mutation = qb.mutation
+
+	// decode selects
+	if j.Select != nil {
+		selects, err := q.decodeJSONFields(j.Select)
+		if err != nil {
+			return err
+		}
+		q.selects = selects
+	}
+
+	// decode returning
+	switch {
+	case j.NoReturning:
+		q.returningMode = returningNone
+	case j.Returning != nil:
+		returning, err := q.decodeJSONFields(j.Returning)
+		if err != nil {
+			return err
+		}
+		q.returning = returning
+		q.returningMode = returningExplicit
+	}
+
+	// decode conditions
+	conds, err := q.decodeJSONConditions(j.Where)
+	if err != nil {
+		return err
+	}
+	if err := validateConditions(conds); err != nil {
+		return err
+	}
+	q.conditions = conds
+
+	// decode prewhere conditions
+	prewhere, err := q.decodeJSONConditions(j.Prewhere)
+	if err != nil {
+		return err
+	}
+	if err := validateConditions(prewhere); err != nil {
+		return err
+	}
+	q.prewhere = prewhere
+
+	// decode limit by fields
+	for _, name := range j.LimitByFields {
+		field, err := q.decodeJSONField(name)
+		if err != nil {
+			return err
+		}
+		q.limitByFields = append(q.limitByFields, *field)
+	}
+
+	// decode sorts
+	for _, s := range j.Sort {
+		// get field
+		field, err := q.decodeJSONField(s.Field)
+		if err != nil {
+			return err
+		}
+
+		// check sort type
+		sort := domain.SortType(s.Order)
+		if sort != domain.SortAsc && sort != domain.SortDesc {
+			return fmt.Errorf("unknown sort order: %q", s.Order)
+		}
+
+		// add sort
+		q.sort = append(q.sort, domain.Sort{Field: field, Type: sort})
+	}
+
+	// decode data
+	for _, d := range j.Data {
+		// get field
+		field, err := q.decodeJSONField(d.Field)
+		if err != nil {
+			return err
+		}
+
+		// decode value
+		var value any
+		if d.Modify != nil {
+			value, err = q.decodeJSONModification(d.Modify)
+		} else {
+			value, err = decodeJSONValue(d.Value, true)
+		}
+		if err != nil {
+			return err
+		}
+
+		// add data
+		q.Set(&domain.Data{Field: field, Value: value, AcceptZero: true})
+	}
+
+	// replace query
+	q.immutable = qb.immutable
+	*qb = *q
+
+	// return success
+	return nil
+}
+
+// encodeJSONFields encodes the fields to their JSON representation.
+func encodeJSONFields(fields []domain.Field) []jsonField {
+	// json fields
+	result := make([]jsonField, len(fields))
+	for i, field := range fields {
+		result[i] = jsonField{
+			Field:       field.DB,
+			Aggregation: aggregationNames[field.Aggregation],
+			Level:       field.Level,
+		}
+	}
+
+	// return fields
+	return result
+}
+
+// encodeJSONConditions recursively encodes the conditions to their JSON
+// representation.
+func encodeJSONConditions(conds []domain.Condition) ([]jsonCondition, error) {
+	// json conditions
+	var result []jsonCondition
+	for _, cond := range conds {
+		// get operator name
+		op, ok := operatorNames[cond.Operator]
+		if !ok {
+			return nil, ErrUnsupportedOperator{Op: cond.Operator}
+		}
+
+		// encode nested conditions
+		if nested, ok := cond.Value.([]domain.Condition); ok {
+			sub, err := encodeJSONConditions(nested)
+			if err != nil {
+				return nil, err
+			}
+
+			// add condition
+			result = append(result, jsonCondition{Op: op, Conditions: sub})
+			continue
+		}
+
+		// encode value
+		value, err := encodeJSONValue(cond.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		// add condition
+		result = append(result, jsonCondition{
+			Field: cond.Field.DB,
+			Op:    op,
+			Value: value,
+		})
+	}
+
+	// return conditions
+	return result, nil
+}
+
+// encodeJSONValue encodes the value, encoding domain.ValueNull as JSON null.
+func encodeJSONValue(value any) (json.RawMessage, error) {
+	// null value
+	if v, ok := value.(domain.ValueType); ok && v == domain.ValueNull {
+		return json.RawMessage("null"), nil
+	}
+
+	// encode value
+	return json.Marshal(value)
+}
+
+// decodeJSONFields decodes and resolves the JSON representation of fields.
+func (qb *Query) decodeJSONFields(fields []jsonField) ([]domain.Field, error) {
+	// fields
+	result := make([]domain.Field, 0, len(fields))
+	for _, f := range fields {
+		// get field
+		var field *domain.Field
+		if f.Field == "*" {
+			field = NewAllField()
+		} else {
+			var err error
+			if field, err = qb.decodeJSONField(f.Field); err != nil {
+				return nil, err
+			}
+		}
+
+		// get aggregation
+		agg, err := parseName(aggregationNames, f.Aggregation, "aggregation")
+		if err != nil {
+			return nil, err
+		}
+
+		// add field
+		field.Aggregation = agg
+		field.Level = f.Level
+		result = append(result, *field)
+	}
+
+	// return fields
+	return result, nil
+}
+
+// decodeJSONField resolves the field name against the allowed fields. It
+// returns a new field that can be modified by the caller.
+func (qb *Query) decodeJSONField(name string) (*domain.Field, error) {
+	// check allowed fields
+	field, ok := qb.allowedFields[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrFieldNotAllowed, name)
+	}
+
+	// return copy of field
+	return cloneField(field), nil
+}
+
+// decodeJSONConditions recursively decodes and resolves the JSON representation
+// of conditions.
+func (qb *Query) decodeJSONConditions(conds []jsonCondition) ([]domain.Condition, error) {
+	// conditions
+	var result []domain.Condition
+	for _, c := range conds {
+		// get operator
+		op, err := ParseOperator(c.Op)
+		if err != nil {
+			return nil, err
+		}
+
+		// decode nested conditions
+		if IsLogicalOperator(op) {
+			nested, err := qb.decodeJSONConditions(c.Conditions)
+			if err != nil {
+				return nil, err
+			}
+
+			// add condition
+			result = append(result, domain.Condition{Operator: op, Value: nested})
+			continue
+		}
+
+		// get field
+		field, err := qb.decodeJSONField(c.Field)
+		if err != nil {
+			return nil, err
+		}
+
+		// decode value
+		value, err := decodeJSONValue(c.Value, false)
+		if err != nil {
+			return nil, err
+		}
+
+		// check is in value an array
+		if _, ok := value.([]any); op == domain.OperatorIn && !ok {
+			return nil, fmt.Errorf("invalid value for operator %q on field %q: expected array", c.Op, c.Field)
+		}
+
+		// null value
+		if value == nil {
+			value = domain.ValueNull
+		}
+
+		// add condition
+		result = append(result, domain.Condition{Field: field, Operator: op, Value: value})
+	}
+
+	// return conditions
+	return result, nil
+}
+
+// decodeJSONModification decodes and resolves the JSON representation of a
+// modification.
+func (qb *Query) decodeJSONModification(m *jsonModification) (*domain.Modification, error) {
+	// get field
+	field, err := qb.decodeJSONField(m.Field)
+	if err != nil {
+		return nil, err
+	}
+
+	// get operator
+	op, err := parseName(modificationNames, m.Op, "modification")
+	if err != nil {
+		return nil, err
+	}
+
+	// decode value
+	value, err := decodeJSONValue(m.Value, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// return modification
+	return &domain.Modification{Field: field, Operator: op, Value: value}, nil
+}
+
+// decodeJSONValue decodes a JSON value. Integral numbers are decoded as int64
+// and other numbers as float64. If rawObjects is set, JSON objects are kept as
+// raw JSON, matching how struct values are written to the database.
+func decodeJSONValue(data json.RawMessage, rawObjects bool) (any, error) {
+	// missing value
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	// keep raw objects
+	if rawObjects && data[0] == '{' {
+		return append(json.RawMessage(nil), data...), nil
+	}
+
+	// decode value
+	var value any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	// convert numbers
+	return convertJSONNumbers(value), nil
+}
+
+// convertJSONNumbers recursively converts json.Number values to int64 or
+// float64.
+func convertJSONNumbers(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		// integral number
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+
+		// float number
+		f, _ := v.Float64()
+		return f
+	case []any:
+		for i := range v {
+			v[i] = convertJSONNumbers(v[i])
+		}
+	case map[string]any:
+		for k := range v {
+			v[k] = convertJSONNumbers(v[k])
+		}
+	}
+
+	// return value
+	return value
+}
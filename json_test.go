@@ -0,0 +1,170 @@
+package qbr
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+func TestQueryJSON(t *testing.T) {
+	id := NewField(WithDB("id"))
+	name := NewField(WithDB("name"))
+
+	tests := []struct {
+		name string
+		qb   *Query
+	}{
+		{
+			name: "read",
+			qb: NewRead().
+				Select(NewAllField()).
+				Where(Or(Eq(id, int64(1)), In(name, "a", "b"), Eq(name, domain.ValueNull))).
+				Sort(NewSortDesc(name)).
+				Limit(10).
+				Offset(5),
+		},
+		{
+			name: "update",
+			qb: NewUpdate().
+				Set(NewData(name, "a"), NewData(id, Add(id, int64(1)))).
+				Where(Eq(id, int64(1))).
+				NoReturning(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.qb)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+
+			// decode query
+			got := New(tt.qb.GetOperation()).AllowFields(id, name)
+			if err := json.Unmarshal(data, got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+
+			// compare built queries
+			wantSql, wantArgs, err := tt.qb.ToSql("users", SqlDollar)
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			gotSql, gotArgs, err := got.ToSql("users", SqlDollar)
+			if err != nil {
+				t.Fatalf("decoded ToSql() error = %v", err)
+			}
+			if gotSql != wantSql || !reflect.DeepEqual(gotArgs, wantArgs) {
+				t.Errorf("decoded query = %s %v, want %s %v", gotSql, gotArgs, wantSql, wantArgs)
+			}
+		})
+	}
+}
+
+func TestQueryUnmarshalJSONErrors(t *testing.T) {
+	id := NewField(WithDB("id"))
+
+	tests := []struct {
+		name    string
+		qb      *Query
+		data    string
+		wantErr error
+		notErr  error
+	}{
+		{
+			name:    "fields denied by default",
+			qb:      NewRead(),
+			data:    `{"version":1,"operation":"read","where":[{"field":"id","op":"eq","value":1}]}`,
+			wantErr: ErrFieldNotAllowed,
+		},
+		{
+			name:    "field not allowed",
+			qb:      NewRead().AllowFields(id),
+			data:    `{"version":1,"operation":"read","sort":[{"field":"password","order":"ASC"}]}`,
+			wantErr: ErrFieldNotAllowed,
+		},
+		{
+			name:    "empty in",
+			qb:      NewRead().AllowFields(id),
+			data:    `{"version":1,"operation":"read","where":[{"field":"id","op":"in","value":[]}]}`,
+			wantErr: ErrEmptyIn,
+		},
+		{
+			name:   "in not array",
+			qb:     NewRead().AllowFields(id),
+			data:   `{"version":1,"operation":"read","where":[{"field":"id","op":"in","value":1}]}`,
+			notErr: ErrEmptyIn,
+		},
+		{
+			name: "unknown version",
+			qb:   NewRead().AllowFields(id),
+			data: `{"version":2,"operation":"read"}`,
+		},
+		{
+			name: "operation mismatch",
+			qb:   NewRead().AllowFields(id),
+			data: `{"version":1,"operation":"delete","where":[{"field":"id","op":"eq","value":1}]}`,
+		},
+		{
+			name: "allow full table from json",
+			qb:   NewDelete().AllowFields(id),
+			data: `{"version":1,"operation":"delete","allow_full_table":true}`,
+		},
+		{
+			name: "max affected from json",
+			qb:   NewDelete().AllowFields(id).MaxAffected(1),
+			data: `{"version":1,"operation":"delete","max_affected":1000}`,
+		},
+		{
+			name: "mutation from json",
+			qb:   NewDelete().AllowFields(id),
+			data: `{"version":1,"operation":"delete","where":[{"field":"id","op":"eq","value":1}],"mutation":true}`,
+		},
+		{
+			name: "lock from json",
+			qb:   NewRead().AllowFields(id),
+			data: `{"version":1,"operation":"read","lock":true}`,
+		},
+		{
+			name: "unknown key",
+			qb:   NewRead().AllowFields(id),
+			data: `{"version":1,"operation":"read","suffix":"; DROP TABLE users"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := json.Unmarshal([]byte(tt.data), tt.qb)
+			if err == nil {
+				t.Fatal("Unmarshal() error = nil, want error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.notErr != nil && errors.Is(err, tt.notErr) {
+				t.Errorf("Unmarshal() error = %v, want other than %v", err, tt.notErr)
+			}
+		})
+	}
+}
+
+func TestQueryUnmarshalJSONIgnoredData(t *testing.T) {
+	id := NewField(WithDB("id"))
+	role := NewField(WithDB("role"), WithIgnoreOn(domain.OperationUpdate))
+
+	// decode data on a field ignored for update
+	qb := NewUpdate().AllowFields(id, role).AllowFullTable()
+	data := `{"version":1,"operation":"update","data":[{"field":"id","value":1},{"field":"role","value":"admin"}],"allow_full_table":true}`
+	if err := json.Unmarshal([]byte(data), qb); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	// check ignored field is skipped
+	got := qb.GetData()
+	if len(got) != 1 || got[0].Field.DB != "id" {
+		t.Errorf("GetData() = %v, want only id", got)
+	}
+}
@@ -2,6 +2,9 @@ package qbr
 
 // Limit set limit.
 func (qb *Query) Limit(limit uint64) *Query {
+	// copy immutable query
+	qb = qb.mutable()
+
 	// set limit
 	qb.limit = limit
 
@@ -8,7 +8,13 @@ package qbr
 // Use this method to set the lock on the query. Returns the modified QueryBuilder
 // instance for method chaining.
 func (qb *Query) Lock() *Query {
+	// copy immutable query
+	qb = qb.mutable()
+
+	// set lock
 	qb.lock = true
+
+	// return query
 	return qb
 }
 
@@ -0,0 +1,649 @@
+package qbr
+
+import (
+	"bytes"
+	"cmp"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+// truth is a three-valued logic value of SQL conditions.
+type truth int
+
+// Truth values.
+const (
+	truthFalse truth = iota
+	truthTrue
+	truthUnknown
+)
+
+// Match reports whether the value satisfies the conditions, evaluated in
+// memory the same way a database evaluates them in a WHERE clause.
+//
+// The value is a struct, a pointer to a struct or a map with string keys.
+// Condition fields are resolved by their DB names through the "db" tags of the
+// struct, including fields of embedded structs, or by the map keys. Qualified
+// names such as "users.name" fall back to the last name part. A field missing
+// in a map is NULL, while a field missing in a struct is an error.
+//
+// Every operator is supported, including nested And, Or and Not groups, IN and
+// LIKE patterns. NULL follows SQL semantics: nil pointers, nil values and
+// driver.Valuer values returning nil are NULL, comparisons with NULL are
+// unknown, and a value matches only if the conditions are true.
+// Eq and NoEq with domain.ValueNull check IS NULL and IS NOT NULL.
+func Match(conds []domain.Condition, value any) (bool, error) {
+	// validate conditions
+	if err := validateConditions(conds); err != nil {
+		return false, err
+	}
+
+	// create resolver
+	r, err := newFieldResolver(value)
+	if err != nil {
+		return false, err
+	}
+
+	// evaluate conditions
+	t, err := r.evalAnd(conds)
+	if err != nil {
+		return false, err
+	}
+
+	// return result
+	return t == truthTrue, nil
+}
+
+// SortSlice sorts the slice in place by the sorts, comparing fields resolved
+// the same way as Match. The sort is stable. NULL values are sorted after
+// other values in ascending order and before them in descending order, as in
+// PostgreSQL.
+func SortSlice[T any](s []T, sorts []domain.Sort) error {
+	// no sorts
+	if len(sorts) == 0 {
+		return nil
+	}
+
+	// resolve sort keys
+	keys := make([][]any, len(s))
+	for i := range s {
+		// create resolver
+		r, err := newFieldResolver(s[i])
+		if err != nil {
+			return err
+		}
+
+		// resolve fields
+		keys[i] = make([]any, len(sorts))
+		for j, sort := range sorts {
+			if keys[i][j], err = r.resolve(sort.Field); err != nil {
+				return err
+			}
+		}
+	}
+
+	// sort indexes
+	var sortErr error
+	indexes := make([]int, len(s))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	slices.SortStableFunc(indexes, func(a, b int) int {
+		for j, sort := range sorts {
+			// compare keys
+			c, err := compareSortKeys(keys[a][j], keys[b][j])
+			if err != nil {
+				if sortErr == nil {
+					sortErr = err
+				}
+				return 0
+			}
+
+			// apply order
+			if c != 0 {
+				if sort.Type == domain.SortDesc {
+					return -c
+				}
+				return c
+			}
+		}
+		return 0
+	})
+	if sortErr != nil {
+		return sortErr
+	}
+
+	// reorder slice
+	sorted := make([]T, len(s))
+	for i, index := range indexes {
+		sorted[i] = s[index]
+	}
+	copy(s, sorted)
+
+	// sorted successfully
+	return nil
+}
+
+// Paginate returns the part of the slice selected by the limit and offset, as
+// LIMIT and OFFSET do. Zero limit means no limit. The returned slice shares the
+// underlying array with s.
+func Paginate[T any](s []T, limit, offset uint64) []T {
+	// apply offset
+	if offset >= uint64(len(s)) {
+		return s[:0]
+	}
+	s = s[offset:]
+
+	// apply limit
+	if limit > 0 && limit < uint64(len(s)) {
+		s = s[:limit]
+	}
+
+	// return page
+	return s
+}
+
+// ApplySlice returns the items of the slice that match the conditions of the
+// read query, sorted by its sorts and paginated by its limit and offset. The
+// input slice is not modified.
+func ApplySlice[T any](qb *Query, items []T) ([]T, error) {
+	// check builder error
+	if qb.err != nil {
+		return nil, qb.err
+	}
+
+	// filter items
+	result := []T{}
+	for _, item := range items {
+		ok, err := Match(qb.conditions, item)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result = append(result, item)
+		}
+	}
+
+	// sort items
+	if err := SortSlice(result, qb.sort); err != nil {
+		return nil, err
+	}
+
+	// return page
+	return Paginate(result, qb.limit, qb.offset), nil
+}
+
+// fieldResolver resolves field values of a struct or map by DB names.
+type fieldResolver struct {
+	val     reflect.Value
+	mapping *structMapping
+}
+
+// newFieldResolver creates a field resolver for the value.
+func newFieldResolver(value any) (*fieldResolver, error) {
+	// dereference pointers
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, fmt.Errorf("unsupported match value: nil %s", v.Type())
+		}
+		v = v.Elem()
+	}
+
+	// select kind
+	switch {
+	case v.Kind() == reflect.Struct:
+		return &fieldResolver{val: v, mapping: getStructMapping(v.Type())}, nil
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		return &fieldResolver{val: v}, nil
+	}
+	return nil, fmt.Errorf("unsupported match value type: %T", value)
+}
+
+// resolve returns the value of the field, or nil for NULL.
+func (r *fieldResolver) resolve(field *domain.Field) (any, error) {
+	// names to try
+	names := []string{field.DB}
+	if i := strings.LastIndexByte(field.DB, '.'); i >= 0 {
+		names = append(names, field.DB[i+1:])
+	}
+
+	// resolve names
+	for _, name := range names {
+		// map value
+		if r.mapping == nil {
+			v := r.val.MapIndex(reflect.ValueOf(name).Convert(r.val.Type().Key()))
+			if v.IsValid() {
+				return normalizeValue(v.Interface())
+			}
+			continue
+		}
+
+		// struct field
+		col, ok := r.mapping.columns[name]
+		if !ok {
+			continue
+		}
+
+		// get field value, nil embedded pointers are null
+		v := r.val
+		for i, x := range col.index {
+			if i > 0 && v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					return nil, nil
+				}
+				v = v.Elem()
+			}
+			v = v.Field(x)
+		}
+
+		// return value
+		return normalizeValue(v.Interface())
+	}
+
+	// missing map keys are null
+	if r.mapping == nil {
+		return nil, nil
+	}
+
+	// unknown field
+	return nil, fmt.Errorf("unknown match field: %s", field.DB)
+}
+
+// evalAnd evaluates conditions joined by AND.
+func (r *fieldResolver) evalAnd(conds []domain.Condition) (truth, error) {
+	// result
+	result := truthTrue
+	for _, cond := range conds {
+		t, err := r.eval(cond)
+		if err != nil {
+			return truthFalse, err
+		}
+
+		// false wins, unknown otherwise
+		switch t {
+		case truthFalse:
+			return truthFalse, nil
+		case truthUnknown:
+			result = truthUnknown
+		}
+	}
+
+	// return result
+	return result, nil
+}
+
+// evalOr evaluates conditions joined by OR.
+func (r *fieldResolver) evalOr(conds []domain.Condition) (truth, error) {
+	// result
+	result := truthFalse
+	for _, cond := range conds {
+		t, err := r.eval(cond)
+		if err != nil {
+			return truthFalse, err
+		}
+
+		// true wins, unknown otherwise
+		switch t {
+		case truthTrue:
+			return truthTrue, nil
+		case truthUnknown:
+			result = truthUnknown
+		}
+	}
+
+	// return result
+	return result, nil
+}
+
+// eval evaluates a single condition.
+func (r *fieldResolver) eval(cond domain.Condition) (truth, error) {
+	// logical operators
+	switch cond.Operator {
+	case domain.OperatorAnd, domain.OperatorOr, domain.OperatorNot:
+		nested := cond.Value.([]domain.Condition)
+		switch cond.Operator {
+		case domain.OperatorOr:
+			return r.evalOr(nested)
+		case domain.OperatorNot:
+			t, err := r.evalAnd(nested)
+			return not(t), err
+		default:
+			return r.evalAnd(nested)
+		}
+	}
+
+	// resolve field value
+	left, err := r.resolve(cond.Field)
+	if err != nil {
+		return truthFalse, err
+	}
+
+	// null checks
+	if v, ok := cond.Value.(domain.ValueType); ok && v == domain.ValueNull {
+		switch cond.Operator {
+		case domain.OperatorEqual:
+			return toTruth(left == nil), nil
+		case domain.OperatorNotEqual:
+			return toTruth(left != nil), nil
+		}
+		return truthUnknown, nil
+	}
+
+	// in values
+	if cond.Operator == domain.OperatorIn {
+		return evalIn(left, cond.Value)
+	}
+
+	// normalize condition value
+	right, err := normalizeValue(cond.Value)
+	if err != nil {
+		return truthFalse, err
+	}
+
+	// comparisons with null are unknown
+	if left == nil || right == nil {
+		return truthUnknown, nil
+	}
+
+	// select operator
+	switch cond.Operator {
+	case domain.OperatorLike, domain.OperatorILike:
+		return evalLike(left, right, cond.Operator == domain.OperatorILike)
+	case domain.OperatorEqual, domain.OperatorNotEqual:
+		eq, err := equalValues(left, right)
+		if err != nil {
+			return truthFalse, err
+		}
+		return toTruth(eq == (cond.Operator == domain.OperatorEqual)), nil
+	}
+
+	// compare values
+	c, err := compareValues(left, right)
+	if err != nil {
+		return truthFalse, err
+	}
+
+	// select comparison
+	switch cond.Operator {
+	case domain.OperatorLessThan:
+		return toTruth(c < 0), nil
+	case domain.OperatorGreaterThan:
+		return toTruth(c > 0), nil
+	case domain.OperatorLessThanOrEqual:
+		return toTruth(c <= 0), nil
+	case domain.OperatorGreaterThanOrEqual:
+		return toTruth(c >= 0), nil
+	}
+	return truthFalse, ErrUnsupportedOperator{Op: cond.Operator}
+}
+
+// evalIn evaluates an IN condition. It is true if a value is equal, unknown if
+// no value is equal and the field or a value is NULL, and false otherwise.
+func evalIn(left, values any) (truth, error) {
+	// null field
+	if left == nil {
+		return truthUnknown, nil
+	}
+
+	// compare values
+	result := truthFalse
+	v := reflect.ValueOf(values)
+	for i := 0; i < v.Len(); i++ {
+		// normalize value
+		right, err := normalizeValue(v.Index(i).Interface())
+		if err != nil {
+			return truthFalse, err
+		}
+
+		// null value
+		if right == nil {
+			result = truthUnknown
+			continue
+		}
+
+		// check equality
+		eq, err := equalValues(left, right)
+		if err != nil {
+			return truthFalse, err
+		}
+		if eq {
+			return truthTrue, nil
+		}
+	}
+
+	// return result
+	return result, nil
+}
+
+// evalLike evaluates a LIKE or ILIKE condition.
+func evalLike(left, pattern any, insensitive bool) (truth, error) {
+	// get strings
+	s, ok := left.(string)
+	if !ok {
+		return truthFalse, fmt.Errorf("unsupported like value type: %T", left)
+	}
+	p, ok := pattern.(string)
+	if !ok {
+		return truthFalse, fmt.Errorf("unsupported like pattern type: %T", pattern)
+	}
+
+	// create regexp
+	expr := likeToRegexp(p)
+	if insensitive {
+		expr = "(?is)" + expr
+	} else {
+		expr = "(?s)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return truthFalse, err
+	}
+
+	// match string
+	return toTruth(re.MatchString(s)), nil
+}
+
+// likeToRegexp converts a LIKE pattern into an anchored regular expression.
+// Backslash escaped wildcards are matched literally.
+func likeToRegexp(pattern string) string {
+	// regexp
+	var b strings.Builder
+	b.WriteByte('^')
+
+	// convert pattern
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch c := runes[i]; {
+		case c == '\\' && i+1 < len(runes):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(runes[i])))
+		case c == '%':
+			b.WriteString(".*")
+		case c == '_':
+			b.WriteByte('.')
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	// return regexp
+	b.WriteByte('$')
+	return b.String()
+}
+
+// normalizeValue dereferences pointers and unwraps driver.Valuer values, and
+// converts numbers to int64, uint64 or float64. It returns nil for NULL.
+func normalizeValue(value any) (any, error) {
+	// unwrap valuer
+	if valuer, ok := value.(driver.Valuer); ok {
+		// check is nil pointer
+		if v := reflect.ValueOf(valuer); v.Kind() == reflect.Ptr && v.IsNil() {
+			return nil, nil
+		}
+
+		// get value
+		inner, err := valuer.Value()
+		if err != nil {
+			return nil, err
+		}
+		value = inner
+	}
+
+	// check is nil
+	v := reflect.ValueOf(value)
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	// select kind
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return normalizeValue(v.Elem().Interface())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+	}
+
+	// return value
+	return value, nil
+}
+
+// equalValues reports whether the normalized values are equal. Values that
+// cannot be ordered are compared deeply.
+func equalValues(a, b any) (bool, error) {
+	// compare ordered values
+	c, err := compareValues(a, b)
+	if err == nil {
+		return c == 0, nil
+	}
+
+	// compare deeply
+	if reflect.TypeOf(a) == reflect.TypeOf(b) {
+		return reflect.DeepEqual(a, b), nil
+	}
+	return false, err
+}
+
+// compareValues compares the normalized values and returns -1, 0 or 1. It
+// returns an error if the values cannot be compared.
+func compareValues(a, b any) (int, error) {
+	switch x := a.(type) {
+	case int64:
+		switch y := b.(type) {
+		case int64:
+			return cmp.Compare(x, y), nil
+		case uint64:
+			if x < 0 {
+				return -1, nil
+			}
+			return cmp.Compare(uint64(x), y), nil
+		case float64:
+			return cmp.Compare(float64(x), y), nil
+		}
+	case uint64:
+		switch y := b.(type) {
+		case uint64:
+			return cmp.Compare(x, y), nil
+		case int64:
+			if y < 0 {
+				return 1, nil
+			}
+			return cmp.Compare(x, uint64(y)), nil
+		case float64:
+			return cmp.Compare(float64(x), y), nil
+		}
+	case float64:
+		switch y := b.(type) {
+		case float64:
+			return cmp.Compare(x, y), nil
+		case int64:
+			return cmp.Compare(x, float64(y)), nil
+		case uint64:
+			return cmp.Compare(x, float64(y)), nil
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), nil
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			return cmp.Compare(boolToInt(x), boolToInt(y)), nil
+		}
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y), nil
+		}
+	case []byte:
+		if y, ok := b.([]byte); ok {
+			return bytes.Compare(x, y), nil
+		}
+	}
+
+	// values cannot be compared
+	return 0, fmt.Errorf("cannot compare values of types %T and %T", a, b)
+}
+
+// compareSortKeys compares the sort keys, NULL being larger than other values.
+func compareSortKeys(a, b any) (int, error) {
+	switch {
+	case a == nil && b == nil:
+		return 0, nil
+	case a == nil:
+		return 1, nil
+	case b == nil:
+		return -1, nil
+	}
+	return compareValues(a, b)
+}
+
+// boolToInt converts false to 0 and true to 1.
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// toTruth converts a boolean to a truth value.
+func toTruth(b bool) truth {
+	if b {
+		return truthTrue
+	}
+	return truthFalse
+}
+
+// not negates a truth value, unknown staying unknown.
+func not(t truth) truth {
+	switch t {
+	case truthTrue:
+		return truthFalse
+	case truthFalse:
+		return truthTrue
+	}
+	return truthUnknown
+}
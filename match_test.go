@@ -0,0 +1,216 @@
+package qbr
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+type matchAudit struct {
+	CreatedBy string `db:"created_by"`
+}
+
+type matchUser struct {
+	ID    int64          `db:"id"`
+	Name  string         `db:"name"`
+	Age   *int           `db:"age"`
+	Email sql.NullString `db:"email"`
+	*matchAudit
+}
+
+func TestMatch(t *testing.T) {
+	id := NewField(WithDB("id"))
+	name := NewField(WithDB("name"))
+	age := NewField(WithDB("age"))
+	email := NewField(WithDB("email"))
+	createdBy := NewField(WithDB("created_by"))
+
+	// values
+	eighteen := 18
+	user := matchUser{
+		ID:         1,
+		Name:       "50% off_",
+		Age:        &eighteen,
+		matchAudit: &matchAudit{CreatedBy: "admin"},
+	}
+	nullUser := matchUser{ID: 2, Name: "bob"}
+
+	tests := []struct {
+		name    string
+		conds   []domain.Condition
+		value   any
+		want    bool
+		wantErr error
+	}{
+		{name: "no conditions", value: user, want: true},
+		{name: "equal", conds: []domain.Condition{Eq(id, 1)}, value: user, want: true},
+		{name: "equal mixed number types", conds: []domain.Condition{Eq(id, uint8(1)), Eq(age, 18.0)}, value: user, want: true},
+		{name: "not equal", conds: []domain.Condition{NoEq(id, 1)}, value: user, want: false},
+		{name: "comparisons", conds: []domain.Condition{Gt(age, 17), GtOrEq(age, 18), Lt(age, 19), LtOrEq(age, 18)}, value: user, want: true},
+		{name: "pointer value", conds: []domain.Condition{Eq(name, "bob")}, value: &nullUser, want: true},
+		{name: "map value", conds: []domain.Condition{Eq(name, "a"), Gt(age, 1)}, value: map[string]any{"name": "a", "age": 2}, want: true},
+		{name: "missing map key is null", conds: []domain.Condition{Eq(email, domain.ValueNull), Not(Eq(email, "a"))}, value: map[string]any{"name": "a"}, want: false},
+		{name: "missing map key is null check", conds: []domain.Condition{Eq(email, domain.ValueNull)}, value: map[string]any{"name": "a"}, want: true},
+		{name: "qualified field", conds: []domain.Condition{Eq(NewField(WithDB("users.id")), 1)}, value: user, want: true},
+
+		// null semantics
+		{name: "is null", conds: []domain.Condition{Eq(age, domain.ValueNull), Eq(email, domain.ValueNull)}, value: nullUser, want: true},
+		{name: "is not null", conds: []domain.Condition{NoEq(age, domain.ValueNull)}, value: user, want: true},
+		{name: "comparison with null is unknown", conds: []domain.Condition{Gt(age, 1)}, value: nullUser, want: false},
+		{name: "not of unknown is unknown", conds: []domain.Condition{Not(Gt(age, 1))}, value: nullUser, want: false},
+		{name: "not equal null field is unknown", conds: []domain.Condition{NoEq(age, 1)}, value: nullUser, want: false},
+		{name: "unknown or true is true", conds: []domain.Condition{Or(Gt(age, 1), Eq(id, 2))}, value: nullUser, want: true},
+		{name: "unknown or false is unknown", conds: []domain.Condition{Not(Or(Gt(age, 1), Eq(id, 3)))}, value: nullUser, want: false},
+		{name: "unknown and false is false", conds: []domain.Condition{Not(And(Gt(age, 1), Eq(id, 3)))}, value: nullUser, want: true},
+		{name: "nil embedded pointer is null", conds: []domain.Condition{Eq(createdBy, domain.ValueNull)}, value: nullUser, want: true},
+		{name: "valuer", conds: []domain.Condition{Eq(email, "a@b.c")}, value: matchUser{Email: sql.NullString{String: "a@b.c", Valid: true}}, want: true},
+
+		// in
+		{name: "in", conds: []domain.Condition{In(id, 3, 1)}, value: user, want: true},
+		{name: "not in", conds: []domain.Condition{Not(In(id, 2, 3))}, value: user, want: true},
+		{name: "in with null value matches", conds: []domain.Condition{In(id, nil, 1)}, value: user, want: true},
+		{name: "not in with null value is unknown", conds: []domain.Condition{Not(In(id, nil, 2))}, value: user, want: false},
+		{name: "in null field is unknown", conds: []domain.Condition{Not(In(age, 1))}, value: nullUser, want: false},
+
+		// like
+		{name: "like", conds: []domain.Condition{Like(name, "50%")}, value: user, want: true},
+		{name: "like single character", conds: []domain.Condition{Like(name, "_0% off_")}, value: user, want: true},
+		{name: "like escaped percent", conds: []domain.Condition{Like(name, `50\% off\_`)}, value: user, want: true},
+		{name: "like escaped no match", conds: []domain.Condition{Like(name, `5\%`)}, value: user, want: false},
+		{name: "like escape like", conds: []domain.Condition{Like(name, EscapeLike("50% off_"))}, value: user, want: true},
+		{name: "like is case sensitive", conds: []domain.Condition{Like(name, "BOB")}, value: nullUser, want: false},
+		{name: "ilike", conds: []domain.Condition{ILike(name, "B%")}, value: nullUser, want: true},
+
+		// embedded structs
+		{name: "embedded field", conds: []domain.Condition{Eq(createdBy, "admin")}, value: user, want: true},
+
+		// errors
+		{name: "unknown field", conds: []domain.Condition{Eq(NewField(WithDB("unknown")), 1)}, value: user, wantErr: errAny},
+		{name: "incomparable types", conds: []domain.Condition{Gt(name, 1)}, value: user, wantErr: errAny},
+		{name: "empty in", conds: []domain.Condition{In(id)}, value: user, wantErr: ErrEmptyIn},
+		{name: "unsupported value", conds: []domain.Condition{Eq(id, 1)}, value: 1, wantErr: errAny},
+		{name: "nil pointer value", conds: []domain.Condition{Eq(id, 1)}, value: (*matchUser)(nil), wantErr: errAny},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Match(tt.conds, tt.value)
+			if tt.wantErr != nil {
+				if err == nil || (tt.wantErr != errAny && !errors.Is(err, tt.wantErr)) {
+					t.Fatalf("Match() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Match() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortSlice(t *testing.T) {
+	name := NewField(WithDB("name"))
+	age := NewField(WithDB("age"))
+
+	// values
+	one, two := 1, 2
+	users := []matchUser{
+		{ID: 1, Name: "b", Age: &two},
+		{ID: 2, Name: "a"},
+		{ID: 3, Name: "a", Age: &one},
+		{ID: 4, Name: "b", Age: &one},
+	}
+
+	tests := []struct {
+		name    string
+		sorts   []domain.Sort
+		want    []int64
+		wantErr bool
+	}{
+		{name: "no sorts", want: []int64{1, 2, 3, 4}},
+		{name: "ascending nulls last", sorts: []domain.Sort{*NewSortAsc(age)}, want: []int64{3, 4, 1, 2}},
+		{name: "descending nulls first", sorts: []domain.Sort{*NewSortDesc(age)}, want: []int64{2, 1, 3, 4}},
+		{name: "stable", sorts: []domain.Sort{*NewSortAsc(name)}, want: []int64{2, 3, 1, 4}},
+		{name: "multiple sorts", sorts: []domain.Sort{*NewSortDesc(name), *NewSortAsc(age)}, want: []int64{4, 1, 3, 2}},
+		{name: "unknown field", sorts: []domain.Sort{*NewSortAsc(NewField(WithDB("unknown")))}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := append([]matchUser(nil), users...)
+			err := SortSlice(s, tt.sorts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SortSlice() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var ids []int64
+			for _, u := range s {
+				ids = append(ids, u.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("SortSlice() ids = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	s := []int{1, 2, 3, 4, 5}
+
+	tests := []struct {
+		name   string
+		limit  uint64
+		offset uint64
+		want   []int
+	}{
+		{name: "no limit", want: []int{1, 2, 3, 4, 5}},
+		{name: "limit", limit: 2, want: []int{1, 2}},
+		{name: "offset", offset: 3, want: []int{4, 5}},
+		{name: "limit and offset", limit: 2, offset: 1, want: []int{2, 3}},
+		{name: "limit past end", limit: 10, offset: 4, want: []int{5}},
+		{name: "offset past end", limit: 2, offset: 5, want: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Paginate(s, tt.limit, tt.offset); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Paginate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplySlice(t *testing.T) {
+	id := NewField(WithDB("id"))
+	name := NewField(WithDB("name"))
+
+	// values
+	users := []matchUser{{ID: 1, Name: "b"}, {ID: 2, Name: "a"}, {ID: 3, Name: "c"}, {ID: 4, Name: "a"}}
+
+	// apply query
+	qb := NewRead().Where(NoEq(id, 3)).Sort(NewSortAsc(name), NewSortDesc(id)).Limit(2).Offset(1)
+	got, err := ApplySlice(qb, users)
+	if err != nil {
+		t.Fatalf("ApplySlice() error = %v", err)
+	}
+
+	// check result
+	var ids []int64
+	for _, u := range got {
+		ids = append(ids, u.ID)
+	}
+	if want := []int64{2, 1}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("ApplySlice() ids = %v, want %v", ids, want)
+	}
+	if users[0].ID != 1 || users[2].ID != 3 {
+		t.Errorf("ApplySlice() modified the input slice")
+	}
+}
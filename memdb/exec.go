@@ -0,0 +1,278 @@
+package memdb
+
+import (
+	"fmt"
+
+	"github.com/tyrenix/qbr"
+	"github.com/tyrenix/qbr/domain"
+)
+
+// tables maps table names to rows in insertion order.
+type tables map[string][]Row
+
+// isRead reports whether the query is a read query.
+func isRead(qb *qbr.Query) bool {
+	return qb.GetOperation() == domain.OperationRead
+}
+
+// exec executes the query against the table.
+func (t tables) exec(table string, qb *qbr.Query) (*Result, error) {
+	// validate query
+	if err := qb.Validate(); err != nil {
+		return nil, err
+	}
+
+	// check unsupported features
+	if qb.GetSuffix() != "" {
+		return nil, fmt.Errorf("suffix is not supported by memdb")
+	}
+	if qb.GetSample() > 0 {
+		return nil, fmt.Errorf("sample is not supported by memdb")
+	}
+	if n, _ := qb.GetLimitBy(); n > 0 {
+		return nil, fmt.Errorf("limit by is not supported by memdb")
+	}
+
+	// select operation
+	switch qb.GetOperation() {
+	case domain.OperationCreate:
+		return t.execInsert(table, qb)
+	case domain.OperationRead:
+		return t.execSelect(table, qb)
+	case domain.OperationUpdate:
+		return t.execUpdate(table, qb)
+	case domain.OperationDelete:
+		return t.execDelete(table, qb)
+	default:
+		return nil, fmt.Errorf("unsupported query type: %v", qb.GetOperation())
+	}
+}
+
+// execInsert inserts a row from the query data.
+func (t tables) execInsert(table string, qb *qbr.Query) (*Result, error) {
+	// create row
+	row := Row{}
+	for _, d := range qb.GetData() {
+		// check modification
+		if _, ok := d.Value.(*domain.Modification); ok {
+			return nil, fmt.Errorf("modification is not supported by insert queries")
+		}
+
+		// set value
+		value, err := storeValue(d.Value)
+		if err != nil {
+			return nil, err
+		}
+		row[d.Field.DB] = value
+	}
+
+	// insert row
+	if err := t.insert(table, row); err != nil {
+		return nil, err
+	}
+
+	// return result
+	return returningResult(qb, []Row{row})
+}
+
+// execSelect selects the rows matching the query.
+func (t tables) execSelect(table string, qb *qbr.Query) (*Result, error) {
+	// filter rows
+	indexes, err := t.match(table, qb)
+	if err != nil {
+		return nil, err
+	}
+	rows := copyRows(t.rows(table, indexes))
+
+	// aggregate rows
+	selects := qb.GetSelects()
+	if hasAggregation(selects) {
+		row, err := aggregate(rows, selects)
+		if err != nil {
+			return nil, err
+		}
+		rows = qbr.Paginate([]Row{row}, qb.GetLimit(), qb.GetOffset())
+		return &Result{Rows: rows}, nil
+	}
+
+	// sort rows
+	if err := qbr.SortSlice(rows, qb.GetSort()); err != nil {
+		return nil, err
+	}
+
+	// paginate rows
+	rows = qbr.Paginate(rows, qb.GetLimit(), qb.GetOffset())
+
+	// return projected rows
+	return &Result{Rows: project(rows, selects)}, nil
+}
+
+// execUpdate applies the query data to the matching rows.
+func (t tables) execUpdate(table string, qb *qbr.Query) (*Result, error) {
+	// get matching rows
+	indexes, err := t.match(table, qb)
+	if err != nil {
+		return nil, err
+	}
+	rows := t.rows(table, qbr.Paginate(indexes, qb.GetMaxAffected(), 0))
+
+	// compute values from the rows before the update
+	data := qb.GetData()
+	values := make([]map[string]any, len(rows))
+	for i, row := range rows {
+		values[i] = make(map[string]any, len(data))
+		for _, d := range data {
+			value, err := computeValue(row, d.Value)
+			if err != nil {
+				return nil, err
+			}
+			values[i][d.Field.DB] = value
+		}
+	}
+
+	// update rows
+	for i, row := range rows {
+		for column, value := range values[i] {
+			row[column] = value
+		}
+	}
+
+	// return result
+	return returningResult(qb, rows)
+}
+
+// execDelete removes the matching rows.
+func (t tables) execDelete(table string, qb *qbr.Query) (*Result, error) {
+	// get matching rows
+	indexes, err := t.match(table, qb)
+	if err != nil {
+		return nil, err
+	}
+	indexes = qbr.Paginate(indexes, qb.GetMaxAffected(), 0)
+
+	// remove rows
+	removed := make(map[int]bool, len(indexes))
+	for _, i := range indexes {
+		removed[i] = true
+	}
+	var rows, kept []Row
+	for i, row := range t[table] {
+		if removed[i] {
+			rows = append(rows, row)
+			continue
+		}
+		kept = append(kept, row)
+	}
+	if kept == nil {
+		kept = []Row{}
+	}
+	t[table] = kept
+
+	// return result
+	return returningResult(qb, rows)
+}
+
+// match returns the indexes of the rows of the table matching the query
+// conditions, including ClickHouse PREWHERE conditions. A table that does not
+// exist has no rows.
+func (t tables) match(table string, qb *qbr.Query) ([]int, error) {
+	// conditions
+	conds := append(qb.GetPrewhere(), qb.GetConditions()...)
+
+	// filter rows
+	var indexes []int
+	for i, row := range t[table] {
+		ok, err := qbr.Match(conds, map[string]any(row))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			indexes = append(indexes, i)
+		}
+	}
+
+	// return indexes
+	return indexes, nil
+}
+
+// rows returns the rows of the table at the indexes. The rows are shared with
+// the table.
+func (t tables) rows(table string, indexes []int) []Row {
+	rows := make([]Row, len(indexes))
+	for i, index := range indexes {
+		rows[i] = t[table][index]
+	}
+	return rows
+}
+
+// insert appends the rows to the table, creating the table if it does not
+// exist. The values are stored as by storeValue, and no row is inserted if
+// any value is not supported.
+func (t tables) insert(table string, rows ...Row) error {
+	// store values
+	stored := make([]Row, len(rows))
+	for i, row := range rows {
+		stored[i] = make(Row, len(row))
+		for column, value := range row {
+			v, err := storeValue(value)
+			if err != nil {
+				return fmt.Errorf("column %s: %w", column, err)
+			}
+			stored[i][column] = v
+		}
+	}
+
+	// append rows
+	if _, ok := t[table]; !ok {
+		t[table] = []Row{}
+	}
+	t[table] = append(t[table], stored...)
+	return nil
+}
+
+// copy returns a deep copy of the tables.
+func (t tables) copy() tables {
+	c := make(tables, len(t))
+	for name, rows := range t {
+		c[name] = copyRows(rows)
+	}
+	return c
+}
+
+// returningResult creates the result of a write query affecting the rows,
+// with the rows projected by the query's returning fields.
+func returningResult(qb *qbr.Query, rows []Row) (*Result, error) {
+	// result
+	result := &Result{Affected: int64(len(rows))}
+
+	// project returning fields
+	if returning := qb.GetReturning(); len(returning) > 0 {
+		if hasAggregation(returning) {
+			return nil, fmt.Errorf("aggregation is not supported in returning")
+		}
+		result.Rows = project(rows, returning)
+	}
+
+	// return result
+	return result, nil
+}
+
+// copyRows returns copies of the rows.
+func copyRows(rows []Row) []Row {
+	// check is nil
+	if rows == nil {
+		return nil
+	}
+
+	// copy rows
+	result := make([]Row, len(rows))
+	for i, row := range rows {
+		result[i] = make(Row, len(row))
+		for k, v := range row {
+			result[i][k] = v
+		}
+	}
+
+	// return rows
+	return result
+}
@@ -0,0 +1,189 @@
+// Package memdb provides an in-memory fake database that executes qbr queries
+// directly, without building or parsing SQL. It is intended for unit tests of
+// code that builds queries.
+package memdb
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/tyrenix/qbr"
+)
+
+// ErrTxDone is returned by operations on a transaction that has already been
+// committed or rolled back.
+var ErrTxDone = errors.New("transaction has already been committed or rolled back")
+
+// ErrTxConflict is returned by Commit when the database was changed after the
+// transaction began. The changes of the transaction are discarded.
+var ErrTxConflict = errors.New("transaction conflicts with a concurrent change")
+
+// Row is a table row, mapping column names to values.
+type Row map[string]any
+
+// Result is the result of an executed query.
+type Result struct {
+	Rows     []Row // Selected rows, or RETURNING rows of write queries.
+	Affected int64 // Number of inserted, updated or deleted rows.
+}
+
+// DB is an in-memory database of tables keyed by name. It is safe for
+// concurrent use.
+type DB struct {
+	mu      sync.RWMutex
+	tables  tables
+	version uint64 // incremented by every change
+}
+
+// New creates a new empty database.
+func New() *DB {
+	return &DB{tables: tables{}}
+}
+
+// Exec executes the query against the table and returns its result.
+//
+// Create queries insert a row from the query data, creating the table if it
+// does not exist. Read queries select the rows that match the conditions,
+// sorted, paginated and projected by the select fields, or a single row of
+// aggregates. Update queries apply the data, including modifications, to the
+// matching rows, and delete queries remove them. Write queries return the rows
+// selected by RETURNING, taken from the query's returning fields.
+//
+// The query is validated as by ToSql, so update and delete queries without
+// conditions fail with qbr.ErrFullTable unless AllowFullTable is set.
+func (db *DB) Exec(table string, qb *qbr.Query) (*Result, error) {
+	// lock by operation
+	if isRead(qb) {
+		db.mu.RLock()
+		defer db.mu.RUnlock()
+		return db.tables.exec(table, qb)
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	// execute query
+	result, err := db.tables.exec(table, qb)
+	if err != nil {
+		return nil, err
+	}
+
+	// return result
+	db.version++
+	return result, nil
+}
+
+// Insert inserts the rows into the table, creating the table if it does not
+// exist. It is intended for seeding test data. Values are stored as query data
+// is, so plain ints or pointers can be seeded; it returns an error if a value
+// is not supported.
+func (db *DB) Insert(table string, rows ...Row) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	// insert rows
+	if err := db.tables.insert(table, rows...); err != nil {
+		return err
+	}
+	db.version++
+	return nil
+}
+
+// Rows returns a copy of all rows of the table in insertion order, or nil if
+// the table does not exist.
+func (db *DB) Rows(table string) []Row {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return copyRows(db.tables[table])
+}
+
+// Snapshot returns a copy of the current contents of the database, which can
+// be restored with Restore.
+func (db *DB) Snapshot() *Snapshot {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return &Snapshot{tables: db.tables.copy()}
+}
+
+// Restore replaces the contents of the database with the snapshot.
+func (db *DB) Restore(s *Snapshot) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.tables = s.tables.copy()
+	db.version++
+}
+
+// Begin starts a transaction on a snapshot of the database. Changes made in
+// the transaction are not visible outside of it until it is committed.
+//
+// Transactions are optimistic: the database is not locked while a transaction
+// is open, and Commit fails with ErrTxConflict if the database was changed
+// after Begin, so no committed change is lost. A transaction should be ended
+// with a deferred Rollback, which does nothing after Commit:
+//
+//	tx := db.Begin()
+//	defer tx.Rollback()
+func (db *DB) Begin() *Tx {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return &Tx{db: db, tables: db.tables.copy(), version: db.version}
+}
+
+// Snapshot is a copy of the contents of a database.
+type Snapshot struct {
+	tables tables
+}
+
+// Tx is an in-memory transaction. It is not safe for concurrent use.
+type Tx struct {
+	db      *DB
+	tables  tables
+	version uint64 // database version at Begin
+	done    bool
+}
+
+// Exec executes the query in the transaction, as DB.Exec does.
+func (tx *Tx) Exec(table string, qb *qbr.Query) (*Result, error) {
+	// check is done
+	if tx.done {
+		return nil, ErrTxDone
+	}
+
+	// execute query
+	return tx.tables.exec(table, qb)
+}
+
+// Commit replaces the contents of the database with the transaction's
+// snapshot. It returns ErrTxConflict and discards the changes if the database
+// was changed after the transaction began.
+func (tx *Tx) Commit() error {
+	// check is done
+	if tx.done {
+		return ErrTxDone
+	}
+	tx.done = true
+
+	// lock database
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+
+	// check version
+	tables := tx.tables
+	tx.tables = nil
+	if tx.db.version != tx.version {
+		return ErrTxConflict
+	}
+
+	// replace tables
+	tx.db.tables = tables
+	tx.db.version++
+	return nil
+}
+
+// Rollback discards the changes made in the transaction. It does nothing if
+// the transaction has already been committed or rolled back.
+func (tx *Tx) Rollback() error {
+	// discard tables
+	tx.done = true
+	tx.tables = nil
+	return nil
+}
@@ -0,0 +1,360 @@
+package memdb
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/tyrenix/qbr"
+	"github.com/tyrenix/qbr/domain"
+)
+
+var (
+	id    = qbr.NewField(qbr.WithDB("id"))
+	name  = qbr.NewField(qbr.WithDB("name"))
+	age   = qbr.NewField(qbr.WithDB("age"))
+	email = qbr.NewField(qbr.WithDB("email"))
+)
+
+// seed returns a database with users seeded without the email column.
+func seed(t *testing.T) *DB {
+	t.Helper()
+	db := New()
+	err := db.Insert("users",
+		Row{"id": int64(1), "name": "a", "age": int64(30)},
+		Row{"id": int64(2), "name": "b", "age": int64(20)},
+		Row{"id": int64(3), "name": "c", "age": nil},
+		Row{"id": int64(4), "name": "d", "age": int64(40), "email": "d@x"},
+	)
+	if err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+	return db
+}
+
+func TestExec(t *testing.T) {
+	tests := []struct {
+		name     string
+		qb       *qbr.Query
+		want     *Result
+		wantRows []Row
+		wantErr  error
+	}{
+		{
+			name: "select",
+			qb:   qbr.NewRead().Select(id, name).Where(qbr.Gt(age, 25)).Sort(qbr.NewSortDesc(age)),
+			want: &Result{Rows: []Row{{"id": int64(4), "name": "d"}, {"id": int64(1), "name": "a"}}},
+		},
+		{
+			name: "select paginated",
+			qb:   qbr.NewRead().Select(id).Sort(qbr.NewSortAsc(id)).Limit(2).Offset(1),
+			want: &Result{Rows: []Row{{"id": int64(2)}, {"id": int64(3)}}},
+		},
+		{
+			name: "missing column is null",
+			qb:   qbr.NewRead().Select(id, email).Where(qbr.Eq(email, domain.ValueNull), qbr.Lt(id, 3)),
+			want: &Result{Rows: []Row{{"id": int64(1), "email": nil}, {"id": int64(2), "email": nil}}},
+		},
+		{
+			name: "missing column comparison is unknown",
+			qb:   qbr.NewRead().Select(id).Where(qbr.Eq(email, "d@x")),
+			want: &Result{Rows: []Row{{"id": int64(4)}}},
+		},
+		{
+			name: "sort by missing column",
+			qb:   qbr.NewRead().Select(id).Sort(qbr.NewSortAsc(email), qbr.NewSortAsc(id)),
+			want: &Result{Rows: []Row{{"id": int64(4)}, {"id": int64(1)}, {"id": int64(2)}, {"id": int64(3)}}},
+		},
+		{
+			name: "aggregates",
+			qb: qbr.NewRead().Select(
+				qbr.NewCountField(qbr.NewAllField()),
+				qbr.NewCountField(age),
+				qbr.NewCountField(email),
+				qbr.NewSumField(age),
+				qbr.NewUniqField(name),
+			),
+			want: &Result{Rows: []Row{{
+				"count(*)":     int64(4),
+				"count(age)":   int64(3),
+				"count(email)": int64(1),
+				"sum(age)":     int64(90),
+				"uniq(name)":   uint64(4),
+			}}},
+		},
+		{
+			name: "quantiles",
+			qb:   qbr.NewRead().Select(qbr.NewQuantileField(age, 0), qbr.NewQuantileField(age, 0.9)),
+			want: &Result{Rows: []Row{{
+				"quantile(age)":      30.0,
+				"quantile(0.9)(age)": 38.0,
+			}}},
+		},
+		{
+			name: "quantile of no values",
+			qb:   qbr.NewRead().Select(qbr.NewQuantileField(age, 0.5)).Where(qbr.Eq(age, domain.ValueNull)),
+			want: &Result{Rows: []Row{{"quantile(0.5)(age)": nil}}},
+		},
+		{
+			name: "insert",
+			qb:   qbr.NewCreate().Set(qbr.NewData(id, 5), qbr.NewData(name, "e")).Returning(id),
+			want: &Result{Rows: []Row{{"id": int64(5)}}, Affected: 1},
+			wantRows: []Row{
+				{"id": int64(1), "name": "a", "age": int64(30)},
+				{"id": int64(2), "name": "b", "age": int64(20)},
+				{"id": int64(3), "name": "c", "age": nil},
+				{"id": int64(4), "name": "d", "age": int64(40), "email": "d@x"},
+				{"id": int64(5), "name": "e"},
+			},
+		},
+		{
+			name: "update",
+			qb:   qbr.NewUpdate().Set(qbr.NewData(age, qbr.Add(age, 1)), qbr.NewData(email, "x")).Where(qbr.In(id, 1, 3)).NoReturning(),
+			want: &Result{Affected: 2},
+			wantRows: []Row{
+				{"id": int64(1), "name": "a", "age": int64(31), "email": "x"},
+				{"id": int64(2), "name": "b", "age": int64(20)},
+				{"id": int64(3), "name": "c", "age": nil, "email": "x"},
+				{"id": int64(4), "name": "d", "age": int64(40), "email": "d@x"},
+			},
+		},
+		{
+			name: "delete",
+			qb:   qbr.NewDelete().Where(qbr.LtOrEq(age, 30)).Returning(name),
+			want: &Result{Rows: []Row{{"name": "a"}, {"name": "b"}}, Affected: 2},
+			wantRows: []Row{
+				{"id": int64(3), "name": "c", "age": nil},
+				{"id": int64(4), "name": "d", "age": int64(40), "email": "d@x"},
+			},
+		},
+		{name: "update full table", qb: qbr.NewUpdate().Set(qbr.NewData(name, "x")), wantErr: qbr.ErrFullTable},
+		{name: "quantile of strings", qb: qbr.NewRead().Select(qbr.NewQuantileField(name, 0.5)), wantErr: errAny},
+		{name: "grouping", qb: qbr.NewRead().Select(name, qbr.NewCountField(id)), wantErr: errAny},
+		{name: "suffix", qb: qbr.NewRead().Suffix("FOR UPDATE"), wantErr: errAny},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := seed(t)
+			got, err := db.Exec("users", tt.qb)
+			if tt.wantErr != nil {
+				if err == nil || (tt.wantErr != errAny && !errors.Is(err, tt.wantErr)) {
+					t.Fatalf("Exec() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exec() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Exec() = %#v, want %#v", got, tt.want)
+			}
+			if tt.wantRows != nil {
+				if rows := db.Rows("users"); !reflect.DeepEqual(rows, tt.wantRows) {
+					t.Errorf("Rows() = %#v, want %#v", rows, tt.wantRows)
+				}
+			}
+		})
+	}
+}
+
+func TestInsert(t *testing.T) {
+	n := 30
+
+	tests := []struct {
+		name     string
+		rows     []Row
+		qb       *qbr.Query
+		want     *Result
+		wantRows []Row
+		wantErr  error
+	}{
+		{
+			name:     "plain ints",
+			rows:     []Row{{"id": 1, "age": int32(30)}, {"id": uint8(2), "age": 20}},
+			qb:       qbr.NewRead().Select(id).Where(qbr.Eq(age, 30)),
+			want:     &Result{Rows: []Row{{"id": int64(1)}}},
+			wantRows: []Row{{"id": int64(1), "age": int64(30)}, {"id": uint64(2), "age": int64(20)}},
+		},
+		{
+			name:     "pointers and null",
+			rows:     []Row{{"id": 1, "age": &n, "name": domain.ValueNull}},
+			qb:       qbr.NewRead().Select(id).Where(qbr.Eq(name, domain.ValueNull)),
+			want:     &Result{Rows: []Row{{"id": int64(1)}}},
+			wantRows: []Row{{"id": int64(1), "age": int64(30), "name": nil}},
+		},
+		{
+			name:    "unsupported value",
+			rows:    []Row{{"id": 1}, {"id": domain.ValueType(100)}},
+			wantErr: errAny,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := New()
+
+			// seed rows
+			err := db.Insert("users", tt.rows...)
+			if tt.wantErr != nil {
+				if err == nil {
+					t.Fatalf("Insert() error = nil, want %v", tt.wantErr)
+				}
+				if rows := db.Rows("users"); rows != nil {
+					t.Errorf("Rows() = %#v, want nil", rows)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Insert() error = %v", err)
+			}
+
+			// check stored rows
+			if rows := db.Rows("users"); !reflect.DeepEqual(rows, tt.wantRows) {
+				t.Errorf("Rows() = %#v, want %#v", rows, tt.wantRows)
+			}
+
+			// query seeded rows
+			got, err := db.Exec("users", tt.qb)
+			if err != nil {
+				t.Fatalf("Exec() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Exec() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTx(t *testing.T) {
+	tests := []struct {
+		name   string
+		commit bool
+		want   int
+	}{
+		{name: "commit", commit: true, want: 5},
+		{name: "rollback", commit: false, want: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := seed(t)
+
+			// insert in transaction
+			tx := db.Begin()
+			if _, err := tx.Exec("users", qbr.NewCreate().Set(qbr.NewData(id, 5))); err != nil {
+				t.Fatalf("Exec() error = %v", err)
+			}
+
+			// end transaction
+			var err error
+			if tt.commit {
+				err = tx.Commit()
+			} else {
+				err = tx.Rollback()
+			}
+			if err != nil {
+				t.Fatalf("end transaction error = %v", err)
+			}
+
+			// check rows
+			if got := len(db.Rows("users")); got != tt.want {
+				t.Errorf("len(Rows()) = %d, want %d", got, tt.want)
+			}
+
+			// check done transaction
+			if _, err := tx.Exec("users", qbr.NewRead()); !errors.Is(err, ErrTxDone) {
+				t.Errorf("Exec() error = %v, want %v", err, ErrTxDone)
+			}
+			if err := tx.Commit(); !errors.Is(err, ErrTxDone) {
+				t.Errorf("Commit() error = %v, want %v", err, ErrTxDone)
+			}
+			if err := tx.Rollback(); err != nil {
+				t.Errorf("Rollback() error = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestTxConflict(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(db *DB) error
+		want    int
+		wantErr error
+	}{
+		{
+			name: "concurrent read",
+			change: func(db *DB) error {
+				_, err := db.Exec("users", qbr.NewRead())
+				return err
+			},
+			want: 5,
+		},
+		{
+			name: "concurrent write",
+			change: func(db *DB) error {
+				_, err := db.Exec("users", qbr.NewCreate().Set(qbr.NewData(id, 6)))
+				return err
+			},
+			want:    5,
+			wantErr: ErrTxConflict,
+		},
+		{
+			name: "concurrent transaction",
+			change: func(db *DB) error {
+				tx := db.Begin()
+				defer tx.Rollback()
+				if _, err := tx.Exec("users", qbr.NewDelete().Where(qbr.Eq(id, 1))); err != nil {
+					return err
+				}
+				return tx.Commit()
+			},
+			want:    3,
+			wantErr: ErrTxConflict,
+		},
+		{
+			name: "failed write",
+			change: func(db *DB) error {
+				_, err := db.Exec("users", qbr.NewDelete())
+				if !errors.Is(err, qbr.ErrFullTable) {
+					return fmt.Errorf("Exec() error = %v, want %v", err, qbr.ErrFullTable)
+				}
+				return nil
+			},
+			want: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := seed(t)
+
+			// insert in transaction
+			tx := db.Begin()
+			defer tx.Rollback()
+			if _, err := tx.Exec("users", qbr.NewCreate().Set(qbr.NewData(id, 5))); err != nil {
+				t.Fatalf("Exec() error = %v", err)
+			}
+
+			// change database while the transaction is open
+			if err := tt.change(db); err != nil {
+				t.Fatalf("change error = %v", err)
+			}
+
+			// commit transaction
+			if err := tx.Commit(); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Commit() error = %v, want %v", err, tt.wantErr)
+			}
+
+			// check rows
+			if got := len(db.Rows("users")); got != tt.want {
+				t.Errorf("len(Rows()) = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// errAny matches any non-nil error in test cases.
+var errAny = errors.New("any error")
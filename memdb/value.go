@@ -0,0 +1,366 @@
+package memdb
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+// storeValue converts the value into the form it is stored in, as a database
+// driver returns it: domain.ValueNull and nil pointers become nil, pointers
+// to scalars are dereferenced, and numbers become int64, uint64 or float64.
+func storeValue(value any) (any, error) {
+	// null value
+	if v, ok := value.(domain.ValueType); ok {
+		if v == domain.ValueNull {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unsupported value type: %d", v)
+	}
+
+	// check is nil
+	v := reflect.ValueOf(value)
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	// select kind
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if v.Elem().Kind() == reflect.Struct {
+			return value, nil
+		}
+		return storeValue(v.Elem().Interface())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	}
+
+	// return value
+	return value, nil
+}
+
+// computeValue computes the new value of a column from the data value and the
+// row before the update. Modifications are applied to the value of their field
+// in the row, and NULL operands give NULL.
+func computeValue(row Row, value any) (any, error) {
+	// plain value
+	mod, ok := value.(*domain.Modification)
+	if !ok {
+		return storeValue(value)
+	}
+
+	// get operands
+	left, err := storeValue(row[mod.Field.DB])
+	if err != nil {
+		return nil, err
+	}
+	right, err := storeValue(mod.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	// null operands
+	if left == nil || right == nil {
+		return nil, nil
+	}
+
+	// float arithmetic
+	lf, lok := left.(float64)
+	rf, rok := right.(float64)
+	if lok || rok {
+		if !lok {
+			if lf, lok = toFloat(left); !lok {
+				return nil, fmt.Errorf("unsupported modification operand type: %T", left)
+			}
+		}
+		if !rok {
+			if rf, rok = toFloat(right); !rok {
+				return nil, fmt.Errorf("unsupported modification operand type: %T", right)
+			}
+		}
+		return applyFloat(mod.Operator, lf, rf)
+	}
+
+	// integer arithmetic
+	li, lok := toInt(left)
+	ri, rok := toInt(right)
+	if !lok || !rok {
+		return nil, fmt.Errorf("unsupported modification operand types: %T and %T", left, right)
+	}
+	return applyInt(mod.Operator, li, ri)
+}
+
+// applyFloat applies the float modification.
+func applyFloat(op domain.ModificationType, a, b float64) (any, error) {
+	switch op {
+	case domain.ModificationAdd:
+		return a + b, nil
+	case domain.ModificationSubtract:
+		return a - b, nil
+	case domain.ModificationMultiply:
+		return a * b, nil
+	case domain.ModificationDivide:
+		if b == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return a / b, nil
+	}
+	return nil, fmt.Errorf("unsupported modification operator for float values: %d", op)
+}
+
+// applyInt applies the integer modification. Division truncates.
+func applyInt(op domain.ModificationType, a, b int64) (any, error) {
+	switch op {
+	case domain.ModificationAdd:
+		return a + b, nil
+	case domain.ModificationSubtract:
+		return a - b, nil
+	case domain.ModificationMultiply:
+		return a * b, nil
+	case domain.ModificationDivide:
+		if b == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return a / b, nil
+	case domain.ModificationBitwiseAnd:
+		return a & b, nil
+	case domain.ModificationBitwiseOr:
+		return a | b, nil
+	case domain.ModificationBitwiseXor:
+		return a ^ b, nil
+	case domain.ModificationShiftLeft:
+		return a << b, nil
+	case domain.ModificationShiftRight:
+		return a >> b, nil
+	}
+	return nil, fmt.Errorf("unsupported modification operator: %d", op)
+}
+
+// toInt converts a stored integer to int64.
+func toInt(v any) (int64, bool) {
+	switch x := v.(type) {
+	case int64:
+		return x, true
+	case uint64:
+		return int64(x), true
+	}
+	return 0, false
+}
+
+// toFloat converts a stored number to float64.
+func toFloat(v any) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case int64:
+		return float64(x), true
+	case uint64:
+		return float64(x), true
+	}
+	return 0, false
+}
+
+// hasAggregation reports whether any of the fields is aggregated.
+func hasAggregation(fields []domain.Field) bool {
+	for _, field := range fields {
+		if field.Aggregation != domain.AggregationNone {
+			return true
+		}
+	}
+	return false
+}
+
+// project returns copies of the rows with only the columns of the fields.
+// Selecting "*" keeps all columns, and columns missing in a row are NULL.
+func project(rows []Row, fields []domain.Field) []Row {
+	// result
+	result := make([]Row, len(rows))
+	for i, row := range rows {
+		result[i] = Row{}
+		for _, field := range fields {
+			// all columns
+			if field.DB == "*" {
+				for k, v := range row {
+					result[i][k] = v
+				}
+				continue
+			}
+
+			// column
+			result[i][field.DB] = row[field.DB]
+		}
+	}
+
+	// return rows
+	return result
+}
+
+// aggregate returns a single row with the aggregates of the rows. Columns are
+// named after the lower-case aggregate expression, such as "count(*)",
+// "sum(amount)" or "quantile(0.9)(latency)", so that several aggregates of
+// the same function do not collide. Non-aggregated fields are not supported,
+// as there is no grouping.
+func aggregate(rows []Row, fields []domain.Field) (Row, error) {
+	// result
+	result := Row{}
+	for _, field := range fields {
+		// select aggregation
+		name := aggregateName(field)
+		switch field.Aggregation {
+		case domain.AggregationCount:
+			// count non null values
+			var count int64
+			for _, row := range rows {
+				if field.DB == "*" || row[field.DB] != nil {
+					count++
+				}
+			}
+			result[name] = count
+		case domain.AggregationSum:
+			// sum values
+			sum, err := sumColumn(rows, field.DB)
+			if err != nil {
+				return nil, err
+			}
+			result[name] = sum
+		case domain.AggregationUniq:
+			// count distinct values
+			seen := map[any]bool{}
+			for _, row := range rows {
+				if v := row[field.DB]; v != nil && reflect.TypeOf(v).Comparable() {
+					seen[v] = true
+				}
+			}
+			result[name] = uint64(len(seen))
+		case domain.AggregationQuantile:
+			// compute quantile
+			q, err := quantileColumn(rows, field.DB, field.Level)
+			if err != nil {
+				return nil, err
+			}
+			result[name] = q
+		case domain.AggregationNone:
+			return nil, fmt.Errorf("field %s must be aggregated, grouping is not supported", field.DB)
+		default:
+			return nil, fmt.Errorf("unsupported aggregation type: %d", field.Aggregation)
+		}
+	}
+
+	// return row
+	return result, nil
+}
+
+// aggregateName returns the result column name of the aggregated field.
+func aggregateName(field domain.Field) string {
+	// parametric aggregation
+	if field.Aggregation == domain.AggregationQuantile && field.Level > 0 {
+		return fmt.Sprintf("quantile(%s)(%s)", strconv.FormatFloat(field.Level, 'g', -1, 64), field.DB)
+	}
+
+	// return name
+	return fmt.Sprintf("%s(%s)", aggregationFunctions[field.Aggregation], field.DB)
+}
+
+// aggregationFunctions is a map that defines lower-case function names for
+// different AggregationTypes.
+var aggregationFunctions = map[domain.AggregationType]string{
+	domain.AggregationCount:    "count",
+	domain.AggregationSum:      "sum",
+	domain.AggregationUniq:     "uniq",
+	domain.AggregationQuantile: "quantile",
+}
+
+// quantileColumn returns the quantile of the values of the column at the given
+// level, ignoring NULL. A level of 0 means the median. The result is
+// interpolated linearly between the nearest values, and the quantile of no
+// values is NULL.
+func quantileColumn(rows []Row, column string, level float64) (any, error) {
+	// check level
+	if level == 0 {
+		level = 0.5
+	}
+	if level < 0 || level > 1 {
+		return nil, fmt.Errorf("invalid quantile level: %g", level)
+	}
+
+	// values
+	var values []float64
+	for _, row := range rows {
+		v := row[column]
+		if v == nil {
+			continue
+		}
+		f, ok := toFloat(v)
+		if !ok {
+			return nil, fmt.Errorf("cannot compute quantile of values of type %T", v)
+		}
+		values = append(values, f)
+	}
+
+	// no values
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	// interpolate between the nearest values
+	slices.Sort(values)
+	pos := level * float64(len(values)-1)
+	i := int(pos)
+	if i == len(values)-1 {
+		return values[i], nil
+	}
+	return values[i] + (values[i+1]-values[i])*(pos-float64(i)), nil
+}
+
+// sumColumn sums the values of the column, ignoring NULL. The sum of no
+// values is NULL.
+func sumColumn(rows []Row, column string) (any, error) {
+	// values
+	var values []any
+	for _, row := range rows {
+		if v := row[column]; v != nil {
+			values = append(values, v)
+		}
+	}
+
+	// no values
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	// sum integers, switching to floats on the first float
+	var sumInt int64
+	var sumFloat float64
+	isFloat := false
+	for _, v := range values {
+		if i, ok := toInt(v); ok && !isFloat {
+			sumInt += i
+			continue
+		}
+		f, ok := toFloat(v)
+		if !ok {
+			return nil, fmt.Errorf("cannot sum values of type %T", v)
+		}
+		if !isFloat {
+			isFloat = true
+			sumFloat = float64(sumInt)
+		}
+		sumFloat += f
+	}
+
+	// return sum
+	if isFloat {
+		return sumFloat, nil
+	}
+	return sumInt, nil
+}
@@ -0,0 +1,29 @@
+package qbr
+
+import (
+	"github.com/tyrenix/qbr/domain"
+	"github.com/tyrenix/qbr/internal/mongobuilder"
+)
+
+// ToMongo builds driver-agnostic MongoDB documents from the query builder data.
+//
+// Conditions are rendered into a filter with $eq, $ne, $lt, $gt, $lte, $gte,
+// $in, $and, $or and $nor, and LIKE patterns into anchored $regex. Select fields
+// become a projection, sorts an ordered sort document, and limit and offset the
+// limit and skip options. Data of create queries becomes the insert document,
+// and data of update queries an update document with $set, $inc, $mul and $bit.
+//
+// The query is checked with Validate first, so update and delete queries
+// without conditions fail with ErrFullTable unless AllowFullTable is set. It
+// returns an error if the query uses a feature that cannot be expressed in
+// MongoDB, such as locks, suffixes, explicit returning fields, MaxAffected,
+// ClickHouse features, aggregations or shift modifications.
+func (qb *Query) ToMongo() (*domain.MongoQuery, error) {
+	// validate query
+	if err := qb.Validate(); err != nil {
+		return nil, err
+	}
+
+	// build query
+	return mongobuilder.CreateMongoQuery(qb)
+}
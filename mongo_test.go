@@ -0,0 +1,156 @@
+package qbr
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+func TestToMongo(t *testing.T) {
+	id := NewField(WithDB("id"))
+	name := NewField(WithDB("name"))
+	age := NewField(WithDB("age"))
+
+	tests := []struct {
+		name    string
+		qb      *Query
+		want    *domain.MongoQuery
+		wantErr error
+	}{
+		{
+			name: "read",
+			qb: NewRead().
+				Select(id, name).
+				Where(Eq(name, "a"), Gt(age, 18), In(id, 1, 2)).
+				Sort(NewSortDesc(age), NewSortAsc(id)).
+				Limit(10).
+				Offset(20),
+			want: &domain.MongoQuery{
+				Filter: map[string]any{
+					"name": map[string]any{"$eq": "a"},
+					"age":  map[string]any{"$gt": 18},
+					"id":   map[string]any{"$in": []any{1, 2}},
+				},
+				Projection: map[string]any{"id": 1, "name": 1},
+				Sort:       []domain.MongoSort{{Key: "age", Value: -1}, {Key: "id", Value: 1}},
+				Limit:      10,
+				Skip:       20,
+			},
+		},
+		{
+			name: "colliding keys are joined with and",
+			qb:   NewRead().Where(Gt(age, 18), Lt(age, 30)),
+			want: &domain.MongoQuery{
+				Filter: map[string]any{"$and": []any{
+					map[string]any{"age": map[string]any{"$gt": 18}},
+					map[string]any{"age": map[string]any{"$lt": 30}},
+				}},
+			},
+		},
+		{
+			name: "logical operators and null",
+			qb:   NewRead().Where(Or(Eq(name, domain.ValueNull), Not(Eq(age, 1), Eq(age, 2)))),
+			want: &domain.MongoQuery{
+				Filter: map[string]any{"$or": []any{
+					map[string]any{"name": map[string]any{"$eq": nil}},
+					map[string]any{"$nor": []any{map[string]any{"$and": []any{
+						map[string]any{"age": map[string]any{"$eq": 1}},
+						map[string]any{"age": map[string]any{"$eq": 2}},
+					}}}},
+				}},
+			},
+		},
+		{
+			name: "like patterns",
+			qb:   NewRead().Where(Like(name, EscapeLike("a.b%")+"_%"), ILike(id, "x%")),
+			want: &domain.MongoQuery{
+				Filter: map[string]any{
+					"name": map[string]any{"$regex": `^a\.b%..*$`},
+					"id":   map[string]any{"$regex": "^x.*$", "$options": "i"},
+				},
+			},
+		},
+		{
+			name: "empty filter",
+			qb:   NewRead(),
+			want: &domain.MongoQuery{Filter: map[string]any{}},
+		},
+		{
+			name: "create",
+			qb:   NewCreate().Set(NewData(name, "a"), NewData(age, domain.ValueNull)),
+			want: &domain.MongoQuery{
+				Filter:   map[string]any{},
+				Document: map[string]any{"name": "a", "age": nil},
+			},
+		},
+		{
+			name: "update",
+			qb: NewUpdate().
+				Set(
+					NewData(name, "a"),
+					NewData(age, Subtract(age, 2)),
+					NewData(id, Divide(id, 4.0)),
+					NewData(NewField(WithDB("flags")), BitwiseOr(NewField(WithDB("flags")), 1)),
+				).
+				Where(Eq(id, 1)),
+			want: &domain.MongoQuery{
+				Filter: map[string]any{"id": map[string]any{"$eq": 1}},
+				Update: map[string]any{
+					"$set": map[string]any{"name": "a"},
+					"$inc": map[string]any{"age": -2},
+					"$mul": map[string]any{"id": 0.25},
+					"$bit": map[string]any{"flags": map[string]any{"or": 1}},
+				},
+			},
+		},
+		{
+			name: "update full table allowed",
+			qb:   NewUpdate().Set(NewData(name, "a")).AllowFullTable(),
+			want: &domain.MongoQuery{
+				Filter: map[string]any{},
+				Update: map[string]any{"$set": map[string]any{"name": "a"}},
+			},
+		},
+		{name: "update full table", qb: NewUpdate().Set(NewData(name, "a")), wantErr: ErrFullTable},
+		{name: "delete full table", qb: NewDelete(), wantErr: ErrFullTable},
+		{name: "delete empty filter", qb: NewDelete().Where(And()), wantErr: ErrFullTable},
+		{name: "update without data", qb: NewUpdate().Where(Eq(id, 1)), wantErr: ErrNoData},
+		{name: "empty in", qb: NewRead().Where(In(id)), wantErr: ErrEmptyIn},
+		{name: "operator field name", qb: NewRead().Where(Eq(NewField(WithDB("$where")), 1)), wantErr: errAny},
+		{name: "lock", qb: NewRead().Lock(), wantErr: errAny},
+		{name: "suffix", qb: NewRead().Suffix("LIMIT 1"), wantErr: errAny},
+		{name: "explicit returning", qb: NewCreate().Set(NewData(name, "a")).Returning(id), wantErr: errAny},
+		{name: "max affected", qb: NewDelete().Where(Eq(id, 1)).MaxAffected(1), wantErr: errAny},
+		{name: "final", qb: NewRead().Final(), wantErr: errAny},
+		{name: "sample", qb: NewRead().Sample(0.5), wantErr: errAny},
+		{name: "prewhere", qb: NewRead().Prewhere(Eq(id, 1)), wantErr: errAny},
+		{name: "limit by", qb: NewRead().LimitBy(1, id), wantErr: errAny},
+		{name: "mutation", qb: NewDelete().Where(Eq(id, 1)).Mutation(), wantErr: errAny},
+		{name: "aggregation", qb: NewRead().Select(NewCountField(id)), wantErr: errAny},
+		{name: "integer division", qb: NewUpdate().Set(NewData(id, Divide(id, 2))).Where(Eq(id, 1)), wantErr: errAny},
+		{name: "shift", qb: NewUpdate().Set(NewData(id, ShiftLeft(id, 2))).Where(Eq(id, 1)), wantErr: errAny},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.qb.ToMongo()
+			if tt.wantErr != nil {
+				if err == nil || (tt.wantErr != errAny && !errors.Is(err, tt.wantErr)) {
+					t.Fatalf("ToMongo() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ToMongo() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ToMongo() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+// errAny matches any non-nil error in test cases.
+var errAny = errors.New("any error")
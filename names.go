@@ -0,0 +1,79 @@
+package qbr
+
+import (
+	"fmt"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+// operatorNames is a map that defines stable names for different OperatorTypes.
+var operatorNames = map[domain.OperatorType]string{
+	domain.OperatorEqual:              "eq",
+	domain.OperatorNotEqual:           "ne",
+	domain.OperatorLessThan:           "lt",
+	domain.OperatorGreaterThan:        "gt",
+	domain.OperatorLessThanOrEqual:    "lte",
+	domain.OperatorGreaterThanOrEqual: "gte",
+	domain.OperatorAnd:                "and",
+	domain.OperatorOr:                 "or",
+	domain.OperatorIn:                 "in",
+	domain.OperatorLike:               "like",
+	domain.OperatorILike:              "ilike",
+	domain.OperatorNot:                "not",
+}
+
+// aggregationNames is a map that defines stable names for different AggregationTypes.
+var aggregationNames = map[domain.AggregationType]string{
+	domain.AggregationNone:     "",
+	domain.AggregationCount:    "count",
+	domain.AggregationSum:      "sum",
+	domain.AggregationUniq:     "uniq",
+	domain.AggregationQuantile: "quantile",
+}
+
+// modificationNames is a map that defines stable names for different ModificationTypes.
+var modificationNames = map[domain.ModificationType]string{
+	domain.ModificationAdd:        "add",
+	domain.ModificationSubtract:   "sub",
+	domain.ModificationMultiply:   "mul",
+	domain.ModificationDivide:     "div",
+	domain.ModificationBitwiseAnd: "bit_and",
+	domain.ModificationBitwiseOr:  "bit_or",
+	domain.ModificationBitwiseXor: "bit_xor",
+	domain.ModificationShiftLeft:  "shl",
+	domain.ModificationShiftRight: "shr",
+}
+
+// OperatorName returns the stable name of the operator, such as "eq" or "gte",
+// as used by the JSON representation of queries. If the operator is not
+// supported, it returns an empty string.
+func OperatorName(op domain.OperatorType) string {
+	return operatorNames[op]
+}
+
+// IsLogicalOperator reports whether the operator combines nested conditions
+// (AND, OR and NOT) instead of comparing a field with a value.
+func IsLogicalOperator(op domain.OperatorType) bool {
+	return op == domain.OperatorAnd || op == domain.OperatorOr || op == domain.OperatorNot
+}
+
+// ParseOperator returns the operator with the given stable name. If the name is
+// unknown, it returns an error.
+func ParseOperator(name string) (domain.OperatorType, error) {
+	return parseName(operatorNames, name, "operator")
+}
+
+// parseName returns the key of the names map with the given name, or an error
+// if the name is unknown.
+func parseName[T comparable](names map[T]string, name, kind string) (T, error) {
+	// find name
+	for k, v := range names {
+		if v == name {
+			return k, nil
+		}
+	}
+
+	// unknown name
+	var zero T
+	return zero, fmt.Errorf("unknown %s name: %q", kind, name)
+}
@@ -0,0 +1,113 @@
+package odata
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tokenKind is the kind of a lexical token.
+type tokenKind int
+
+// Token kinds.
+const (
+	tokenEOF    tokenKind = iota
+	tokenIdent            // property names, operators and keywords
+	tokenString           // quoted string literal
+	tokenValue            // unquoted literal: numbers and date times
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+// token is a lexical token of a filter expression.
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+// tokenize splits the filter expression into tokens.
+func tokenize(s string) ([]token, error) {
+	// tokens
+	var tokens []token
+
+	// scan expression
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLParen, value: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRParen, value: ")", pos: i})
+			i++
+		case c == ',':
+			tokens = append(tokens, token{kind: tokenComma, value: ",", pos: i})
+			i++
+		case c == '\'':
+			// read quoted string, '' is an escaped quote
+			var b strings.Builder
+			j := i + 1
+			for {
+				if j >= len(s) {
+					return nil, fmt.Errorf("unterminated string at position %d", i)
+				}
+				if s[j] == '\'' {
+					if j+1 < len(s) && s[j+1] == '\'' {
+						b.WriteByte('\'')
+						j += 2
+						continue
+					}
+					break
+				}
+				b.WriteByte(s[j])
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenString, value: b.String(), pos: i})
+			i = j + 1
+		case isIdentStart(c):
+			// read identifier
+			j := i
+			for j < len(s) && isIdentPart(s[j]) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, value: s[i:j], pos: i})
+			i = j
+		case isValuePart(c):
+			// read unquoted value
+			j := i
+			for j < len(s) && (isValuePart(s[j]) || isIdentStart(s[j])) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenValue, value: s[i:j], pos: i})
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+		}
+	}
+
+	// add end of expression
+	tokens = append(tokens, token{kind: tokenEOF, pos: len(s)})
+
+	// return tokens
+	return tokens, nil
+}
+
+// isIdentStart reports whether c can start an identifier.
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isIdentPart reports whether c can be part of an identifier. Slashes and dots
+// are allowed for navigation property paths.
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9') || c == '/' || c == '.'
+}
+
+// isValuePart reports whether c can be part of an unquoted literal.
+func isValuePart(c byte) bool {
+	return (c >= '0' && c <= '9') || c == '-' || c == '+' || c == '.' || c == ':'
+}
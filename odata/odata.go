@@ -0,0 +1,442 @@
+// Package odata parses OData $filter, $orderby, $top and $skip query options
+// into qbr queries.
+//
+// The supported $filter grammar covers the comparison operators eq, ne, gt,
+// ge, lt and le, the logical operators and, or and not, the in operator, the
+// null literal and the contains, startswith and endswith functions:
+//
+//	$filter=Age ge 18 and (contains(Name,'jo') or Status in ('a','b'))
+//
+// Property names are resolved through a field allowlist, so only declared
+// properties can be filtered and sorted on.
+package odata
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/tyrenix/qbr"
+	"github.com/tyrenix/qbr/domain"
+)
+
+// Query option names.
+const (
+	OptionFilter  = "$filter"
+	OptionOrderBy = "$orderby"
+	OptionTop     = "$top"
+	OptionSkip    = "$skip"
+)
+
+// Schema maps OData property names to database fields.
+type Schema struct {
+	Fields map[string]*domain.Field // Allowed properties by OData name.
+	MaxTop uint64                   // Maximum accepted $top, 0 for no maximum.
+}
+
+// Parse parses the $filter, $orderby, $top and $skip options of the URL values
+// into a read query.
+func (s *Schema) Parse(values url.Values) (*qbr.Query, error) {
+	// create query
+	qb := qbr.NewRead()
+
+	// parse filter
+	if v := values.Get(OptionFilter); v != "" {
+		conds, err := s.ParseFilter(v)
+		if err != nil {
+			return nil, err
+		}
+		qb = qb.Where(conds...)
+	}
+
+	// parse order by
+	if v := values.Get(OptionOrderBy); v != "" {
+		sorts, err := s.ParseOrderBy(v)
+		if err != nil {
+			return nil, err
+		}
+		for i := range sorts {
+			qb = qb.Sort(&sorts[i])
+		}
+	}
+
+	// parse top
+	if v := values.Get(OptionTop); v != "" {
+		top, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %q", OptionTop, v)
+		}
+		if s.MaxTop > 0 && top > s.MaxTop {
+			return nil, fmt.Errorf("%s must not exceed %d", OptionTop, s.MaxTop)
+		}
+		qb = qb.Limit(top)
+	}
+
+	// parse skip
+	if v := values.Get(OptionSkip); v != "" {
+		skip, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %q", OptionSkip, v)
+		}
+		qb = qb.Offset(skip)
+	}
+
+	// return query
+	return qb, nil
+}
+
+// ParseFilter parses a $filter expression into conditions.
+func (s *Schema) ParseFilter(filter string) ([]domain.Condition, error) {
+	// tokenize filter
+	tokens, err := tokenize(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// parse expression
+	p := &parser{schema: s, tokens: tokens}
+	cond, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	// check end of expression
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", t.value, t.pos)
+	}
+
+	// unwrap top level and
+	if cond.Operator == domain.OperatorAnd {
+		return cond.Value.([]domain.Condition), nil
+	}
+
+	// return condition
+	return []domain.Condition{cond}, nil
+}
+
+// ParseOrderBy parses a $orderby expression, a comma separated list of
+// properties optionally followed by asc or desc, into sorts.
+func (s *Schema) ParseOrderBy(orderBy string) ([]domain.Sort, error) {
+	// sorts
+	var sorts []domain.Sort
+	for _, item := range strings.Split(orderBy, ",") {
+		// split property and direction
+		parts := strings.Fields(item)
+		if len(parts) == 0 || len(parts) > 2 {
+			return nil, fmt.Errorf("invalid %s item: %q", OptionOrderBy, item)
+		}
+
+		// get field
+		field, err := s.field(parts[0])
+		if err != nil {
+			return nil, err
+		}
+
+		// get direction
+		sortType := domain.SortAsc
+		if len(parts) == 2 {
+			switch strings.ToLower(parts[1]) {
+			case "asc":
+			case "desc":
+				sortType = domain.SortDesc
+			default:
+				return nil, fmt.Errorf("invalid %s direction: %q", OptionOrderBy, parts[1])
+			}
+		}
+
+		// add sort
+		sorts = append(sorts, domain.Sort{Field: field, Type: sortType})
+	}
+
+	// return sorts
+	return sorts, nil
+}
+
+// field returns the field of the property, or an error if it is not allowed.
+func (s *Schema) field(name string) (*domain.Field, error) {
+	// get field
+	field, ok := s.Fields[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", qbr.ErrFieldNotAllowed, name)
+	}
+
+	// return field
+	return field, nil
+}
+
+// comparisonOperators maps OData comparison operators to operator types.
+var comparisonOperators = map[string]domain.OperatorType{
+	"eq": domain.OperatorEqual,
+	"ne": domain.OperatorNotEqual,
+	"gt": domain.OperatorGreaterThan,
+	"ge": domain.OperatorGreaterThanOrEqual,
+	"lt": domain.OperatorLessThan,
+	"le": domain.OperatorLessThanOrEqual,
+}
+
+// likeFunctions maps OData string functions to LIKE pattern formats.
+var likeFunctions = map[string]string{
+	"contains":   "%%%s%%",
+	"startswith": "%s%%",
+	"endswith":   "%%%s",
+}
+
+// parser is a recursive descent parser of filter expressions.
+type parser struct {
+	schema *Schema
+	tokens []token
+	pos    int
+}
+
+// peek returns the current token.
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+// next returns the current token and advances to the next one.
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// isKeyword reports whether the current token is the given keyword.
+func (p *parser) isKeyword(keyword string) bool {
+	t := p.peek()
+	return t.kind == tokenIdent && strings.EqualFold(t.value, keyword)
+}
+
+// expect consumes a token of the given kind or returns an error.
+func (p *parser) expect(kind tokenKind, what string) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, fmt.Errorf("expected %s at position %d", what, t.pos)
+	}
+	return t, nil
+}
+
+// parseOr parses: and-expr { "or" and-expr }.
+func (p *parser) parseOr() (domain.Condition, error) {
+	return p.parseLogical("or", domain.OperatorOr, p.parseAnd)
+}
+
+// parseAnd parses: unary-expr { "and" unary-expr }.
+func (p *parser) parseAnd() (domain.Condition, error) {
+	return p.parseLogical("and", domain.OperatorAnd, p.parseUnary)
+}
+
+// parseLogical parses operands joined by the keyword into a logical condition.
+// Nested conditions with the same operator are flattened.
+func (p *parser) parseLogical(keyword string, op domain.OperatorType, operand func() (domain.Condition, error)) (domain.Condition, error) {
+	// operands
+	var conds []domain.Condition
+	for {
+		// parse operand
+		cond, err := operand()
+		if err != nil {
+			return domain.Condition{}, err
+		}
+
+		// flatten same operator
+		if cond.Operator == op {
+			conds = append(conds, cond.Value.([]domain.Condition)...)
+		} else {
+			conds = append(conds, cond)
+		}
+
+		// check next keyword
+		if !p.isKeyword(keyword) {
+			break
+		}
+		p.next()
+	}
+
+	// single operand
+	if len(conds) == 1 {
+		return conds[0], nil
+	}
+
+	// return logical condition
+	return domain.Condition{Operator: op, Value: conds}, nil
+}
+
+// parseUnary parses: "not" unary-expr | primary-expr.
+func (p *parser) parseUnary() (domain.Condition, error) {
+	// not expression
+	if p.isKeyword("not") {
+		p.next()
+		cond, err := p.parseUnary()
+		if err != nil {
+			return domain.Condition{}, err
+		}
+		return qbr.Not(cond), nil
+	}
+
+	// primary expression
+	return p.parsePrimary()
+}
+
+// parsePrimary parses a parenthesized expression, a function call or a
+// comparison.
+func (p *parser) parsePrimary() (domain.Condition, error) {
+	// parenthesized expression
+	if p.peek().kind == tokenLParen {
+		p.next()
+		cond, err := p.parseOr()
+		if err != nil {
+			return domain.Condition{}, err
+		}
+		if _, err := p.expect(tokenRParen, "')'"); err != nil {
+			return domain.Condition{}, err
+		}
+		return cond, nil
+	}
+
+	// property or function name
+	name, err := p.expect(tokenIdent, "property or function")
+	if err != nil {
+		return domain.Condition{}, err
+	}
+
+	// function call
+	if format, ok := likeFunctions[strings.ToLower(name.value)]; ok && p.peek().kind == tokenLParen {
+		return p.parseLikeFunction(format)
+	}
+
+	// get field
+	field, err := p.schema.field(name.value)
+	if err != nil {
+		return domain.Condition{}, err
+	}
+
+	// get operator
+	opToken, err := p.expect(tokenIdent, "operator")
+	if err != nil {
+		return domain.Condition{}, err
+	}
+	opName := strings.ToLower(opToken.value)
+
+	// in operator
+	if opName == "in" {
+		return p.parseIn(field)
+	}
+
+	// comparison operator
+	op, ok := comparisonOperators[opName]
+	if !ok {
+		return domain.Condition{}, fmt.Errorf("unknown operator %q at position %d", opToken.value, opToken.pos)
+	}
+
+	// parse value
+	value, err := p.parseLiteral()
+	if err != nil {
+		return domain.Condition{}, err
+	}
+
+	// null comparison
+	if value == nil {
+		if op != domain.OperatorEqual && op != domain.OperatorNotEqual {
+			return domain.Condition{}, fmt.Errorf("operator %q is not supported for null", opToken.value)
+		}
+		value = domain.ValueNull
+	}
+
+	// return condition
+	return domain.Condition{Field: field, Operator: op, Value: value}, nil
+}
+
+// parseLikeFunction parses the arguments of contains, startswith or endswith
+// into a LIKE condition with the given pattern format.
+func (p *parser) parseLikeFunction(format string) (domain.Condition, error) {
+	// open parenthesis
+	p.next()
+
+	// get property
+	name, err := p.expect(tokenIdent, "property")
+	if err != nil {
+		return domain.Condition{}, err
+	}
+	field, err := p.schema.field(name.value)
+	if err != nil {
+		return domain.Condition{}, err
+	}
+
+	// comma
+	if _, err := p.expect(tokenComma, "','"); err != nil {
+		return domain.Condition{}, err
+	}
+
+	// get string argument
+	arg, err := p.expect(tokenString, "string")
+	if err != nil {
+		return domain.Condition{}, err
+	}
+
+	// close parenthesis
+	if _, err := p.expect(tokenRParen, "')'"); err != nil {
+		return domain.Condition{}, err
+	}
+
+	// return like condition
+	return qbr.Like(field, fmt.Sprintf(format, qbr.EscapeLike(arg.value))), nil
+}
+
+// parseIn parses the parenthesized value list of the in operator.
+func (p *parser) parseIn(field *domain.Field) (domain.Condition, error) {
+	// open parenthesis
+	if _, err := p.expect(tokenLParen, "'('"); err != nil {
+		return domain.Condition{}, err
+	}
+
+	// values
+	var values []any
+	for {
+		// parse value
+		value, err := p.parseLiteral()
+		if err != nil {
+			return domain.Condition{}, err
+		}
+		values = append(values, value)
+
+		// check next value
+		if p.peek().kind != tokenComma {
+			break
+		}
+		p.next()
+	}
+
+	// close parenthesis
+	if _, err := p.expect(tokenRParen, "')'"); err != nil {
+		return domain.Condition{}, err
+	}
+
+	// return in condition
+	return qbr.In(field, values...), nil
+}
+
+// parseLiteral parses a literal: a string, number, boolean, null or date time.
+func (p *parser) parseLiteral() (any, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokenString:
+		return t.value, nil
+	case tokenIdent:
+		switch strings.ToLower(t.value) {
+		case "null":
+			return nil, nil
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+	case tokenValue:
+		return parseValue(t.value)
+	}
+
+	// unexpected token
+	return nil, fmt.Errorf("expected literal at position %d", t.pos)
+}
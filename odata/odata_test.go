@@ -0,0 +1,118 @@
+package odata
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tyrenix/qbr"
+	"github.com/tyrenix/qbr/domain"
+)
+
+var schema = &Schema{
+	Fields: map[string]*domain.Field{
+		"Age":     qbr.NewField(qbr.WithDB("age")),
+		"Name":    qbr.NewField(qbr.WithDB("name")),
+		"Status":  qbr.NewField(qbr.WithDB("status")),
+		"Active":  qbr.NewField(qbr.WithDB("active")),
+		"Created": qbr.NewField(qbr.WithDB("created_at")),
+	},
+	MaxTop: 100,
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  url.Values
+		want    string
+		args    []any
+		wantErr error
+	}{
+		{
+			name:   "comparison and logical operators",
+			values: url.Values{"$filter": {"Age ge 18 and (contains(Name,'jo') or Status in ('a','b'))"}},
+			want:   `SELECT * FROM users WHERE age >= $1 AND (name LIKE $2 OR status IN ($3, $4))`,
+			args:   []any{int64(18), "%jo%", "a", "b"},
+		},
+		{
+			name:   "not and null",
+			values: url.Values{"$filter": {"not (Name eq null) and Status ne null"}},
+			want:   "SELECT * FROM users WHERE NOT (name IS NULL) AND status IS NOT NULL",
+		},
+		{
+			name:   "or of ands",
+			values: url.Values{"$filter": {"Age lt 10 or Age gt 20 and Active eq true"}},
+			want:   "SELECT * FROM users WHERE (age < $1 OR (age > $2 AND active = $3))",
+			args:   []any{int64(10), int64(20), true},
+		},
+		{
+			name:   "like functions escape wildcards",
+			values: url.Values{"$filter": {`startswith(Name,'50%_') and endswith(Name,'a\b')`}},
+			want:   `SELECT * FROM users WHERE name LIKE $1 AND name LIKE $2`,
+			args:   []any{`50\%\_%`, `%a\\b`},
+		},
+		{
+			name:   "quoted string and date",
+			values: url.Values{"$filter": {"Name eq 'O''Brien' and Created ge 2024-01-02"}},
+			want:   "SELECT * FROM users WHERE name = $1 AND created_at >= $2",
+			args:   []any{"O'Brien", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:   "order by and pagination",
+			values: url.Values{"$orderby": {"Age desc, Name"}, "$top": {"10"}, "$skip": {"20"}},
+			want:   "SELECT * FROM users ORDER BY age DESC, name ASC LIMIT 10 OFFSET 20",
+		},
+		{
+			name:    "unknown property",
+			values:  url.Values{"$filter": {"Password eq 'x'"}},
+			wantErr: qbr.ErrFieldNotAllowed,
+		},
+		{
+			name:    "unknown order by property",
+			values:  url.Values{"$orderby": {"Password"}},
+			wantErr: qbr.ErrFieldNotAllowed,
+		},
+		{name: "null comparison", values: url.Values{"$filter": {"Age gt null"}}, wantErr: errAny},
+		{name: "unknown operator", values: url.Values{"$filter": {"Age has 1"}}, wantErr: errAny},
+		{name: "trailing token", values: url.Values{"$filter": {"Age eq 1 Age"}}, wantErr: errAny},
+		{name: "unclosed parenthesis", values: url.Values{"$filter": {"(Age eq 1"}}, wantErr: errAny},
+		{name: "unterminated string", values: url.Values{"$filter": {"Name eq 'a"}}, wantErr: errAny},
+		{name: "top exceeds maximum", values: url.Values{"$top": {"1000"}}, wantErr: errAny},
+		{name: "invalid skip", values: url.Values{"$skip": {"-1"}}, wantErr: errAny},
+		{name: "invalid direction", values: url.Values{"$orderby": {"Age up"}}, wantErr: errAny},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qb, err := schema.Parse(tt.values)
+			if tt.wantErr != nil {
+				if err == nil || (tt.wantErr != errAny && !errors.Is(err, tt.wantErr)) {
+					t.Fatalf("Parse() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			// build query
+			got, args, err := qb.ToSql("users", qbr.SqlDollar)
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ToSql() = %q, want %q", got, tt.want)
+			}
+			if len(args) != 0 || len(tt.args) != 0 {
+				if !reflect.DeepEqual(args, tt.args) {
+					t.Errorf("args = %#v, want %#v", args, tt.args)
+				}
+			}
+		})
+	}
+}
+
+// errAny matches any non-nil error in test cases.
+var errAny = errors.New("any error")
@@ -0,0 +1,34 @@
+package odata
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// parseValue parses an unquoted literal as an integer, a float, a date time or
+// a date.
+func parseValue(s string) (any, error) {
+	// integer
+	if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return v, nil
+	}
+
+	// float
+	if v, err := strconv.ParseFloat(s, 64); err == nil {
+		return v, nil
+	}
+
+	// date time
+	if v, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return v, nil
+	}
+
+	// date
+	if v, err := time.Parse(time.DateOnly, s); err == nil {
+		return v, nil
+	}
+
+	// unknown literal
+	return nil, fmt.Errorf("invalid literal: %q", s)
+}
@@ -2,6 +2,9 @@ package qbr
 
 // Offset set offset.
 func (qb *Query) Offset(offset uint64) *Query {
+	// copy immutable query
+	qb = qb.mutable()
+
 	// set offset
 	qb.offset = offset
 
@@ -13,6 +13,23 @@ type Query struct {
 	limit      uint64
 	offset     uint64
 	suffix     string
+	err        error
+
+	returning     []domain.Field
+	returningMode returningMode
+
+	allowFullTable bool
+	maxAffected    uint64
+
+	immutable     bool
+	allowedFields map[string]*domain.Field
+
+	final         bool
+	sample        float64
+	prewhere      []domain.Condition
+	limitBy       uint64
+	limitByFields []domain.Field
+	mutation      bool
 }
 
 // New creates new query builder with given query type.
@@ -53,3 +70,18 @@ func NewUpdate() *Query {
 func NewDelete() *Query {
 	return New(domain.OperationDelete)
 }
+
+// setError records the first error that occurred while building the query.
+// The error is returned when the query is built.
+func (qb *Query) setError(err error) *Query {
+	// copy immutable query
+	qb = qb.mutable()
+
+	// keep first error
+	if qb.err == nil {
+		qb.err = err
+	}
+
+	// return query
+	return qb
+}
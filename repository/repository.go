@@ -0,0 +1,387 @@
+// Package repository provides a generic repository with typed CRUD operations
+// on top of qbr queries.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/tyrenix/qbr"
+	"github.com/tyrenix/qbr/domain"
+	"github.com/tyrenix/qbr/exec"
+	"github.com/tyrenix/qbr/internal/sqlbuilder"
+)
+
+// Repository provides typed CRUD operations for the struct type T.
+//
+// The table, primary key and columns are taken from T as described by
+// qbr.TableOf. Queries are run through an exec.Executor, so the same repository
+// can work with *sql.DB, *sql.Tx or *sql.Conn.
+//
+// On dialects without RETURNING, such as MySQL and ClickHouse, Insert, Update
+// and Patch execute the write and then read the row back by its primary key.
+type Repository[T any] struct {
+	db      exec.Executor
+	table   *qbr.Table
+	dialect domain.SqlDialect
+}
+
+// New creates a new repository for the struct type T using the given executor
+// and SQL dialect.
+//
+// It returns an error if the table descriptor of T cannot be collected.
+func New[T any](db exec.Executor, dialect domain.SqlDialect) (*Repository[T], error) {
+	// get table
+	var entity T
+	tbl, err := qbr.TableOf(entity)
+	if err != nil {
+		return nil, err
+	}
+
+	// create repository
+	return &Repository[T]{
+		db:      db,
+		table:   tbl,
+		dialect: dialect,
+	}, nil
+}
+
+// WithExecutor returns a copy of the repository that runs its queries through
+// the given executor, e.g. a transaction.
+func (r *Repository[T]) WithExecutor(db exec.Executor) *Repository[T] {
+	return &Repository[T]{
+		db:      db,
+		table:   r.table,
+		dialect: r.dialect,
+	}
+}
+
+// Table returns the table descriptor of the repository.
+func (r *Repository[T]) Table() *qbr.Table {
+	return r.table
+}
+
+// Insert inserts all fields of the given entity, including the fields of
+// embedded structs and zero values, and returns the created row. Generated
+// fields, such as serial primary keys, are left to the database.
+func (r *Repository[T]) Insert(ctx context.Context, entity T) (T, error) {
+	// get entity data
+	data, err := r.table.Data(entity)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	// create query
+	qb := qbr.NewCreate().
+		Set(data...).
+		SelectStruct(new(T))
+
+	// check is returning supported
+	if sqlbuilder.SupportsReturning(r.dialect) {
+		return r.queryOne(ctx, qb)
+	}
+
+	// get primary key of inserted row
+	pk, generated, err := r.insertedPrimaryKey(entity)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	// execute query
+	res, err := exec.Exec(ctx, r.db, qb.NoReturning(), r.table.FullName(), r.dialect)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	// get generated primary key
+	if generated {
+		if pk, err = res.LastInsertId(); err != nil {
+			var zero T
+			return zero, err
+		}
+	}
+
+	// read inserted row
+	return r.Get(ctx, pk)
+}
+
+// Get returns the row with the given primary key. For composite primary keys
+// pk is a []any with the values in primary key order.
+//
+// If the row does not exist, sql.ErrNoRows is returned.
+func (r *Repository[T]) Get(ctx context.Context, pk any) (T, error) {
+	// create conditions
+	conds, err := r.pkConditions(pk)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	// create query
+	qb := r.newRead().Where(conds...)
+
+	// execute query
+	return r.queryOne(ctx, qb)
+}
+
+// Find returns all rows that match the given conditions.
+func (r *Repository[T]) Find(ctx context.Context, conds ...domain.Condition) ([]T, error) {
+	// create query
+	qb := r.newRead().Where(conds...)
+
+	// execute query
+	rows, err := exec.QueryRows(ctx, r.db, qb, r.table.FullName(), r.dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	// scan rows
+	return qbr.ScanAll[T](rows)
+}
+
+// Update writes all fields of the given entity, except primary key and
+// generated fields, to the row with the entity's primary key, and returns the
+// updated row.
+func (r *Repository[T]) Update(ctx context.Context, entity T) (T, error) {
+	// zero entity
+	var zero T
+
+	// get primary key values
+	pk, err := r.table.PrimaryKeyValues(entity)
+	if err != nil {
+		return zero, err
+	}
+
+	// get entity data
+	data, err := r.table.Data(entity)
+	if err != nil {
+		return zero, err
+	}
+
+	// remove primary key fields
+	sets := make([]*domain.Data, 0, len(data))
+	for _, d := range data {
+		if !r.isPrimaryKey(d.Field) {
+			sets = append(sets, d)
+		}
+	}
+
+	// update row
+	return r.Patch(ctx, pk, sets...)
+}
+
+// Patch sets the given data on the row with the given primary key and returns
+// the updated row. For composite primary keys pk is a []any with the values in
+// primary key order.
+//
+// If the row does not exist, sql.ErrNoRows is returned.
+func (r *Repository[T]) Patch(ctx context.Context, pk any, data ...*domain.Data) (T, error) {
+	// create conditions
+	conds, err := r.pkConditions(pk)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	// create query
+	qb := qbr.NewUpdate().
+		Set(data...).
+		Where(conds...).
+		SelectStruct(new(T))
+
+	// check is returning supported
+	if sqlbuilder.SupportsReturning(r.dialect) {
+		return r.queryOne(ctx, qb)
+	}
+
+	// execute query
+	if _, err := exec.Exec(ctx, r.db, qb.NoReturning(), r.table.FullName(), r.dialect); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	// read updated row
+	return r.Get(ctx, pk)
+}
+
+// Delete deletes the row with the given primary key. For composite primary
+// keys pk is a []any with the values in primary key order.
+//
+// If the row does not exist, sql.ErrNoRows is returned.
+func (r *Repository[T]) Delete(ctx context.Context, pk any) error {
+	// create conditions
+	conds, err := r.pkConditions(pk)
+	if err != nil {
+		return err
+	}
+
+	// create query
+	qb := qbr.NewDelete().
+		Where(conds...).
+		NoReturning()
+
+	// execute query
+	res, err := exec.Exec(ctx, r.db, qb, r.table.FullName(), r.dialect)
+	if err != nil {
+		return err
+	}
+
+	// get affected rows
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	// check is row exists
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	// row deleted
+	return nil
+}
+
+// Count returns the number of rows that match the given conditions.
+func (r *Repository[T]) Count(ctx context.Context, conds ...domain.Condition) (uint64, error) {
+	// create query
+	qb := qbr.NewRead().
+		Select(qbr.NewCountField(qbr.NewAllField())).
+		Where(conds...)
+
+	// execute query
+	row, err := exec.QueryRow(ctx, r.db, qb, r.table.FullName(), r.dialect)
+	if err != nil {
+		return 0, err
+	}
+
+	// scan count
+	var count uint64
+	if err := row.Scan(&count); err != nil {
+		return 0, err
+	}
+
+	// return count
+	return count, nil
+}
+
+// Exists reports whether at least one row matches the given conditions.
+func (r *Repository[T]) Exists(ctx context.Context, conds ...domain.Condition) (bool, error) {
+	// create query
+	qb := qbr.NewRead().
+		Select(qbr.NewField(qbr.WithDB("1"))).
+		Where(conds...).
+		Limit(1)
+
+	// execute query
+	row, err := exec.QueryRow(ctx, r.db, qb, r.table.FullName(), r.dialect)
+	if err != nil {
+		return false, err
+	}
+
+	// scan row
+	var one int
+	if err := row.Scan(&one); err != nil {
+		// no rows
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+
+		// return error
+		return false, err
+	}
+
+	// row exists
+	return true, nil
+}
+
+// newRead creates a read query selecting the fields of T.
+func (r *Repository[T]) newRead() *qbr.Query {
+	return qbr.NewRead().SelectStruct(new(T))
+}
+
+// queryOne executes the query and scans the single resulting row into T.
+func (r *Repository[T]) queryOne(ctx context.Context, qb *qbr.Query) (T, error) {
+	// execute query
+	rows, err := exec.QueryRows(ctx, r.db, qb, r.table.FullName(), r.dialect)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	// scan row
+	return qbr.ScanOne[T](rows)
+}
+
+// insertedPrimaryKey returns the primary key of an entity inserted without
+// RETURNING. A single generated primary key is reported as generated, to be
+// taken from the last insert id, other primary keys are taken from the entity.
+func (r *Repository[T]) insertedPrimaryKey(entity T) (any, bool, error) {
+	// check is primary key exists
+	if len(r.table.PrimaryKey) == 0 {
+		return nil, false, fmt.Errorf("primary key is not declared for table: %s", r.table.FullName())
+	}
+
+	// single generated primary key
+	if len(r.table.PrimaryKey) == 1 && r.isGenerated(&r.table.PrimaryKey[0]) {
+		return nil, true, nil
+	}
+
+	// check is generated primary key
+	for i := range r.table.PrimaryKey {
+		if r.isGenerated(&r.table.PrimaryKey[i]) {
+			return nil, false, fmt.Errorf("generated composite primary key of table %s cannot be read back", r.table.FullName())
+		}
+	}
+
+	// get primary key values
+	values, err := r.table.PrimaryKeyValues(entity)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// return primary key
+	return values, false, nil
+}
+
+// pkConditions creates primary key conditions from a single value or a []any
+// of values for composite primary keys.
+func (r *Repository[T]) pkConditions(pk any) ([]domain.Condition, error) {
+	// composite primary key
+	if values, ok := pk.([]any); ok {
+		return r.table.PrimaryKeyConditions(values...)
+	}
+
+	// single primary key
+	return r.table.PrimaryKeyConditions(pk)
+}
+
+// isGenerated checks if the given field is a generated field of the table.
+func (r *Repository[T]) isGenerated(field *domain.Field) bool {
+	// check generated fields
+	for i := range r.table.Generated {
+		if qbr.IsFieldEqual(&r.table.Generated[i], field) {
+			return true
+		}
+	}
+
+	// not generated
+	return false
+}
+
+// isPrimaryKey checks if the given field is a primary key field of the table.
+func (r *Repository[T]) isPrimaryKey(field *domain.Field) bool {
+	// check primary key fields
+	for i := range r.table.PrimaryKey {
+		if qbr.IsFieldEqual(&r.table.PrimaryKey[i], field) {
+			return true
+		}
+	}
+
+	// not primary key
+	return false
+}
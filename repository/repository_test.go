@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/tyrenix/qbr"
+	"github.com/tyrenix/qbr/domain"
+	"github.com/tyrenix/qbr/internal/fakedb"
+)
+
+type Audit struct {
+	CreatedBy string `db:"created_by"`
+}
+
+type user struct {
+	_ struct{} `qbr:"table=app.users"`
+	Audit
+	ID   int64  `db:"id" qbr:"pk generated"`
+	Name string `db:"name"`
+	Age  int    `db:"age"`
+}
+
+var (
+	userColumns = []string{"id", "name", "age", "created_by"}
+	userRow     = []driver.Value{int64(1), "ann", int64(0), "root"}
+	userValue   = user{Audit: Audit{CreatedBy: "root"}, ID: 1, Name: "ann"}
+	nameField   = qbr.NewField(qbr.WithDB("name"))
+)
+
+func TestRepository(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect domain.SqlDialect
+		run     func(ctx context.Context, r *Repository[user]) (any, error)
+		results []fakedb.Result
+		want    any
+		wantErr error
+		stmts   []fakedb.Statement
+	}{
+		{
+			name: "insert writes zero and embedded fields",
+			run: func(ctx context.Context, r *Repository[user]) (any, error) {
+				return r.Insert(ctx, user{Audit: Audit{CreatedBy: "root"}, Name: "ann"})
+			},
+			results: []fakedb.Result{{Columns: userColumns, Rows: [][]driver.Value{userRow}}},
+			want:    userValue,
+			stmts: []fakedb.Statement{{
+				Query: "INSERT INTO app.users (name, age, created_by) VALUES ($1, $2, $3) RETURNING id, name, age, created_by",
+				Args:  []any{"ann", int64(0), "root"},
+			}},
+		},
+		{
+			name: "get",
+			run: func(ctx context.Context, r *Repository[user]) (any, error) {
+				return r.Get(ctx, 1)
+			},
+			results: []fakedb.Result{{Columns: userColumns, Rows: [][]driver.Value{userRow}}},
+			want:    userValue,
+			stmts: []fakedb.Statement{{
+				Query: "SELECT id, name, age, created_by FROM app.users WHERE id = $1",
+				Args:  []any{int64(1)},
+			}},
+		},
+		{
+			name: "get not found",
+			run: func(ctx context.Context, r *Repository[user]) (any, error) {
+				return r.Get(ctx, 1)
+			},
+			results: []fakedb.Result{{Columns: userColumns}},
+			want:    user{},
+			wantErr: sql.ErrNoRows,
+			stmts: []fakedb.Statement{{
+				Query: "SELECT id, name, age, created_by FROM app.users WHERE id = $1",
+				Args:  []any{int64(1)},
+			}},
+		},
+		{
+			name: "get composite key mismatch",
+			run: func(ctx context.Context, r *Repository[user]) (any, error) {
+				_, err := r.Get(ctx, []any{1, 2})
+				return nil, err
+			},
+			wantErr: errAny,
+		},
+		{
+			name: "find",
+			run: func(ctx context.Context, r *Repository[user]) (any, error) {
+				return r.Find(ctx, qbr.Eq(nameField, "ann"))
+			},
+			results: []fakedb.Result{{Columns: userColumns, Rows: [][]driver.Value{userRow}}},
+			want:    []user{userValue},
+			stmts: []fakedb.Statement{{
+				Query: "SELECT id, name, age, created_by FROM app.users WHERE name = $1",
+				Args:  []any{"ann"},
+			}},
+		},
+		{
+			name: "update",
+			run: func(ctx context.Context, r *Repository[user]) (any, error) {
+				return r.Update(ctx, userValue)
+			},
+			results: []fakedb.Result{{Columns: userColumns, Rows: [][]driver.Value{userRow}}},
+			want:    userValue,
+			stmts: []fakedb.Statement{{
+				Query: "UPDATE app.users SET name = $1, age = $2, created_by = $3 WHERE id = $4 RETURNING id, name, age, created_by",
+				Args:  []any{"ann", int64(0), "root", int64(1)},
+			}},
+		},
+		{
+			name: "delete",
+			run: func(ctx context.Context, r *Repository[user]) (any, error) {
+				return nil, r.Delete(ctx, 1)
+			},
+			results: []fakedb.Result{{RowsAffected: 1}},
+			stmts: []fakedb.Statement{{
+				Query: "DELETE FROM app.users WHERE id = $1",
+				Args:  []any{int64(1)},
+			}},
+		},
+		{
+			name: "delete not found",
+			run: func(ctx context.Context, r *Repository[user]) (any, error) {
+				return nil, r.Delete(ctx, 1)
+			},
+			results: []fakedb.Result{{RowsAffected: 0}},
+			wantErr: sql.ErrNoRows,
+			stmts: []fakedb.Statement{{
+				Query: "DELETE FROM app.users WHERE id = $1",
+				Args:  []any{int64(1)},
+			}},
+		},
+		{
+			name:    "mysql insert reads the row back",
+			dialect: qbr.SqlMySQL,
+			run: func(ctx context.Context, r *Repository[user]) (any, error) {
+				return r.Insert(ctx, user{Audit: Audit{CreatedBy: "root"}, Name: "ann"})
+			},
+			results: []fakedb.Result{
+				{RowsAffected: 1, LastInsertId: 1},
+				{Columns: userColumns, Rows: [][]driver.Value{userRow}},
+			},
+			want: userValue,
+			stmts: []fakedb.Statement{
+				{
+					Query: "INSERT INTO app.users (name, age, created_by) VALUES (?, ?, ?)",
+					Args:  []any{"ann", int64(0), "root"},
+				},
+				{
+					Query: "SELECT id, name, age, created_by FROM app.users WHERE id = ?",
+					Args:  []any{int64(1)},
+				},
+			},
+		},
+		{
+			name:    "mysql update reads the row back",
+			dialect: qbr.SqlMySQL,
+			run: func(ctx context.Context, r *Repository[user]) (any, error) {
+				return r.Update(ctx, userValue)
+			},
+			results: []fakedb.Result{
+				{RowsAffected: 1},
+				{Columns: userColumns, Rows: [][]driver.Value{userRow}},
+			},
+			want: userValue,
+			stmts: []fakedb.Statement{
+				{
+					Query: "UPDATE app.users SET name = ?, age = ?, created_by = ? WHERE id = ?",
+					Args:  []any{"ann", int64(0), "root", int64(1)},
+				},
+				{
+					Query: "SELECT id, name, age, created_by FROM app.users WHERE id = ?",
+					Args:  []any{int64(1)},
+				},
+			},
+		},
+		{
+			name:    "mysql patch not found",
+			dialect: qbr.SqlMySQL,
+			run: func(ctx context.Context, r *Repository[user]) (any, error) {
+				return r.Patch(ctx, 1, qbr.NewData(nameField, "bob"))
+			},
+			results: []fakedb.Result{{RowsAffected: 0}, {Columns: userColumns}},
+			want:    user{},
+			wantErr: sql.ErrNoRows,
+			stmts: []fakedb.Statement{
+				{Query: "UPDATE app.users SET name = ? WHERE id = ?", Args: []any{"bob", int64(1)}},
+				{Query: "SELECT id, name, age, created_by FROM app.users WHERE id = ?", Args: []any{int64(1)}},
+			},
+		},
+		{
+			name: "count",
+			run: func(ctx context.Context, r *Repository[user]) (any, error) {
+				return r.Count(ctx)
+			},
+			results: []fakedb.Result{{Columns: []string{"count"}, Rows: [][]driver.Value{{int64(3)}}}},
+			want:    uint64(3),
+			stmts:   []fakedb.Statement{{Query: "SELECT COUNT(*) FROM app.users", Args: []any{}}},
+		},
+		{
+			name: "exists",
+			run: func(ctx context.Context, r *Repository[user]) (any, error) {
+				return r.Exists(ctx, qbr.Eq(nameField, "ann"))
+			},
+			results: []fakedb.Result{{Columns: []string{"1"}, Rows: [][]driver.Value{{int64(1)}}}},
+			want:    true,
+			stmts: []fakedb.Statement{{
+				Query: "SELECT 1 FROM app.users WHERE name = $1 LIMIT 1",
+				Args:  []any{"ann"},
+			}},
+		},
+		{
+			name: "not exists",
+			run: func(ctx context.Context, r *Repository[user]) (any, error) {
+				return r.Exists(ctx, qbr.Eq(nameField, "bob"))
+			},
+			results: []fakedb.Result{{Columns: []string{"1"}}},
+			want:    false,
+			stmts: []fakedb.Statement{{
+				Query: "SELECT 1 FROM app.users WHERE name = $1 LIMIT 1",
+				Args:  []any{"bob"},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, rec := fakedb.Open()
+			defer db.Close()
+			rec.Push(tt.results...)
+
+			dialect := tt.dialect
+			if dialect == "" {
+				dialect = qbr.SqlPostgres
+			}
+			r, err := New[user](db, dialect)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			// check result
+			got, err := tt.run(context.Background(), r)
+			switch {
+			case tt.wantErr == errAny && err == nil:
+				t.Fatalf("error = nil, want error")
+			case tt.wantErr != errAny && !errors.Is(err, tt.wantErr):
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("result = %#v, want %#v", got, tt.want)
+			}
+
+			// check statements
+			if got := rec.Statements(); !reflect.DeepEqual(got, tt.stmts) {
+				t.Errorf("statements = %#v, want %#v", got, tt.stmts)
+			}
+		})
+	}
+}
+
+// errAny matches any non-nil error in test cases.
+var errAny = errors.New("any error")
@@ -0,0 +1,91 @@
+package qbr
+
+import "github.com/tyrenix/qbr/domain"
+
+// returningMode describes how the RETURNING clause of a query is built.
+type returningMode int
+
+// Returning modes.
+const (
+	returningDefault  returningMode = iota // return the select fields
+	returningExplicit                      // return the fields set by Returning
+	returningNone                          // do not return anything
+)
+
+// Returning sets the fields returned by create, update and delete queries,
+// independently of the select fields. If no fields are specified, all fields
+// are returned. Read queries fail to build if Returning is set.
+//
+// Without Returning or NoReturning, create, update and delete queries return
+// the select fields, which default to all fields. Dialects without RETURNING,
+// such as MySQL and ClickHouse, fail to build such queries unless NoReturning
+// is set. The method returns the QueryBuilder instance to support method
+// chaining.
+func (qb *Query) Returning(fields ...*domain.Field) *Query {
+	// copy immutable query
+	qb = qb.mutable()
+
+	// set explicit returning
+	qb.returningMode = returningExplicit
+	qb.returning = nil
+
+	// add fields to query
+	for _, field := range fields {
+		qb.returning = append(qb.returning, *field)
+	}
+
+	// return all fields if not specified
+	if len(qb.returning) == 0 {
+		qb.returning = []domain.Field{*NewAllField()}
+	}
+
+	// return query
+	return qb
+}
+
+// NoReturning disables the RETURNING clause of create, update and delete
+// queries. The method returns the QueryBuilder instance to support method
+// chaining.
+func (qb *Query) NoReturning() *Query {
+	// copy immutable query
+	qb = qb.mutable()
+
+	// disable returning
+	qb.returningMode = returningNone
+	qb.returning = nil
+
+	// return query
+	return qb
+}
+
+// GetReturning returns the fields returned by the query, or an empty slice if
+// the query returns nothing.
+func (qb *Query) GetReturning() []domain.Field {
+	// returning fields
+	var src []domain.Field
+
+	// select returning fields
+	switch qb.returningMode {
+	case returningExplicit:
+		src = qb.returning
+	case returningDefault:
+		if qb.operation != domain.OperationRead {
+			src = qb.selects
+		}
+	}
+
+	// fields for returning
+	fields := make([]domain.Field, len(src))
+
+	// copy fields
+	copy(fields, src)
+
+	// return fields
+	return fields
+}
+
+// IsReturningExplicit returns true if the returned fields have been set with
+// Returning.
+func (qb *Query) IsReturningExplicit() bool {
+	return qb.returningMode == returningExplicit
+}
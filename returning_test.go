@@ -0,0 +1,83 @@
+package qbr
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+func TestReturning(t *testing.T) {
+	id := NewField(WithDB("id"))
+	name := NewField(WithDB("name"))
+
+	tests := []struct {
+		name    string
+		qb      *Query
+		dialect domain.SqlDialect
+		want    string
+		args    []any
+		wantErr bool
+	}{
+		{
+			name:    "postgres default returning",
+			qb:      NewCreate().Set(NewData(name, "a")),
+			dialect: SqlPostgres,
+			want:    "INSERT INTO users (name) VALUES ($1) RETURNING *",
+			args:    []any{"a"},
+		},
+		{
+			name:    "postgres explicit returning",
+			qb:      NewUpdate().Set(NewData(name, "a")).Where(Eq(id, 1)).Select(name).Returning(id),
+			dialect: SqlPostgres,
+			want:    "UPDATE users SET name = $1 WHERE id = $2 RETURNING id",
+			args:    []any{"a", 1},
+		},
+		{
+			name:    "sqlite delete returning",
+			qb:      NewDelete().Where(Eq(id, 1)).Returning(id, name),
+			dialect: SqlSQLite,
+			want:    "DELETE FROM users WHERE id = ? RETURNING id, name",
+			args:    []any{1},
+		},
+		{
+			name:    "sqlserver output",
+			qb:      NewCreate().Set(NewData(name, "a")).Returning(id),
+			dialect: SqlSQLServer,
+			want:    "INSERT INTO users (name) OUTPUT INSERTED.id VALUES (@p1)",
+			args:    []any{"a"},
+		},
+		{
+			name:    "no returning",
+			qb:      NewDelete().Where(Eq(id, 1)).NoReturning(),
+			dialect: SqlPostgres,
+			want:    "DELETE FROM users WHERE id = $1",
+			args:    []any{1},
+		},
+		{
+			name:    "mysql no returning",
+			qb:      NewUpdate().Set(NewData(name, "a")).Where(Eq(id, 1)).NoReturning(),
+			dialect: SqlMySQL,
+			want:    "UPDATE users SET name = ? WHERE id = ?",
+			args:    []any{"a", 1},
+		},
+		{name: "mysql default returning", qb: NewCreate().Set(NewData(name, "a")), dialect: SqlMySQL, wantErr: true},
+		{name: "mysql explicit returning", qb: NewDelete().Where(Eq(id, 1)).Returning(id), dialect: SqlMySQL, wantErr: true},
+		{name: "read returning", qb: NewRead().Returning(id), dialect: SqlPostgres, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, args, err := tt.qb.ToSqlDialect("users", tt.dialect)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ToSqlDialect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ToSqlDialect() = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %#v, want %#v", args, tt.args)
+			}
+		})
+	}
+}
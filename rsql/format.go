@@ -0,0 +1,236 @@
+package rsql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tyrenix/qbr"
+	"github.com/tyrenix/qbr/domain"
+)
+
+// Format renders the conditions as an RSQL expression, so that saved filters
+// can be displayed and edited. Top level conditions are joined with ";".
+//
+// Fields are mapped back to their selectors, LIKE patterns to wildcard
+// arguments, and NOT around IN or LIKE to =out= and != respectively. It returns
+// an error for conditions that cannot be expressed in RSQL.
+func (p *Parser) Format(conds []domain.Condition) (string, error) {
+	return p.formatLogical(conds, ";", false)
+}
+
+// formatLogical renders the conditions joined by sep, wrapping the result in
+// parentheses if group is set.
+func (p *Parser) formatLogical(conds []domain.Condition, sep string, group bool) (string, error) {
+	// rendered conditions
+	parts := make([]string, 0, len(conds))
+	for _, cond := range conds {
+		part, err := p.formatCondition(cond, sep == ";" && len(conds) > 1)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, part)
+	}
+
+	// join conditions
+	s := strings.Join(parts, sep)
+	if group && len(parts) > 1 {
+		s = "(" + s + ")"
+	}
+
+	// return expression
+	return s, nil
+}
+
+// formatCondition renders a single condition. inAnd reports whether the
+// condition is an operand of AND, in which case nested OR groups need
+// parentheses.
+func (p *Parser) formatCondition(cond domain.Condition, inAnd bool) (string, error) {
+	switch cond.Operator {
+	case domain.OperatorAnd:
+		return p.formatLogical(cond.Value.([]domain.Condition), ";", false)
+	case domain.OperatorOr:
+		return p.formatLogical(cond.Value.([]domain.Condition), ",", inAnd)
+	case domain.OperatorNot:
+		return p.formatNot(cond)
+	case domain.OperatorLike:
+		// check custom like operator
+		if _, ok := p.symbol(cond.Operator); !ok {
+			return p.formatWildcard(cond, "==")
+		}
+	}
+
+	// get selector
+	selector, err := p.selector(cond.Field)
+	if err != nil {
+		return "", err
+	}
+
+	// null value
+	if v, ok := cond.Value.(domain.ValueType); ok && v == domain.ValueNull {
+		return "", fmt.Errorf("null comparison on field %s cannot be expressed in rsql", cond.Field.DB)
+	}
+
+	// get operator symbol
+	symbol, ok := p.symbol(cond.Operator)
+	if !ok {
+		return "", fmt.Errorf("operator %q cannot be expressed in rsql", qbr.OperatorName(cond.Operator))
+	}
+
+	// in values
+	if cond.Operator == domain.OperatorIn {
+		values, _ := cond.Value.([]any)
+		return selector + symbol + formatValues(values), nil
+	}
+
+	// return comparison
+	return selector + symbol + formatValue(cond.Value), nil
+}
+
+// formatNot renders NOT (field IN ...) as =out= and NOT (field LIKE ...) as !=.
+func (p *Parser) formatNot(cond domain.Condition) (string, error) {
+	// check single nested condition
+	nested := cond.Value.([]domain.Condition)
+	if len(nested) == 1 {
+		switch inner := nested[0]; inner.Operator {
+		case domain.OperatorIn:
+			selector, err := p.selector(inner.Field)
+			if err != nil {
+				return "", err
+			}
+			values, _ := inner.Value.([]any)
+			return selector + "=out=" + formatValues(values), nil
+		case domain.OperatorLike:
+			return p.formatWildcard(inner, "!=")
+		}
+	}
+
+	// not is not supported
+	return "", fmt.Errorf("operator %q cannot be expressed in rsql", qbr.OperatorName(cond.Operator))
+}
+
+// formatWildcard renders a LIKE condition as a wildcard comparison.
+func (p *Parser) formatWildcard(cond domain.Condition, symbol string) (string, error) {
+	// get selector
+	selector, err := p.selector(cond.Field)
+	if err != nil {
+		return "", err
+	}
+
+	// convert pattern
+	pattern, ok := cond.Value.(string)
+	if !ok {
+		return "", fmt.Errorf("invalid like pattern for field %s", cond.Field.DB)
+	}
+
+	// unescape wildcards
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '*' || (c == '\\' && i+1 < len(pattern) && pattern[i+1] == '*'):
+			return "", fmt.Errorf("literal '*' in like pattern on field %s cannot be expressed in rsql", cond.Field.DB)
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			b.WriteByte(pattern[i])
+		case c == '%':
+			b.WriteByte('*')
+		case c == '_':
+			return "", fmt.Errorf("single character wildcard on field %s cannot be expressed in rsql", cond.Field.DB)
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	// wildcard argument must not be quoted
+	arg := b.String()
+	if strings.ContainsAny(arg, reservedChars) {
+		return "", fmt.Errorf("like pattern on field %s cannot be expressed in rsql", cond.Field.DB)
+	}
+
+	// return comparison
+	return selector + symbol + arg, nil
+}
+
+// selector returns the selector of the field. If several selectors map to the
+// field, the lexicographically smallest one is returned, so that the output is
+// deterministic.
+func (p *Parser) selector(field *domain.Field) (string, error) {
+	// find selector
+	best := ""
+	for selector, f := range p.fields {
+		if !qbr.IsFieldEqual(f.Field, field) {
+			continue
+		}
+		if best == "" || selector < best {
+			best = selector
+		}
+	}
+
+	// check is selector found
+	if best == "" {
+		return "", fmt.Errorf("%w: %q", qbr.ErrFieldNotAllowed, field.DB)
+	}
+
+	// return selector
+	return best, nil
+}
+
+// symbol returns the preferred operator symbol of the operator type. FIQL
+// operators are preferred over symbolic ones, and the shortest symbol wins.
+func (p *Parser) symbol(op domain.OperatorType) (string, bool) {
+	// preferred symbols of standard operators
+	switch op {
+	case domain.OperatorEqual:
+		return "==", true
+	case domain.OperatorNotEqual:
+		return "!=", true
+	}
+
+	// find symbol
+	best := ""
+	for symbol, o := range p.operators {
+		if o != op || !strings.HasPrefix(symbol, "=") {
+			continue
+		}
+		if best == "" || len(symbol) < len(best) || (len(symbol) == len(best) && symbol < best) {
+			best = symbol
+		}
+	}
+
+	// return symbol
+	return best, best != ""
+}
+
+// formatValues renders the values as a parenthesized argument list.
+func formatValues(values []any) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = formatValue(v)
+	}
+	return "(" + strings.Join(parts, ",") + ")"
+}
+
+// formatValue renders a value as an argument, quoting it if needed.
+func formatValue(value any) string {
+	// get string value
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case time.Time:
+		s = v.Format(time.RFC3339Nano)
+	case float64:
+		s = strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		s = fmt.Sprint(v)
+	}
+
+	// quote value
+	if s == "" || strings.ContainsAny(s, reservedChars+"*") {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+	}
+
+	// return value
+	return s
+}
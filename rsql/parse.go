@@ -0,0 +1,341 @@
+package rsql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tyrenix/qbr"
+	"github.com/tyrenix/qbr/domain"
+)
+
+// reservedChars are the characters that cannot appear in unquoted selectors
+// and arguments.
+const reservedChars = "\"'();,=!~<> \t\r\n"
+
+// Parse parses the RSQL expression into conditions.
+//
+// Arguments of == and != containing "*" wildcards are translated into LIKE
+// patterns, and =out= into NOT IN.
+func (p *Parser) Parse(expr string) ([]domain.Condition, error) {
+	// create parser state
+	s := &state{parser: p, input: expr}
+
+	// parse expression
+	cond, err := s.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	// check end of expression
+	s.skipSpaces()
+	if s.pos < len(s.input) {
+		return nil, fmt.Errorf("unexpected %q at position %d", s.input[s.pos], s.pos)
+	}
+
+	// unwrap top level and
+	if cond.Operator == domain.OperatorAnd {
+		return cond.Value.([]domain.Condition), nil
+	}
+
+	// return condition
+	return []domain.Condition{cond}, nil
+}
+
+// state is the state of a recursive descent parse.
+type state struct {
+	parser *Parser
+	input  string
+	pos    int
+}
+
+// skipSpaces advances over whitespace.
+func (s *state) skipSpaces() {
+	for s.pos < len(s.input) && strings.IndexByte(" \t\r\n", s.input[s.pos]) >= 0 {
+		s.pos++
+	}
+}
+
+// consume advances over the given character if it is next.
+func (s *state) consume(c byte) bool {
+	s.skipSpaces()
+	if s.pos < len(s.input) && s.input[s.pos] == c {
+		s.pos++
+		return true
+	}
+	return false
+}
+
+// parseOr parses: and { "," and }.
+func (s *state) parseOr() (domain.Condition, error) {
+	return s.parseLogical(',', domain.OperatorOr, s.parseAnd)
+}
+
+// parseAnd parses: constraint { ";" constraint }.
+func (s *state) parseAnd() (domain.Condition, error) {
+	return s.parseLogical(';', domain.OperatorAnd, s.parseConstraint)
+}
+
+// parseLogical parses operands separated by sep into a logical condition.
+// Nested conditions with the same operator are flattened.
+func (s *state) parseLogical(sep byte, op domain.OperatorType, operand func() (domain.Condition, error)) (domain.Condition, error) {
+	// operands
+	var conds []domain.Condition
+	for {
+		// parse operand
+		cond, err := operand()
+		if err != nil {
+			return domain.Condition{}, err
+		}
+
+		// flatten same operator
+		if cond.Operator == op {
+			conds = append(conds, cond.Value.([]domain.Condition)...)
+		} else {
+			conds = append(conds, cond)
+		}
+
+		// check separator
+		if !s.consume(sep) {
+			break
+		}
+	}
+
+	// single operand
+	if len(conds) == 1 {
+		return conds[0], nil
+	}
+
+	// return logical condition
+	return domain.Condition{Operator: op, Value: conds}, nil
+}
+
+// parseConstraint parses a parenthesized group or a comparison.
+func (s *state) parseConstraint() (domain.Condition, error) {
+	// group
+	if s.consume('(') {
+		cond, err := s.parseOr()
+		if err != nil {
+			return domain.Condition{}, err
+		}
+		if !s.consume(')') {
+			return domain.Condition{}, fmt.Errorf("expected ')' at position %d", s.pos)
+		}
+		return cond, nil
+	}
+
+	// comparison
+	return s.parseComparison()
+}
+
+// parseComparison parses: selector operator arguments.
+func (s *state) parseComparison() (domain.Condition, error) {
+	// get selector
+	s.skipSpaces()
+	start := s.pos
+	selector := s.readUnreserved()
+	if selector == "" {
+		return domain.Condition{}, fmt.Errorf("expected selector at position %d", start)
+	}
+
+	// get field
+	field, ok := s.parser.fields[selector]
+	if !ok {
+		return domain.Condition{}, fmt.Errorf("%w: %q", qbr.ErrFieldNotAllowed, selector)
+	}
+
+	// get operator
+	symbol, err := s.readOperator()
+	if err != nil {
+		return domain.Condition{}, err
+	}
+
+	// get arguments
+	args, quoted, err := s.readArguments()
+	if err != nil {
+		return domain.Condition{}, err
+	}
+
+	// create condition
+	return s.parser.newCondition(field, symbol, args, quoted)
+}
+
+// readUnreserved reads a run of unreserved characters.
+func (s *state) readUnreserved() string {
+	start := s.pos
+	for s.pos < len(s.input) && strings.IndexByte(reservedChars, s.input[s.pos]) < 0 {
+		s.pos++
+	}
+	return s.input[start:s.pos]
+}
+
+// readOperator reads a comparison operator: ==, !=, <, <=, >, >= or =name=.
+func (s *state) readOperator() (string, error) {
+	// rest of input
+	s.skipSpaces()
+	rest := s.input[s.pos:]
+
+	// fiql operator
+	if strings.HasPrefix(rest, "=") && !strings.HasPrefix(rest, "==") {
+		if end := strings.IndexByte(rest[1:], '='); end >= 0 {
+			symbol := rest[:end+2]
+			s.pos += len(symbol)
+			return symbol, nil
+		}
+	}
+
+	// symbolic operators
+	for _, symbol := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if strings.HasPrefix(rest, symbol) {
+			s.pos += len(symbol)
+			return symbol, nil
+		}
+	}
+
+	// unknown operator
+	return "", fmt.Errorf("expected operator at position %d", s.pos)
+}
+
+// readArguments reads a single argument or a parenthesized argument list. It
+// also reports which arguments were quoted.
+func (s *state) readArguments() ([]string, []bool, error) {
+	// argument list
+	if s.consume('(') {
+		var args []string
+		var quoted []bool
+		for {
+			arg, q, err := s.readArgument()
+			if err != nil {
+				return nil, nil, err
+			}
+			args = append(args, arg)
+			quoted = append(quoted, q)
+
+			// check next argument
+			if !s.consume(',') {
+				break
+			}
+		}
+		if !s.consume(')') {
+			return nil, nil, fmt.Errorf("expected ')' at position %d", s.pos)
+		}
+		return args, quoted, nil
+	}
+
+	// single argument
+	arg, q, err := s.readArgument()
+	if err != nil {
+		return nil, nil, err
+	}
+	return []string{arg}, []bool{q}, nil
+}
+
+// readArgument reads an unreserved or quoted argument.
+func (s *state) readArgument() (string, bool, error) {
+	s.skipSpaces()
+
+	// check end of input
+	if s.pos >= len(s.input) {
+		return "", false, fmt.Errorf("expected argument at position %d", s.pos)
+	}
+
+	// quoted argument, backslash escapes the next character
+	if q := s.input[s.pos]; q == '"' || q == '\'' {
+		var b strings.Builder
+		for i := s.pos + 1; i < len(s.input); i++ {
+			switch c := s.input[i]; {
+			case c == '\\' && i+1 < len(s.input):
+				i++
+				b.WriteByte(s.input[i])
+			case c == q:
+				s.pos = i + 1
+				return b.String(), true, nil
+			default:
+				b.WriteByte(c)
+			}
+		}
+		return "", false, fmt.Errorf("unterminated string at position %d", s.pos)
+	}
+
+	// unreserved argument
+	start := s.pos
+	arg := s.readUnreserved()
+	if arg == "" {
+		return "", false, fmt.Errorf("expected argument at position %d", start)
+	}
+	return arg, false, nil
+}
+
+// newCondition creates the condition for the field, operator and arguments.
+func (p *Parser) newCondition(field Field, symbol string, args []string, quoted []bool) (domain.Condition, error) {
+	// negated in
+	if symbol == "=out=" {
+		values, err := parseArguments(field, args)
+		if err != nil {
+			return domain.Condition{}, err
+		}
+		return qbr.Not(qbr.In(field.Field, values...)), nil
+	}
+
+	// get operator
+	op, ok := p.operators[symbol]
+	if !ok {
+		return domain.Condition{}, fmt.Errorf("unknown operator %q", symbol)
+	}
+
+	// in operator
+	if op == domain.OperatorIn {
+		values, err := parseArguments(field, args)
+		if err != nil {
+			return domain.Condition{}, err
+		}
+		return qbr.In(field.Field, values...), nil
+	}
+
+	// check single argument
+	if len(args) != 1 {
+		return domain.Condition{}, fmt.Errorf("operator %q expects a single argument", symbol)
+	}
+
+	// wildcard equality
+	if (op == domain.OperatorEqual || op == domain.OperatorNotEqual) && !quoted[0] && strings.Contains(args[0], "*") {
+		// create like pattern
+		pattern := strings.ReplaceAll(qbr.EscapeLike(args[0]), "*", "%")
+		like := qbr.Like(field.Field, pattern)
+
+		// negated wildcard
+		if op == domain.OperatorNotEqual {
+			return qbr.Not(like), nil
+		}
+		return like, nil
+	}
+
+	// parse value
+	values, err := parseArguments(field, args)
+	if err != nil {
+		return domain.Condition{}, err
+	}
+
+	// return condition
+	return domain.Condition{Field: field.Field, Operator: op, Value: values[0]}, nil
+}
+
+// parseArguments converts the arguments to values with the field's parse
+// function.
+func parseArguments(field Field, args []string) ([]any, error) {
+	values := make([]any, len(args))
+	for i, arg := range args {
+		// keep strings
+		if field.Parse == nil {
+			values[i] = arg
+			continue
+		}
+
+		// parse value
+		v, err := field.Parse(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid argument %q for field %s: %w", arg, field.Field.DB, err)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
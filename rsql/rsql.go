@@ -0,0 +1,70 @@
+// Package rsql parses RSQL/FIQL filter expressions into qbr conditions and
+// formats conditions back into RSQL.
+//
+// An expression such as
+//
+//	name==John*;age=gt=30,status=in=(a,b)
+//
+// is parsed into (name LIKE 'John%' AND age > 30) OR status IN ('a', 'b'):
+// ";" joins constraints with AND, "," with OR, and AND binds tighter than OR.
+// Selectors are resolved through a field mapping, so only declared fields can
+// be filtered on.
+package rsql
+
+import (
+	"github.com/tyrenix/qbr/domain"
+)
+
+// Field maps an RSQL selector to a database field.
+type Field struct {
+	Field *domain.Field             // Database field.
+	Parse func(string) (any, error) // Converts argument strings to values, nil keeps strings.
+}
+
+// Parser parses and formats RSQL expressions for a set of fields.
+type Parser struct {
+	fields    map[string]Field
+	operators map[string]domain.OperatorType
+}
+
+// defaultOperators maps the standard RSQL comparison operators to operator types.
+var defaultOperators = map[string]domain.OperatorType{
+	"==":   domain.OperatorEqual,
+	"!=":   domain.OperatorNotEqual,
+	"=lt=": domain.OperatorLessThan,
+	"<":    domain.OperatorLessThan,
+	"=le=": domain.OperatorLessThanOrEqual,
+	"<=":   domain.OperatorLessThanOrEqual,
+	"=gt=": domain.OperatorGreaterThan,
+	">":    domain.OperatorGreaterThan,
+	"=ge=": domain.OperatorGreaterThanOrEqual,
+	">=":   domain.OperatorGreaterThanOrEqual,
+	"=in=": domain.OperatorIn,
+}
+
+// NewParser creates a new parser for the given fields by selector.
+//
+// The standard operators ==, !=, =lt= (<), =le= (<=), =gt= (>), =ge= (>=),
+// =in= and =out= are supported, and more can be added with RegisterOperator.
+func NewParser(fields map[string]Field) *Parser {
+	// copy default operators
+	operators := make(map[string]domain.OperatorType, len(defaultOperators))
+	for k, v := range defaultOperators {
+		operators[k] = v
+	}
+
+	// create parser
+	return &Parser{
+		fields:    fields,
+		operators: operators,
+	}
+}
+
+// RegisterOperator registers a custom FIQL operator of the form "=name=" for
+// the given operator type, e.g. "=ilike=" for domain.OperatorILike. The
+// operator is used both for parsing and formatting. Returns the parser for
+// method chaining.
+func (p *Parser) RegisterOperator(symbol string, op domain.OperatorType) *Parser {
+	p.operators[symbol] = op
+	return p
+}
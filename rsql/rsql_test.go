@@ -0,0 +1,175 @@
+package rsql
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/tyrenix/qbr"
+	"github.com/tyrenix/qbr/domain"
+)
+
+var (
+	nameField   = qbr.NewField(qbr.WithDB("name"))
+	ageField    = qbr.NewField(qbr.WithDB("age"))
+	statusField = qbr.NewField(qbr.WithDB("status"))
+)
+
+// newTestParser creates a parser with an aliased name field and an ilike
+// operator.
+func newTestParser() *Parser {
+	parseInt := func(s string) (any, error) { return strconv.ParseInt(s, 10, 64) }
+	return NewParser(map[string]Field{
+		"name":     {Field: nameField},
+		"fullName": {Field: nameField},
+		"age":      {Field: ageField, Parse: parseInt},
+		"status":   {Field: statusField},
+	}).RegisterOperator("=ilike=", domain.OperatorILike)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		want    string
+		args    []any
+		wantErr error
+	}{
+		{
+			name: "and binds tighter than or",
+			expr: "name==John*;age=gt=30,status=in=(a,b)",
+			want: `SELECT * FROM users WHERE ((name LIKE $1 AND age > $2) OR status IN ($3, $4))`,
+			args: []any{"John%", int64(30), "a", "b"},
+		},
+		{
+			name: "group and symbolic operators",
+			expr: "age>=18;(status==a,status!=b)",
+			want: "SELECT * FROM users WHERE age >= $1 AND (status = $2 OR status != $3)",
+			args: []any{int64(18), "a", "b"},
+		},
+		{
+			name: "negations",
+			expr: "status=out=(a,b);name!=*x",
+			want: `SELECT * FROM users WHERE NOT (status IN ($1, $2)) AND NOT (name LIKE $3)`,
+			args: []any{"a", "b", "%x"},
+		},
+		{
+			name: "wildcard escapes like characters",
+			expr: "name==50%_*",
+			want: `SELECT * FROM users WHERE name LIKE $1`,
+			args: []any{`50\%\_%`},
+		},
+		{
+			name: "quoted argument is literal",
+			expr: `name=="a*, b\"c"`,
+			want: "SELECT * FROM users WHERE name = $1",
+			args: []any{`a*, b"c`},
+		},
+		{
+			name: "custom operator",
+			expr: "fullName=ilike=jo%",
+			want: `SELECT * FROM users WHERE name ILIKE $1`,
+			args: []any{"jo%"},
+		},
+		{name: "unknown selector", expr: "password==x", wantErr: qbr.ErrFieldNotAllowed},
+		{name: "unknown operator", expr: "name=foo=x", wantErr: errAny},
+		{name: "invalid value", expr: "age==x", wantErr: errAny},
+		{name: "multiple arguments", expr: "name==(a,b)", wantErr: errAny},
+		{name: "unclosed group", expr: "(name==a", wantErr: errAny},
+		{name: "unterminated string", expr: `name=="a`, wantErr: errAny},
+		{name: "trailing input", expr: "name==a)", wantErr: errAny},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conds, err := newTestParser().Parse(tt.expr)
+			if tt.wantErr != nil {
+				if err == nil || (tt.wantErr != errAny && !errors.Is(err, tt.wantErr)) {
+					t.Fatalf("Parse() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			// build query
+			got, args, err := qbr.NewRead().Where(conds...).ToSql("users", qbr.SqlDollar)
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ToSql() = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %#v, want %#v", args, tt.args)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		conds   []domain.Condition
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "aliased field uses smallest selector",
+			conds: []domain.Condition{qbr.Eq(nameField, "a b")},
+			want:  `fullName=="a b"`,
+		},
+		{
+			name: "logical groups",
+			conds: []domain.Condition{
+				qbr.Gt(ageField, int64(30)),
+				qbr.Or(qbr.In(statusField, "a", "b"), qbr.Not(qbr.In(statusField, "c"))),
+			},
+			want: "age=gt=30;(status=in=(a,b),status=out=(c))",
+		},
+		{
+			name:  "wildcards",
+			conds: []domain.Condition{qbr.Like(nameField, `%50\%`), qbr.Not(qbr.Like(nameField, "x%"))},
+			want:  "fullName==*50%;fullName!=x*",
+		},
+		{
+			name:  "custom operator",
+			conds: []domain.Condition{qbr.ILike(nameField, "jo%")},
+			want:  "fullName=ilike=jo%",
+		},
+		{name: "null", conds: []domain.Condition{qbr.Eq(nameField, domain.ValueNull)}, wantErr: true},
+		{name: "single character wildcard", conds: []domain.Condition{qbr.Like(nameField, "a_")}, wantErr: true},
+		{name: "literal star", conds: []domain.Condition{qbr.Like(nameField, "a*%")}, wantErr: true},
+		{name: "unknown field", conds: []domain.Condition{qbr.Eq(qbr.NewField(qbr.WithDB("x")), 1)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestParser()
+
+			// format repeatedly to catch map order dependence
+			for i := 0; i < 10; i++ {
+				got, err := p.Format(tt.conds)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("Format() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if got != tt.want {
+					t.Fatalf("Format() = %q, want %q", got, tt.want)
+				}
+			}
+			if tt.wantErr {
+				return
+			}
+
+			// parse formatted expression
+			if _, err := p.Parse(tt.want); err != nil {
+				t.Errorf("Parse(Format()) error = %v", err)
+			}
+		})
+	}
+}
+
+// errAny matches any non-nil error in test cases.
+var errAny = errors.New("any error")
@@ -0,0 +1,46 @@
+package qbr
+
+// AllowFullTable allows update and delete queries without conditions.
+//
+// By default such queries fail to build with ErrFullTable, so that a missing
+// or stripped filter cannot turn a targeted update or delete into a
+// full-table one. Returns the modified QueryBuilder instance for method
+// chaining.
+func (qb *Query) AllowFullTable() *Query {
+	// copy immutable query
+	qb = qb.mutable()
+
+	// allow full table
+	qb.allowFullTable = true
+
+	// return query
+	return qb
+}
+
+// IsFullTableAllowed returns true if the query has been set with
+// AllowFullTable.
+func (qb *Query) IsFullTableAllowed() bool {
+	return qb.allowFullTable
+}
+
+// MaxAffected limits the number of rows affected by update and delete queries
+// with a LIMIT clause. It is supported only by dialects that allow LIMIT on
+// update and delete statements (MySQL and SQLite), other dialects fail to
+// build the query. Zero means no limit. Returns the modified QueryBuilder
+// instance for method chaining.
+func (qb *Query) MaxAffected(n uint64) *Query {
+	// copy immutable query
+	qb = qb.mutable()
+
+	// set max affected
+	qb.maxAffected = n
+
+	// return query
+	return qb
+}
+
+// GetMaxAffected returns the maximum number of affected rows set for the
+// query, or 0 if no limit has been set.
+func (qb *Query) GetMaxAffected() uint64 {
+	return qb.maxAffected
+}
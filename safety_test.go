@@ -0,0 +1,61 @@
+package qbr
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+func TestMaxAffected(t *testing.T) {
+	id := NewField(WithDB("id"))
+	name := NewField(WithDB("name"))
+
+	tests := []struct {
+		name    string
+		qb      *Query
+		dialect domain.SqlDialect
+		want    string
+		args    []any
+		wantErr bool
+	}{
+		{
+			name:    "mysql update",
+			qb:      NewUpdate().Set(NewData(name, "a")).Where(Eq(id, 1)).NoReturning().MaxAffected(1),
+			dialect: SqlMySQL,
+			want:    "UPDATE users SET name = ? WHERE id = ? LIMIT 1",
+			args:    []any{"a", 1},
+		},
+		{
+			name:    "sqlite delete",
+			qb:      NewDelete().Where(Eq(id, 1)).NoReturning().MaxAffected(2),
+			dialect: SqlSQLite,
+			want:    "DELETE FROM users WHERE id = ? LIMIT 2",
+			args:    []any{1},
+		},
+		{
+			name:    "zero is no limit",
+			qb:      NewDelete().Where(Eq(id, 1)).NoReturning().MaxAffected(0),
+			dialect: SqlPostgres,
+			want:    "DELETE FROM users WHERE id = $1",
+			args:    []any{1},
+		},
+		{name: "postgres", qb: NewDelete().Where(Eq(id, 1)).MaxAffected(1), dialect: SqlPostgres, wantErr: true},
+		{name: "sqlserver", qb: NewDelete().Where(Eq(id, 1)).MaxAffected(1), dialect: SqlSQLServer, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, args, err := tt.qb.ToSqlDialect("users", tt.dialect)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ToSqlDialect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ToSqlDialect() = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %#v, want %#v", args, tt.args)
+			}
+		})
+	}
+}
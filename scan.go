@@ -0,0 +1,273 @@
+package qbr
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/tyrenix/qbr/domain"
+	"github.com/tyrenix/qbr/internal/sqlbuilder"
+)
+
+// structColumn describes a struct field that a result set column is scanned into.
+type structColumn struct {
+	index []int // field index path, including embedded structs
+	json  bool  // decode column value from JSON
+}
+
+// structMapping maps result set column names onto struct fields.
+type structMapping struct {
+	columns map[string]structColumn
+	fields  []domain.Field // mapped fields in column order
+}
+
+// structMappings caches struct mappings by struct type.
+var structMappings sync.Map
+
+// ScanOne scans the first row of rows into a new value of struct type T and
+// closes the rows.
+//
+// Columns are mapped onto struct fields by the same "db" tags that are used by
+// SetStruct, including fields of embedded structs. Columns without a matching
+// field are ignored. If the result set is empty, sql.ErrNoRows is returned.
+func ScanOne[T any](rows *sql.Rows) (T, error) {
+	// result
+	var result T
+
+	// close rows
+	defer rows.Close()
+
+	// get columns
+	columns, err := rows.Columns()
+	if err != nil {
+		return result, err
+	}
+
+	// check is row exists
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return result, err
+		}
+
+		// return no rows error
+		return result, sql.ErrNoRows
+	}
+
+	// scan row
+	if err := scanRow(rows, columns, &result); err != nil {
+		return result, err
+	}
+
+	// return result
+	return result, rows.Close()
+}
+
+// ScanAll scans all rows into a slice of struct type T and closes the rows.
+//
+// Columns are mapped onto struct fields in the same way as ScanOne. If the
+// result set is empty, an empty slice is returned.
+func ScanAll[T any](rows *sql.Rows) ([]T, error) {
+	// close rows
+	defer rows.Close()
+
+	// get columns
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	// results
+	result := []T{}
+
+	// scan all rows
+	for rows.Next() {
+		// scan row
+		var item T
+		if err := scanRow(rows, columns, &item); err != nil {
+			return nil, err
+		}
+
+		// add item
+		result = append(result, item)
+	}
+
+	// check rows error
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// return results
+	return result, nil
+}
+
+// scanRow scans the current row into the struct pointed to by dest.
+func scanRow(rows *sql.Rows, columns []string, dest any) error {
+	// struct value
+	val := reflect.ValueOf(dest).Elem()
+
+	// check is struct
+	if val.Kind() != reflect.Struct {
+		return fmt.Errorf("unsupported scan destination type: %s", val.Type())
+	}
+
+	// get struct mapping
+	mapping := getStructMapping(val.Type())
+
+	// create scan destinations
+	targets := make([]any, len(columns))
+	for i, column := range columns {
+		// get field for column
+		col, ok := mapping.columns[column]
+		if !ok {
+			// discard unknown column
+			targets[i] = new(any)
+			continue
+		}
+
+		// get field value
+		field := fieldByIndexAlloc(val, col.index)
+
+		// json decoded field
+		if col.json {
+			targets[i] = &jsonScanner{dest: field}
+			continue
+		}
+
+		// add field pointer
+		targets[i] = field.Addr().Interface()
+	}
+
+	// scan row
+	return rows.Scan(targets...)
+}
+
+// getStructMapping returns the cached column mapping of the given struct type,
+// building it on first use.
+func getStructMapping(t reflect.Type) *structMapping {
+	// get cached mapping
+	if m, ok := structMappings.Load(t); ok {
+		return m.(*structMapping)
+	}
+
+	// build mapping
+	m := &structMapping{
+		columns: map[string]structColumn{},
+	}
+	buildStructMapping(t, nil, m)
+
+	// cache mapping
+	actual, _ := structMappings.LoadOrStore(t, m)
+
+	// return mapping
+	return actual.(*structMapping)
+}
+
+// buildStructMapping adds the columns of struct type t to the mapping.
+//
+// Tagged fields of the struct itself are added first, then embedded structs
+// without a "db" tag are traversed, so shallower fields take precedence over
+// fields promoted from embedded structs.
+func buildStructMapping(t reflect.Type, index []int, m *structMapping) {
+	// embedded structs
+	var embedded []int
+
+	// we go through the fields of the structure
+	for i := 0; i < t.NumField(); i++ {
+		// field type
+		ft := t.Field(i)
+
+		// extract field
+		field := extractFieldFromStruct(ft)
+
+		// check is embedded struct without tag
+		if field == nil {
+			if ft.Anonymous && derefType(ft.Type).Kind() == reflect.Struct {
+				embedded = append(embedded, i)
+			}
+			continue
+		}
+
+		// check is field can be set
+		if !ft.IsExported() || field.DB == "-" {
+			continue
+		}
+
+		// check is column already mapped
+		if _, ok := m.columns[field.DB]; ok {
+			continue
+		}
+
+		// add column
+		m.columns[field.DB] = structColumn{
+			index: append(append([]int{}, index...), i),
+			json:  sqlbuilder.IsJSONType(ft.Type),
+		}
+		// add field
+		m.fields = append(m.fields, *field)
+	}
+
+	// add embedded structs fields
+	for _, i := range embedded {
+		buildStructMapping(
+			derefType(t.Field(i).Type),
+			append(append([]int{}, index...), i),
+			m,
+		)
+	}
+}
+
+// fieldByIndexAlloc returns the nested field of v by the given index path,
+// allocating nil embedded struct pointers along the way.
+func fieldByIndexAlloc(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		// dereference embedded pointer
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+
+		// get field
+		v = v.Field(x)
+	}
+
+	// return field
+	return v
+}
+
+// derefType returns the element type of t if t is a pointer.
+func derefType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
+
+// jsonScanner is a sql.Scanner that decodes a JSON column value into a field.
+type jsonScanner struct {
+	dest reflect.Value
+}
+
+// Scan implements the sql.Scanner interface.
+func (s *jsonScanner) Scan(src any) error {
+	// get json data
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		// set zero value for null
+		s.dest.Set(reflect.Zero(s.dest.Type()))
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported json source type: %T", src)
+	}
+
+	// decode json
+	return json.Unmarshal(data, s.dest.Addr().Interface())
+}
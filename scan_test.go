@@ -0,0 +1,164 @@
+package qbr
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tyrenix/qbr/internal/fakedb"
+)
+
+type scanMeta struct {
+	Tags []string `json:"tags"`
+}
+
+type scanBase struct {
+	ID int64 `db:"id"`
+}
+
+type scanUser struct {
+	scanBase
+	Name    string         `db:"name"`
+	Meta    scanMeta       `db:"meta"`
+	MetaPtr *scanMeta      `db:"meta_ptr"`
+	Seen    *time.Time     `db:"seen"`
+	Note    sql.NullString `db:"note"`
+}
+
+// queryRows runs a query on a fake database returning the given result.
+func queryRows(t *testing.T, res fakedb.Result) *sql.Rows {
+	t.Helper()
+	db, rec := fakedb.Open()
+	t.Cleanup(func() { db.Close() })
+	rec.Push(res)
+	rows, err := db.QueryContext(context.Background(), "SELECT")
+	if err != nil {
+		t.Fatalf("QueryContext() error = %v", err)
+	}
+	return rows
+}
+
+func TestScanOne(t *testing.T) {
+	seen := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		res     fakedb.Result
+		want    scanUser
+		wantErr error
+	}{
+		{
+			name: "all columns",
+			res: fakedb.Result{
+				Columns: []string{"id", "name", "meta", "meta_ptr", "seen", "note", "unknown"},
+				Rows: [][]driver.Value{{
+					int64(1), "ann", []byte(`{"tags":["a"]}`), `{"tags":["b"]}`, seen, "hi", "x",
+				}},
+			},
+			want: scanUser{
+				scanBase: scanBase{ID: 1},
+				Name:     "ann",
+				Meta:     scanMeta{Tags: []string{"a"}},
+				MetaPtr:  &scanMeta{Tags: []string{"b"}},
+				Seen:     &seen,
+				Note:     sql.NullString{String: "hi", Valid: true},
+			},
+		},
+		{
+			name: "null columns",
+			res: fakedb.Result{
+				Columns: []string{"id", "meta_ptr", "seen", "note"},
+				Rows:    [][]driver.Value{{int64(2), nil, nil, nil}},
+			},
+			want: scanUser{scanBase: scanBase{ID: 2}},
+		},
+		{
+			name:    "no rows",
+			res:     fakedb.Result{Columns: []string{"id"}},
+			wantErr: sql.ErrNoRows,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ScanOne[scanUser](queryRows(t, tt.res))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ScanOne() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ScanOne() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanAll(t *testing.T) {
+	tests := []struct {
+		name string
+		res  fakedb.Result
+		want []scanUser
+	}{
+		{
+			name: "rows",
+			res: fakedb.Result{
+				Columns: []string{"id", "name"},
+				Rows:    [][]driver.Value{{int64(1), "a"}, {int64(2), "b"}},
+			},
+			want: []scanUser{
+				{scanBase: scanBase{ID: 1}, Name: "a"},
+				{scanBase: scanBase{ID: 2}, Name: "b"},
+			},
+		},
+		{
+			name: "empty",
+			res:  fakedb.Result{Columns: []string{"id"}},
+			want: []scanUser{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ScanAll[scanUser](queryRows(t, tt.res))
+			if err != nil {
+				t.Fatalf("ScanAll() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ScanAll() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanWriteSymmetry(t *testing.T) {
+	type writeUser struct {
+		Name    string         `db:"name"`
+		Meta    scanMeta       `db:"meta"`
+		MetaPtr *scanMeta      `db:"meta_ptr"`
+		Seen    *time.Time     `db:"seen"`
+		Note    sql.NullString `db:"note"`
+	}
+
+	seen := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	user := writeUser{
+		Name:    "ann",
+		Meta:    scanMeta{Tags: []string{"a"}},
+		MetaPtr: &scanMeta{Tags: []string{"b"}},
+		Seen:    &seen,
+		Note:    sql.NullString{String: "hi", Valid: true},
+	}
+
+	_, params, err := NewCreate().SetStruct(user).ToSql("users", SqlDollar)
+	if err != nil {
+		t.Fatalf("ToSql() error = %v", err)
+	}
+
+	// every written value must be accepted by a column scanned into the same field
+	want := []any{"ann", []byte(`{"tags":["a"]}`), []byte(`{"tags":["b"]}`), &seen, user.Note}
+	if !reflect.DeepEqual(params, want) {
+		t.Errorf("params = %#v, want %#v", params, want)
+	}
+}
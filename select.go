@@ -1,6 +1,11 @@
 package qbr
 
-import "github.com/tyrenix/qbr/domain"
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/tyrenix/qbr/domain"
+)
 
 // Select sets the fields to be selected in the query. If no fields are
 // specified, all fields are selected. The fields parameter is a variable
@@ -8,6 +13,9 @@ import "github.com/tyrenix/qbr/domain"
 // of fields. The method returns the QueryBuilder instance to support method
 // chaining.
 func (qb *Query) Select(fields ...*domain.Field) *Query {
+	// copy immutable query
+	qb = qb.mutable()
+
 	// set select to null
 	qb.selects = nil
 
@@ -20,6 +28,48 @@ func (qb *Query) Select(fields ...*domain.Field) *Query {
 	return qb
 }
 
+// SelectStruct sets the fields to be selected in the query from the "db" tags
+// of the given struct, including the fields of embedded structs. Fields tagged
+// with qbr:"ignore_on=read" are not selected. For create and update queries the
+// same fields are used for RETURNING unless Returning or NoReturning is set, so
+// the selected columns always match the struct that ScanOne and ScanAll scan
+// into. The struct is dereferenced if it is a pointer; any other value records
+// an error that is returned when the query is built. The method returns the
+// QueryBuilder instance to support method chaining.
+func (qb *Query) SelectStruct(s any) *Query {
+	// struct type
+	t := reflect.TypeOf(s)
+	if t == nil {
+		return qb.setError(fmt.Errorf("select struct: nil value"))
+	}
+
+	// check is struct
+	t = derefType(t)
+	if t.Kind() != reflect.Struct {
+		return qb.setError(fmt.Errorf("select struct: %s is not a struct", t))
+	}
+
+	// copy immutable query
+	qb = qb.mutable()
+
+	// set select to null
+	qb.selects = nil
+
+	// add fields to query
+	for _, field := range getStructMapping(t).fields {
+		// check is ignored on read
+		if isFieldIgnored(&field, domain.OperationRead) {
+			continue
+		}
+
+		// add field
+		qb.selects = append(qb.selects, field)
+	}
+
+	// return query
+	return qb
+}
+
 // GetSelects returns the select fields set for the query builder, or an empty slice if no select fields have been set.
 func (qb *Query) GetSelects() []domain.Field {
 	// conditions for returning
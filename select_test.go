@@ -0,0 +1,55 @@
+package qbr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSelectStruct(t *testing.T) {
+	type base struct {
+		ID int64 `db:"id"`
+	}
+	type user struct {
+		base
+		Name     string `db:"name"`
+		Password string `db:"password" qbr:"ignore_on=read"`
+		Skipped  string
+	}
+
+	tests := []struct {
+		name    string
+		s       any
+		want    []string
+		wantErr bool
+	}{
+		{name: "struct", s: user{}, want: []string{"name", "id"}},
+		{name: "pointer", s: &user{}, want: []string{"name", "id"}},
+		{name: "nil pointer", s: (*user)(nil), want: []string{"name", "id"}},
+		{name: "nil", s: nil, wantErr: true},
+		{name: "not struct", s: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qb := NewRead().Select(NewField(WithDB("other"))).SelectStruct(tt.s)
+
+			// check error
+			_, _, err := qb.ToSql("users", SqlDollar)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ToSql() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			// check selects
+			var got []string
+			for _, f := range qb.GetSelects() {
+				got = append(got, f.DB)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selects = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -27,6 +27,9 @@ func NewData(field *domain.Field, value any, acceptZero ...bool) *domain.Data {
 // the current query type, it is also ignored and not added. Returns the modified QueryBuilder
 // instance for method chaining.
 func (qb *Query) Set(data ...*domain.Data) *Query {
+	// copy immutable query
+	qb = qb.mutable()
+
 	// add data to query
 	for _, d := range data {
 		// check is value is nil
@@ -0,0 +1,450 @@
+package qbr
+
+import (
+	"reflect"
+	"strconv"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+// maxNormalFormClauses is the maximum number of clauses of a CNF or DNF
+// conversion. Larger conversions are abandoned, as they grow exponentially.
+const maxNormalFormClauses = 256
+
+// normalForm is the normal form Simplify converts conditions into.
+type normalForm int
+
+// Normal forms.
+const (
+	normalFormNone normalForm = iota
+	normalFormCNF
+	normalFormDNF
+)
+
+// SimplifyOption is a function that configures Simplify.
+type SimplifyOption func(*simplifyOptions)
+
+// simplifyOptions are the options of Simplify.
+type simplifyOptions struct {
+	form normalForm
+}
+
+// SimplifyCNF converts the conditions into conjunctive normal form: top-level
+// conditions joined by AND, each of them a single predicate or an Or of
+// predicates.
+func SimplifyCNF() SimplifyOption {
+	return func(o *simplifyOptions) {
+		o.form = normalFormCNF
+	}
+}
+
+// SimplifyDNF converts the conditions into disjunctive normal form: a single
+// Or of And groups of predicates.
+func SimplifyDNF() SimplifyOption {
+	return func(o *simplifyOptions) {
+		o.form = normalFormDNF
+	}
+}
+
+// Simplify returns an equivalent, simplified copy of the conditions, which are
+// treated as joined by AND like the conditions of Where. The input conditions
+// are not modified.
+//
+// Nested And groups are flattened into And groups and Or into Or groups,
+// single-condition groups are replaced by their condition, double negations are
+// removed and duplicate predicates in a group are dropped. IN conditions with a
+// single non-nil value become equality checks, and equality checks of the same
+// field in an Or group are merged into a single IN condition. Fields are the
+// same if their DB names, aggregations and levels are equal.
+//
+// With SimplifyCNF or SimplifyDNF the conditions are also converted into the
+// normal form, pushing Not down to the predicates. A conversion that would
+// produce more than 256 clauses is skipped. All rewrites keep SQL NULL
+// semantics.
+func Simplify(conds []domain.Condition, options ...SimplifyOption) []domain.Condition {
+	// apply options
+	var opts simplifyOptions
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	// simplify conditions
+	result := simplifyGroup(domain.OperatorAnd, cloneConditions(conds))
+
+	// convert to normal form
+	if len(result) == 0 {
+		return result
+	}
+	switch opts.form {
+	case normalFormCNF:
+		if clauses, ok := normalize(And(result...), true); ok {
+			result = simplifyGroup(domain.OperatorAnd, fromClauses(clauses, domain.OperatorOr))
+		}
+	case normalFormDNF:
+		if clauses, ok := normalize(And(result...), false); ok {
+			result = simplifyGroup(domain.OperatorAnd, []domain.Condition{
+				Or(fromClauses(clauses, domain.OperatorAnd)...),
+			})
+		}
+	}
+
+	// return conditions
+	return result
+}
+
+// SimplifyWhere simplifies the conditions of the query with Simplify.
+//
+// Returns the modified QueryBuilder instance for method chaining.
+func (qb *Query) SimplifyWhere(options ...SimplifyOption) *Query {
+	// copy immutable query
+	qb = qb.mutable()
+
+	// simplify conditions
+	qb.conditions = Simplify(qb.conditions, options...)
+
+	// return query
+	return qb
+}
+
+// simplifyGroup simplifies the conditions of an And or Or group and returns
+// the simplified conditions of the group.
+func simplifyGroup(op domain.OperatorType, conds []domain.Condition) []domain.Condition {
+	// simplify and flatten conditions
+	var flat []domain.Condition
+	for _, cond := range conds {
+		cond = simplifyCondition(cond)
+
+		// flatten group of the same operator
+		if cond.Operator == op {
+			flat = append(flat, cond.Value.([]domain.Condition)...)
+			continue
+		}
+		flat = append(flat, cond)
+	}
+
+	// merge equality checks of or groups
+	if op == domain.OperatorOr {
+		flat = mergeEqualities(flat)
+	}
+
+	// drop duplicates
+	result := []domain.Condition{}
+	for _, cond := range flat {
+		if !containsCondition(result, cond) {
+			result = append(result, cond)
+		}
+	}
+
+	// return conditions
+	return result
+}
+
+// simplifyCondition simplifies a single condition.
+func simplifyCondition(cond domain.Condition) domain.Condition {
+	// predicate
+	nested, ok := cond.Value.([]domain.Condition)
+	if !ok {
+		// single value in, except nil: IN (NULL) never matches, while an
+		// equality check with nil is IS NULL
+		if cond.Operator == domain.OperatorIn {
+			values := inValues(cond.Value)
+			if len(values) == 1 && values[0] != nil && !isNullValue(values[0]) {
+				return domain.Condition{Field: cond.Field, Operator: domain.OperatorEqual, Value: values[0]}
+			}
+			cond.Value = uniqueValues(values)
+		}
+		return cond
+	}
+
+	// select operator
+	switch cond.Operator {
+	case domain.OperatorNot:
+		// simplify negated conjunction
+		inner := simplifyGroup(domain.OperatorAnd, nested)
+
+		// remove double negation
+		if len(inner) == 1 && inner[0].Operator == domain.OperatorNot {
+			return unwrapGroup(domain.OperatorAnd, inner[0].Value.([]domain.Condition))
+		}
+		return Not(inner...)
+	case domain.OperatorOr:
+		return unwrapGroup(domain.OperatorOr, simplifyGroup(domain.OperatorOr, nested))
+	default:
+		return unwrapGroup(domain.OperatorAnd, simplifyGroup(domain.OperatorAnd, nested))
+	}
+}
+
+// unwrapGroup returns the single condition of the group, or the group of the
+// conditions.
+func unwrapGroup(op domain.OperatorType, conds []domain.Condition) domain.Condition {
+	if len(conds) == 1 {
+		return conds[0]
+	}
+	return domain.Condition{Operator: op, Value: conds}
+}
+
+// mergeEqualities merges equality checks and IN conditions of the same field
+// into a single IN condition at the position of the first one. Checks of NULL
+// and nil are not merged.
+func mergeEqualities(conds []domain.Condition) []domain.Condition {
+	// merged conditions by field
+	merged := map[string]int{}
+	result := []domain.Condition{}
+	for _, cond := range conds {
+		// get values
+		var values []any
+		switch {
+		case cond.Operator == domain.OperatorEqual && cond.Value != nil && !isNullValue(cond.Value):
+			values = []any{cond.Value}
+		case cond.Operator == domain.OperatorIn:
+			values = inValues(cond.Value)
+		default:
+			result = append(result, cond)
+			continue
+		}
+
+		// merge into previous condition of the field
+		key := fieldKey(cond.Field)
+		if i, ok := merged[key]; ok {
+			prev := inValues(result[i].Value)
+			result[i] = domain.Condition{
+				Field:    result[i].Field,
+				Operator: domain.OperatorIn,
+				Value:    uniqueValues(append(prev, values...)),
+			}
+			continue
+		}
+
+		// add condition
+		merged[key] = len(result)
+		result = append(result, cond)
+	}
+
+	// convert merged single value in
+	for i, cond := range result {
+		result[i] = simplifyCondition(cond)
+	}
+
+	// return conditions
+	return result
+}
+
+// normalize converts the condition into clauses of predicates, a conjunction
+// of disjunctions for CNF and a disjunction of conjunctions for DNF. It reports
+// false if the conversion exceeds maxNormalFormClauses.
+func normalize(cond domain.Condition, cnf bool) ([][]domain.Condition, bool) {
+	// push negation down, negated predicates are kept as they are
+	if cond.Operator == domain.OperatorNot {
+		negated := negate(cond.Value.([]domain.Condition))
+		if negated.Operator == domain.OperatorNot {
+			return [][]domain.Condition{{negated}}, true
+		}
+		return normalize(negated, cnf)
+	}
+
+	// predicate
+	nested, ok := cond.Value.([]domain.Condition)
+	if !ok {
+		return [][]domain.Condition{{cond}}, true
+	}
+
+	// outer operator of the clauses: And for CNF, Or for DNF
+	outer := cond.Operator == domain.OperatorAnd
+	if !cnf {
+		outer = cond.Operator == domain.OperatorOr
+	}
+
+	// outer group concatenates clauses
+	if outer {
+		var clauses [][]domain.Condition
+		for _, c := range nested {
+			sub, ok := normalize(c, cnf)
+			if !ok {
+				return nil, false
+			}
+			clauses = append(clauses, sub...)
+			if len(clauses) > maxNormalFormClauses {
+				return nil, false
+			}
+		}
+		return clauses, true
+	}
+
+	// inner group distributes over clauses
+	clauses := [][]domain.Condition{{}}
+	for _, c := range nested {
+		sub, ok := normalize(c, cnf)
+		if !ok {
+			return nil, false
+		}
+
+		// cross product
+		var product [][]domain.Condition
+		for _, a := range clauses {
+			for _, b := range sub {
+				product = append(product, append(append([]domain.Condition{}, a...), b...))
+			}
+		}
+		if len(product) > maxNormalFormClauses {
+			return nil, false
+		}
+		clauses = product
+	}
+	return clauses, true
+}
+
+// negate returns the negation of the conjunction of the conditions, with the
+// negation pushed down by De Morgan's laws. Comparisons are negated into the
+// opposite comparison, other predicates are wrapped in Not.
+func negate(conds []domain.Condition) domain.Condition {
+	// negate conditions
+	negated := make([]domain.Condition, len(conds))
+	for i, cond := range conds {
+		negated[i] = negateCondition(cond)
+	}
+
+	// not (a and b) is (not a or not b)
+	return unwrapGroup(domain.OperatorOr, negated)
+}
+
+// negateCondition returns the negation of the condition.
+func negateCondition(cond domain.Condition) domain.Condition {
+	switch cond.Operator {
+	case domain.OperatorNot:
+		return unwrapGroup(domain.OperatorAnd, cond.Value.([]domain.Condition))
+	case domain.OperatorAnd:
+		return negate(cond.Value.([]domain.Condition))
+	case domain.OperatorOr:
+		// not (a or b) is (not a and not b)
+		nested := cond.Value.([]domain.Condition)
+		negated := make([]domain.Condition, len(nested))
+		for i, c := range nested {
+			negated[i] = negateCondition(c)
+		}
+		return unwrapGroup(domain.OperatorAnd, negated)
+	}
+
+	// opposite comparison
+	if op, ok := negatedOperators[cond.Operator]; ok {
+		// ordering comparisons with null are kept wrapped
+		if !isNullValue(cond.Value) || op == domain.OperatorEqual || op == domain.OperatorNotEqual {
+			cond.Operator = op
+			return cond
+		}
+	}
+
+	// wrap predicate
+	return Not(cond)
+}
+
+// negatedOperators is a map that defines the negation of comparison OperatorTypes.
+var negatedOperators = map[domain.OperatorType]domain.OperatorType{
+	domain.OperatorEqual:              domain.OperatorNotEqual,
+	domain.OperatorNotEqual:           domain.OperatorEqual,
+	domain.OperatorLessThan:           domain.OperatorGreaterThanOrEqual,
+	domain.OperatorGreaterThanOrEqual: domain.OperatorLessThan,
+	domain.OperatorGreaterThan:        domain.OperatorLessThanOrEqual,
+	domain.OperatorLessThanOrEqual:    domain.OperatorGreaterThan,
+}
+
+// fromClauses creates conditions from the clauses, each clause becoming a
+// group of the given operator.
+func fromClauses(clauses [][]domain.Condition, op domain.OperatorType) []domain.Condition {
+	// conditions
+	result := make([]domain.Condition, len(clauses))
+	for i, clause := range clauses {
+		result[i] = unwrapGroup(op, clause)
+	}
+
+	// return conditions
+	return result
+}
+
+// containsCondition reports whether the conditions contain an equal condition.
+func containsCondition(conds []domain.Condition, cond domain.Condition) bool {
+	for _, c := range conds {
+		if equalConditions(c, cond) {
+			return true
+		}
+	}
+	return false
+}
+
+// equalConditions reports whether the conditions are equal, comparing fields
+// by DB name, aggregation and level, and values deeply.
+func equalConditions(a, b domain.Condition) bool {
+	// compare operators
+	if a.Operator != b.Operator {
+		return false
+	}
+
+	// compare nested conditions
+	an, aok := a.Value.([]domain.Condition)
+	bn, bok := b.Value.([]domain.Condition)
+	if aok || bok {
+		if !aok || !bok || len(an) != len(bn) {
+			return false
+		}
+		for i := range an {
+			if !equalConditions(an[i], bn[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// compare fields and values
+	return fieldKey(a.Field) == fieldKey(b.Field) && reflect.DeepEqual(a.Value, b.Value)
+}
+
+// fieldKey returns a key identifying the field by DB name, aggregation and
+// level.
+func fieldKey(field *domain.Field) string {
+	if field == nil {
+		return ""
+	}
+	return field.DB + "\x00" + aggregationNames[field.Aggregation] + "\x00" + strconv.FormatFloat(field.Level, 'g', -1, 64)
+}
+
+// inValues returns the values of an IN condition.
+func inValues(value any) []any {
+	// any values
+	if values, ok := value.([]any); ok {
+		return values
+	}
+
+	// other slices
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice {
+		return []any{value}
+	}
+	values := make([]any, v.Len())
+	for i := range values {
+		values[i] = v.Index(i).Interface()
+	}
+	return values
+}
+
+// uniqueValues returns the values without duplicates, keeping their order.
+func uniqueValues(values []any) []any {
+	result := make([]any, 0, len(values))
+	for _, v := range values {
+		duplicate := false
+		for _, r := range result {
+			if reflect.DeepEqual(v, r) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// isNullValue reports whether the value is domain.ValueNull.
+func isNullValue(value any) bool {
+	v, ok := value.(domain.ValueType)
+	return ok && v == domain.ValueNull
+}
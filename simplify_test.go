@@ -0,0 +1,204 @@
+package qbr
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+func TestSimplify(t *testing.T) {
+	a := NewField(WithDB("a"))
+	b := NewField(WithDB("b"))
+	c := NewField(WithDB("c"))
+	median := NewQuantileField(a, 0.5)
+	p90 := NewQuantileField(a, 0.9)
+
+	// conditions exceeding the clause limit in dnf, 2^9 clauses
+	var wide []domain.Condition
+	for i := 0; i < 9; i++ {
+		wide = append(wide, Or(
+			Eq(NewField(WithDB(fmt.Sprintf("x%d", i))), 1),
+			Eq(NewField(WithDB(fmt.Sprintf("y%d", i))), 2),
+		))
+	}
+
+	tests := []struct {
+		name    string
+		conds   []domain.Condition
+		options []SimplifyOption
+		want    []domain.Condition
+	}{
+		{
+			name:  "empty",
+			conds: nil,
+			want:  []domain.Condition{},
+		},
+		{
+			name:  "flatten and groups",
+			conds: []domain.Condition{And(Eq(a, 1), And(Eq(b, 2))), Eq(c, 3)},
+			want:  []domain.Condition{Eq(a, 1), Eq(b, 2), Eq(c, 3)},
+		},
+		{
+			name:  "flatten or groups",
+			conds: []domain.Condition{Or(Gt(a, 1), Or(Gt(b, 2), Gt(c, 3)))},
+			want:  []domain.Condition{Or(Gt(a, 1), Gt(b, 2), Gt(c, 3))},
+		},
+		{
+			name:  "unwrap single condition groups",
+			conds: []domain.Condition{Or(And(Gt(a, 1)))},
+			want:  []domain.Condition{Gt(a, 1)},
+		},
+		{
+			name:  "remove double negation",
+			conds: []domain.Condition{Not(Not(Gt(a, 1), Gt(b, 2)))},
+			want:  []domain.Condition{Gt(a, 1), Gt(b, 2)},
+		},
+		{
+			name:  "drop duplicates",
+			conds: []domain.Condition{Gt(a, 1), Or(Lt(b, 2), Lt(b, 2)), Gt(a, 1)},
+			want:  []domain.Condition{Gt(a, 1), Lt(b, 2)},
+		},
+		{
+			name:  "single value in",
+			conds: []domain.Condition{In(a, 1)},
+			want:  []domain.Condition{Eq(a, 1)},
+		},
+		{
+			name:  "single nil in is kept",
+			conds: []domain.Condition{In(a, nil)},
+			want:  []domain.Condition{In(a, nil)},
+		},
+		{
+			name:  "duplicate in values",
+			conds: []domain.Condition{In(a, 1, 2, 1)},
+			want:  []domain.Condition{In(a, 1, 2)},
+		},
+		{
+			name:  "merge equalities into in",
+			conds: []domain.Condition{Or(Eq(a, 1), Eq(b, 2), Eq(a, 3), In(a, 3, 4))},
+			want:  []domain.Condition{Or(In(a, 1, 3, 4), Eq(b, 2))},
+		},
+		{
+			name:  "merge into single value",
+			conds: []domain.Condition{Or(Eq(a, 1), In(a, 1))},
+			want:  []domain.Condition{Eq(a, 1)},
+		},
+		{
+			name:  "null checks are not merged",
+			conds: []domain.Condition{Or(Eq(a, domain.ValueNull), Eq(a, 1))},
+			want:  []domain.Condition{Or(Eq(a, domain.ValueNull), Eq(a, 1))},
+		},
+		{
+			name:  "quantile levels are different fields",
+			conds: []domain.Condition{Or(Eq(median, 1), Eq(p90, 2))},
+			want:  []domain.Condition{Or(Eq(median, 1), Eq(p90, 2))},
+		},
+		{
+			name:  "quantile of the same level is merged",
+			conds: []domain.Condition{Or(Eq(median, 1), Eq(NewQuantileField(a, 0.5), 2))},
+			want:  []domain.Condition{In(median, 1, 2)},
+		},
+		{
+			name:    "cnf",
+			conds:   []domain.Condition{Or(And(Eq(a, 1), Eq(b, 2)), Gt(c, 3))},
+			options: []SimplifyOption{SimplifyCNF()},
+			want:    []domain.Condition{Or(Eq(a, 1), Gt(c, 3)), Or(Eq(b, 2), Gt(c, 3))},
+		},
+		{
+			name:    "cnf pushes negation down",
+			conds:   []domain.Condition{Not(Or(Eq(a, 1), Gt(b, 2)))},
+			options: []SimplifyOption{SimplifyCNF()},
+			want:    []domain.Condition{NoEq(a, 1), LtOrEq(b, 2)},
+		},
+		{
+			name:    "cnf keeps negated predicates",
+			conds:   []domain.Condition{Not(Like(a, "x%")), Not(Gt(b, domain.ValueNull))},
+			options: []SimplifyOption{SimplifyCNF()},
+			want:    []domain.Condition{Not(Like(a, "x%")), Not(Gt(b, domain.ValueNull))},
+		},
+		{
+			name:    "dnf",
+			conds:   []domain.Condition{Or(Gt(a, 1), Gt(b, 2)), Gt(c, 3)},
+			options: []SimplifyOption{SimplifyDNF()},
+			want:    []domain.Condition{Or(And(Gt(a, 1), Gt(c, 3)), And(Gt(b, 2), Gt(c, 3)))},
+		},
+		{
+			name:    "dnf pushes negation down",
+			conds:   []domain.Condition{Not(Gt(a, 1), Lt(b, 2))},
+			options: []SimplifyOption{SimplifyDNF()},
+			want:    []domain.Condition{Or(LtOrEq(a, 1), GtOrEq(b, 2))},
+		},
+		{
+			name:    "dnf over clause limit is skipped",
+			conds:   wide,
+			options: []SimplifyOption{SimplifyDNF()},
+			want:    wide,
+		},
+		{
+			name:    "cnf within clause limit",
+			conds:   wide,
+			options: []SimplifyOption{SimplifyCNF()},
+			want:    wide,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// keep original conditions
+			original := cloneConditions(tt.conds)
+
+			// simplify conditions
+			got := Simplify(tt.conds, tt.options...)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Simplify() = %#v, want %#v", got, tt.want)
+			}
+
+			// check input is not modified
+			if !reflect.DeepEqual(tt.conds, original) {
+				t.Errorf("Simplify() modified the input conditions")
+			}
+		})
+	}
+}
+
+func TestSimplifyWhere(t *testing.T) {
+	a := NewField(WithDB("a"))
+	b := NewField(WithDB("b"))
+
+	tests := []struct {
+		name string
+		qb   *Query
+		want string
+		args []any
+	}{
+		{
+			name: "simplified",
+			qb:   NewRead().Where(Or(Eq(a, 1), Eq(a, 2)), And(In(b, 3))).SimplifyWhere(),
+			want: "SELECT * FROM users WHERE a IN ($1, $2) AND b = $3",
+			args: []any{1, 2, 3},
+		},
+		{
+			name: "single nil in keeps never matching",
+			qb:   NewRead().Where(In(a, nil)).SimplifyWhere(),
+			want: "SELECT * FROM users WHERE a IN ($1)",
+			args: []any{nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, args, err := tt.qb.ToSql("users", SqlDollar)
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ToSql() = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %#v, want %#v", args, tt.args)
+			}
+		})
+	}
+}
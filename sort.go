@@ -27,6 +27,9 @@ func NewSortAsc(field *domain.Field) *domain.Sort {
 
 // Sort add sort.
 func (qb *Query) Sort(sorts ...*domain.Sort) *Query {
+	// copy immutable query
+	qb = qb.mutable()
+
 	// add sorts to query
 	for _, sort := range sorts {
 		qb.sort = append(qb.sort, *sort)
@@ -38,5 +41,12 @@ func (qb *Query) Sort(sorts ...*domain.Sort) *Query {
 
 // GetSort returns the sort parameters of the query, or an empty slice if no order by has been set.
 func (qb *Query) GetSort() []domain.Sort {
-	return qb.sort
+	// sorts for returning
+	sorts := make([]domain.Sort, len(qb.sort))
+
+	// copy query sorts
+	copy(sorts, qb.sort)
+
+	// return copy sorts
+	return sorts
 }
@@ -11,22 +11,65 @@ import (
 const (
 	SqlDollar   domain.SqlPlaceholder = "$"
 	SqlQuestion domain.SqlPlaceholder = "?"
+	SqlAtP      domain.SqlPlaceholder = "@p"
+)
+
+// SqlDialect is a dialect type for SQL queries.
+const (
+	SqlPostgres   domain.SqlDialect = "postgres"
+	SqlMySQL      domain.SqlDialect = "mysql"
+	SqlSQLite     domain.SqlDialect = "sqlite"
+	SqlSQLServer  domain.SqlDialect = "sqlserver"
+	SqlClickHouse domain.SqlDialect = "clickhouse"
 )
 
 // ToSql builds SQL query from the query builder data and returns it as a string, along with the query parameters and an error if the query could not be built.
 //
 // It supports the following query types: SELECT, INSERT, UPDATE, DELETE.
+// The query is built without dialect specific translations, use ToSqlDialect
+// to target a specific database.
 func (qb *Query) ToSql(table string, placeholder domain.SqlPlaceholder) (string, []any, error) {
+	return qb.toSql(table, placeholder, "")
+}
+
+// ToSqlDialect builds SQL query for the given dialect, using the dialect's
+// placeholder and translating dialect specific clauses, such as RETURNING.
+//
+// It returns an error if the dialect is not supported or the query uses a
+// feature that the dialect does not support.
+func (qb *Query) ToSqlDialect(table string, dialect domain.SqlDialect) (string, []any, error) {
+	// get dialect placeholder
+	placeholder, err := sqlbuilder.GetDialectPlaceholder(dialect)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// build query
+	return qb.toSql(table, placeholder, dialect)
+}
+
+// toSql builds SQL query with the given placeholder and dialect.
+func (qb *Query) toSql(table string, placeholder domain.SqlPlaceholder, dialect domain.SqlDialect) (string, []any, error) {
+	// validate table
+	if err := validateTable(table); err != nil {
+		return "", nil, err
+	}
+
+	// validate query
+	if err := qb.Validate(); err != nil {
+		return "", nil, err
+	}
+
 	// select need method for build
 	switch qb.operation {
 	case domain.OperationRead:
-		return sqlbuilder.CreateSelectSql(qb, table, placeholder)
+		return sqlbuilder.CreateSelectSql(qb, table, placeholder, dialect)
 	case domain.OperationCreate:
-		return sqlbuilder.CreateInsertSql(qb, table, placeholder)
+		return sqlbuilder.CreateInsertSql(qb, table, placeholder, dialect)
 	case domain.OperationUpdate:
-		return sqlbuilder.CreateUpdateSql(qb, table, placeholder)
+		return sqlbuilder.CreateUpdateSql(qb, table, placeholder, dialect)
 	case domain.OperationDelete:
-		return sqlbuilder.CreateDeleteSql(qb, table, placeholder)
+		return sqlbuilder.CreateDeleteSql(qb, table, placeholder, dialect)
 	default:
 		return "", nil, fmt.Errorf("unsupported query type: %v", qb.operation)
 	}
@@ -0,0 +1,114 @@
+package qbr
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+func TestToSqlDialect(t *testing.T) {
+	id := NewField(WithDB("id"))
+	name := NewField(WithDB("name"))
+
+	tests := []struct {
+		name    string
+		qb      *Query
+		dialect domain.SqlDialect
+		want    string
+		args    []any
+		wantErr bool
+	}{
+		{
+			name:    "postgres ilike",
+			qb:      NewRead().Where(ILike(name, "a%")),
+			dialect: SqlPostgres,
+			want:    `SELECT * FROM users WHERE name ILIKE $1 ESCAPE '\'`,
+			args:    []any{"a%"},
+		},
+		{
+			name:    "mysql ilike",
+			qb:      NewRead().Where(ILike(name, "a%")),
+			dialect: SqlMySQL,
+			want:    "SELECT * FROM users WHERE LOWER(name) LIKE LOWER(?)",
+			args:    []any{"a%"},
+		},
+		{
+			name:    "sqlite ilike",
+			qb:      NewRead().Where(Or(Eq(id, 1), ILike(name, "a%"))),
+			dialect: SqlSQLite,
+			want:    `SELECT * FROM users WHERE (id = ? OR LOWER(name) LIKE LOWER(?) ESCAPE '\')`,
+			args:    []any{1, "a%"},
+		},
+		{
+			name:    "sqlserver ilike",
+			qb:      NewDelete().Where(ILike(name, "a%")).NoReturning(),
+			dialect: SqlSQLServer,
+			want:    `DELETE FROM users WHERE LOWER(name) LIKE LOWER(@p1) ESCAPE '\'`,
+			args:    []any{"a%"},
+		},
+		{
+			name:    "postgres like",
+			qb:      NewRead().Where(Like(name, EscapeLike("50%_a\\")+"%")),
+			dialect: SqlPostgres,
+			want:    `SELECT * FROM users WHERE name LIKE $1 ESCAPE '\'`,
+			args:    []any{`50\%\_a\\%`},
+		},
+		{
+			name:    "neutral like",
+			qb:      NewRead().Where(Like(name, "a%")),
+			dialect: "",
+			want:    "SELECT * FROM users WHERE name LIKE $1",
+			args:    []any{"a%"},
+		},
+		{
+			name:    "mysql like",
+			qb:      NewRead().Where(Like(name, "a%")),
+			dialect: SqlMySQL,
+			want:    "SELECT * FROM users WHERE name LIKE ?",
+			args:    []any{"a%"},
+		},
+		{
+			name:    "clickhouse ilike",
+			qb:      NewRead().Where(ILike(name, "a%")),
+			dialect: SqlClickHouse,
+			want:    "SELECT * FROM users WHERE ilike(name, ?)",
+			args:    []any{"a%"},
+		},
+		{
+			name:    "clickhouse like",
+			qb:      NewRead().Where(Like(name, "a%")),
+			dialect: SqlClickHouse,
+			want:    "SELECT * FROM users WHERE name LIKE ?",
+			args:    []any{"a%"},
+		},
+		{
+			name:    "unsupported dialect",
+			qb:      NewRead(),
+			dialect: "oracle",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			var args []any
+			var err error
+			if tt.dialect == "" {
+				got, args, err = tt.qb.ToSql("users", SqlDollar)
+			} else {
+				got, args, err = tt.qb.ToSqlDialect("users", tt.dialect)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ToSqlDialect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ToSqlDialect() = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %#v, want %#v", args, tt.args)
+			}
+		})
+	}
+}
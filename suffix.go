@@ -2,7 +2,13 @@ package qbr
 
 // Suffix adds a suffix to the query builder.
 func (q *Query) Suffix(s string) *Query {
+	// copy immutable query
+	q = q.mutable()
+
+	// set suffix
 	q.suffix = s
+
+	// return query
 	return q
 }
 
@@ -0,0 +1,295 @@
+package qbr
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+// Table describes the database table of a struct type.
+//
+// It is collected from the struct by TableOf and cached per type.
+type Table struct {
+	Name       string         // Table name.
+	Schema     string         // Table schema, empty for the default schema.
+	Fields     []domain.Field // All mapped fields in column order.
+	PrimaryKey []domain.Field // Primary key fields, tagged with qbr:"pk".
+	Generated  []domain.Field // Generated fields, tagged with qbr:"generated".
+
+	typ     reflect.Type // struct type
+	pkIndex [][]int      // primary key fields index paths
+}
+
+// TableNamer is implemented by structs that declare their table name.
+//
+// The returned name may be qualified with a schema, e.g. "public.users".
+type TableNamer interface {
+	TableName() string
+}
+
+// tables caches table descriptors by struct type.
+var tables sync.Map
+
+// TableOf returns the table descriptor of the given struct or pointer to struct.
+//
+// The table name is taken from the TableName method if the struct implements
+// TableNamer, otherwise from a qbr:"table=<name>" annotation on a blank field:
+//
+//	type User struct {
+//		_  struct{} `qbr:"table=users schema=public"`
+//		ID int64    `db:"id" qbr:"pk generated"`
+//	}
+//
+// Primary key columns are declared with qbr:"pk" and generated columns with
+// qbr:"generated". It returns an error if s is not a struct or has no table name.
+func TableOf(s any) (*Table, error) {
+	// struct type
+	t := reflect.TypeOf(s)
+	if t == nil {
+		return nil, fmt.Errorf("unsupported table type: %v", t)
+	}
+
+	// dereference pointer
+	t = derefType(t)
+
+	// check is struct
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("unsupported table type: %s", t)
+	}
+
+	// get cached table
+	if tbl, ok := tables.Load(t); ok {
+		return tbl.(*Table), nil
+	}
+
+	// build table
+	tbl, err := buildTable(t)
+	if err != nil {
+		return nil, err
+	}
+
+	// cache table
+	actual, _ := tables.LoadOrStore(t, tbl)
+
+	// return table
+	return actual.(*Table), nil
+}
+
+// buildTable collects the table descriptor of struct type t.
+func buildTable(t reflect.Type) (*Table, error) {
+	// create table
+	tbl := &Table{
+		typ: t,
+	}
+
+	// get table name from method
+	if namer, ok := reflect.New(t).Interface().(TableNamer); ok {
+		tbl.Name = namer.TableName()
+	}
+
+	// get table name from annotations
+	for i := 0; i < t.NumField(); i++ {
+		// field type
+		ft := t.Field(i)
+
+		// check is blank field
+		if ft.Name != "_" {
+			continue
+		}
+
+		// get table name
+		if v := getAnnotationValue(ft, domain.QueryTable); v != "" && tbl.Name == "" {
+			tbl.Name = v
+		}
+
+		// get schema
+		if v := getAnnotationValue(ft, domain.QuerySchema); v != "" {
+			tbl.Schema = v
+		}
+	}
+
+	// split qualified name
+	if schema, name, ok := strings.Cut(tbl.Name, "."); ok {
+		tbl.Schema, tbl.Name = schema, name
+	}
+
+	// check is table name exists
+	if tbl.Name == "" {
+		return nil, fmt.Errorf("table name is not declared for type: %s", t)
+	}
+
+	// get struct mapping
+	mapping := getStructMapping(t)
+
+	// collect fields
+	for _, field := range mapping.fields {
+		// add field
+		tbl.Fields = append(tbl.Fields, field)
+
+		// get struct field
+		col := mapping.columns[field.DB]
+		ft := t.FieldByIndex(col.index)
+
+		// add primary key
+		if hasAnnotation(ft, domain.QueryPK) {
+			tbl.PrimaryKey = append(tbl.PrimaryKey, field)
+			tbl.pkIndex = append(tbl.pkIndex, col.index)
+		}
+
+		// add generated
+		if hasAnnotation(ft, domain.QueryGenerated) {
+			tbl.Generated = append(tbl.Generated, field)
+		}
+	}
+
+	// return table
+	return tbl, nil
+}
+
+// FullName returns the table name qualified with its schema, if any.
+func (t *Table) FullName() string {
+	// no schema
+	if t.Schema == "" {
+		return t.Name
+	}
+
+	// qualified name
+	return t.Schema + "." + t.Name
+}
+
+// PrimaryKeyConditions returns equality conditions for the primary key fields
+// of the table with the given values, in primary key order. It returns an error
+// if the table has no primary key or the number of values does not match.
+func (t *Table) PrimaryKeyConditions(values ...any) ([]domain.Condition, error) {
+	// check is primary key exists
+	if len(t.PrimaryKey) == 0 {
+		return nil, fmt.Errorf("primary key is not declared for table: %s", t.FullName())
+	}
+
+	// check values count
+	if len(values) != len(t.PrimaryKey) {
+		return nil, fmt.Errorf(
+			"primary key of table %s has %d fields, got %d values",
+			t.FullName(), len(t.PrimaryKey), len(values),
+		)
+	}
+
+	// create conditions
+	conds := make([]domain.Condition, len(values))
+	for i, v := range values {
+		conds[i] = Eq(&t.PrimaryKey[i], v)
+	}
+
+	// return conditions
+	return conds, nil
+}
+
+// PrimaryKeyValues returns the primary key values of the given entity, which
+// must be a struct or pointer to struct of the table's type.
+func (t *Table) PrimaryKeyValues(entity any) ([]any, error) {
+	// struct value
+	val := reflect.ValueOf(entity)
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+
+	// check is table type
+	if !val.IsValid() || val.Type() != t.typ {
+		return nil, fmt.Errorf("entity of type %T does not belong to table: %s", entity, t.FullName())
+	}
+
+	// get values
+	values := make([]any, len(t.pkIndex))
+	for i, index := range t.pkIndex {
+		// get field value
+		field, err := val.FieldByIndexErr(index)
+		if err != nil {
+			return nil, err
+		}
+
+		// add value
+		values[i] = field.Interface()
+	}
+
+	// return values
+	return values, nil
+}
+
+// Data returns Data objects for all mapped fields of the given entity, which
+// must be a struct or pointer to struct of the table's type. Unlike SetStruct,
+// zero values are accepted, so the result describes the full row.
+func (t *Table) Data(entity any) ([]*domain.Data, error) {
+	// struct value
+	val := reflect.ValueOf(entity)
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+
+	// check is table type
+	if !val.IsValid() || val.Type() != t.typ {
+		return nil, fmt.Errorf("entity of type %T does not belong to table: %s", entity, t.FullName())
+	}
+
+	// get struct mapping
+	mapping := getStructMapping(t.typ)
+
+	// create data
+	data := make([]*domain.Data, 0, len(t.Fields))
+	for i := range t.Fields {
+		// get field value
+		field, err := val.FieldByIndexErr(mapping.columns[t.Fields[i].DB].index)
+		if err != nil {
+			return nil, err
+		}
+
+		// add data
+		data = append(data, NewData(&t.Fields[i], field.Interface()))
+	}
+
+	// return data
+	return data, nil
+}
+
+// WherePK adds equality conditions on the primary key fields of the given
+// entity, using its current field values. The table is inferred from the
+// entity type as by TableOf. If the conditions cannot be created, the error is
+// returned when the query is built. Returns the modified QueryBuilder instance
+// for method chaining.
+func (qb *Query) WherePK(entity any) *Query {
+	// get table
+	tbl, err := TableOf(entity)
+	if err != nil {
+		return qb.setError(err)
+	}
+
+	// get primary key values
+	values, err := tbl.PrimaryKeyValues(entity)
+	if err != nil {
+		return qb.setError(err)
+	}
+
+	// create conditions
+	conds, err := tbl.PrimaryKeyConditions(values...)
+	if err != nil {
+		return qb.setError(err)
+	}
+
+	// add conditions
+	return qb.Where(conds...)
+}
+
+// ToSqlFor builds SQL query like ToSql, inferring the table name from the given
+// struct or pointer to struct as by TableOf.
+func (qb *Query) ToSqlFor(s any, placeholder domain.SqlPlaceholder) (string, []any, error) {
+	// get table
+	tbl, err := TableOf(s)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// build query
+	return qb.ToSql(tbl.FullName(), placeholder)
+}
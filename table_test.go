@@ -0,0 +1,236 @@
+package qbr
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+type tableUser struct {
+	_       struct{} `qbr:"table=users schema=auth"`
+	ID      int64    `db:"id" qbr:"pk generated"`
+	Name    string   `db:"name"`
+	Created string   `db:"created_at" qbr:"generated"`
+}
+
+type tableMember struct {
+	_      struct{} `qbr:"table=ignored"`
+	TeamID int64    `db:"team_id" qbr:"pk"`
+	UserID int64    `db:"user_id" qbr:"pk"`
+	Role   string   `db:"role"`
+}
+
+// TableName overrides the table annotation.
+func (tableMember) TableName() string {
+	return "org.members"
+}
+
+type tableNoPK struct {
+	_    struct{} `qbr:"table=logs"`
+	Line string   `db:"line"`
+}
+
+type tableNoName struct {
+	ID int64 `db:"id"`
+}
+
+func TestTableOf(t *testing.T) {
+	tests := []struct {
+		name       string
+		s          any
+		fullName   string
+		fields     []string
+		primaryKey []string
+		generated  []string
+		wantErr    bool
+	}{
+		{
+			name:       "annotation with schema",
+			s:          tableUser{},
+			fullName:   "auth.users",
+			fields:     []string{"id", "name", "created_at"},
+			primaryKey: []string{"id"},
+			generated:  []string{"id", "created_at"},
+		},
+		{
+			name:       "table name method with schema",
+			s:          &tableMember{},
+			fullName:   "org.members",
+			fields:     []string{"team_id", "user_id", "role"},
+			primaryKey: []string{"team_id", "user_id"},
+		},
+		{
+			name:     "no primary key",
+			s:        tableNoPK{},
+			fullName: "logs",
+			fields:   []string{"line"},
+		},
+		{name: "no table name", s: tableNoName{}, wantErr: true},
+		{name: "not struct", s: 1, wantErr: true},
+		{name: "nil", s: nil, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tbl, err := TableOf(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TableOf() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			// field names
+			names := func(fields []domain.Field) []string {
+				var s []string
+				for _, f := range fields {
+					s = append(s, f.DB)
+				}
+				return s
+			}
+
+			if got := tbl.FullName(); got != tt.fullName {
+				t.Errorf("FullName() = %q, want %q", got, tt.fullName)
+			}
+			if got := names(tbl.Fields); !reflect.DeepEqual(got, tt.fields) {
+				t.Errorf("Fields = %v, want %v", got, tt.fields)
+			}
+			if got := names(tbl.PrimaryKey); !reflect.DeepEqual(got, tt.primaryKey) {
+				t.Errorf("PrimaryKey = %v, want %v", got, tt.primaryKey)
+			}
+			if got := names(tbl.Generated); !reflect.DeepEqual(got, tt.generated) {
+				t.Errorf("Generated = %v, want %v", got, tt.generated)
+			}
+		})
+	}
+}
+
+func TestPrimaryKeyConditions(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       any
+		values  []any
+		want    string
+		wantErr bool
+	}{
+		{name: "single", s: tableUser{}, values: []any{1}, want: "SELECT * FROM auth.users WHERE id = $1"},
+		{name: "composite", s: tableMember{}, values: []any{1, 2}, want: "SELECT * FROM org.members WHERE team_id = $1 AND user_id = $2"},
+		{name: "too few values", s: tableMember{}, values: []any{1}, wantErr: true},
+		{name: "too many values", s: tableUser{}, values: []any{1, 2}, wantErr: true},
+		{name: "no primary key", s: tableNoPK{}, values: []any{1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tbl, err := TableOf(tt.s)
+			if err != nil {
+				t.Fatalf("TableOf() error = %v", err)
+			}
+
+			conds, err := tbl.PrimaryKeyConditions(tt.values...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PrimaryKeyConditions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			got, _, err := NewRead().Where(conds...).ToSqlFor(tt.s, SqlDollar)
+			if err != nil {
+				t.Fatalf("ToSqlFor() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ToSqlFor() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWherePK(t *testing.T) {
+	tests := []struct {
+		name    string
+		entity  any
+		want    string
+		args    []any
+		wantErr bool
+	}{
+		{
+			name:   "single",
+			entity: tableUser{ID: 7},
+			want:   "DELETE FROM auth.users WHERE id = $1 RETURNING *",
+			args:   []any{int64(7)},
+		},
+		{
+			name:   "composite pointer",
+			entity: &tableMember{TeamID: 1, UserID: 2},
+			want:   "DELETE FROM org.members WHERE team_id = $1 AND user_id = $2 RETURNING *",
+			args:   []any{int64(1), int64(2)},
+		},
+		{name: "no primary key", entity: tableNoPK{}, wantErr: true},
+		{name: "no table name", entity: tableNoName{}, wantErr: true},
+		{name: "nil pointer", entity: (*tableUser)(nil), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, args, err := NewDelete().WherePK(tt.entity).ToSqlFor(tt.entity, SqlDollar)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ToSqlFor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ToSqlFor() = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %#v, want %#v", args, tt.args)
+			}
+		})
+	}
+}
+
+func TestToSqlForGenerated(t *testing.T) {
+	user := tableUser{ID: 1, Name: "ann", Created: "now"}
+
+	tests := []struct {
+		name string
+		qb   *Query
+		want string
+		args []any
+	}{
+		{
+			name: "create skips generated fields",
+			qb:   NewCreate().SetStruct(user),
+			want: "INSERT INTO auth.users (name) VALUES ($1) RETURNING *",
+			args: []any{"ann"},
+		},
+		{
+			name: "update skips generated fields",
+			qb:   NewUpdate().SetStruct(user).WherePK(user),
+			want: "UPDATE auth.users SET name = $1 WHERE id = $2 RETURNING *",
+			args: []any{"ann", int64(1)},
+		},
+		{
+			name: "read selects generated fields",
+			qb:   NewRead().SelectStruct(user),
+			want: "SELECT id, name, created_at FROM auth.users",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, args, err := tt.qb.ToSqlFor(user, SqlDollar)
+			if err != nil {
+				t.Fatalf("ToSqlFor() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ToSqlFor() = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %#v, want %#v", args, tt.args)
+			}
+		})
+	}
+}
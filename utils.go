@@ -65,7 +65,8 @@ func isFieldIgnored(field *domain.Field, queryType domain.OperationType) bool {
 // Additionally, the function checks for a "qbr" tag and parses any annotations
 // it contains. If the "qbr" tag includes an "ignore_on" annotation, the function
 // extracts the ignored operations and adds them to the Field's IgnoredOperations
-// slice.
+// slice. A "generated" annotation marks the field as ignored on create and
+// update, since generated columns are computed by the database.
 //
 // The resulting Field object is returned, representing a database field with
 // optional ignored operations based on the struct field's annotations.
@@ -105,6 +106,13 @@ func extractFieldFromStruct(ft reflect.StructField) *domain.Field {
 				field.IgnoreOn,
 				extractIgnoredOperationOnAnnotations(block)...,
 			)
+		case block == string(domain.QueryGenerated):
+			// generated columns are never written
+			field.IgnoreOn = append(
+				field.IgnoreOn,
+				domain.OperationCreate,
+				domain.OperationUpdate,
+			)
 		default:
 			continue
 		}
@@ -153,6 +161,11 @@ func extractDataFromStruct(s any) []*domain.Data {
 		// field type
 		ft := t.Field(i)
 
+		// skip unexported fields, such as the blank table annotation field
+		if !ft.IsExported() {
+			continue
+		}
+
 		// add data
 		data = append(data, NewData(
 			extractFieldFromStruct(ft),
@@ -165,6 +178,34 @@ func extractDataFromStruct(s any) []*domain.Data {
 	return data
 }
 
+// hasAnnotation reports whether the "qbr" tag of the given struct field contains
+// the specified flag annotation, such as "pk" or "generated".
+func hasAnnotation(ft reflect.StructField, annotation domain.QueryAnnotationType) bool {
+	// get annotations from query builder annotation
+	for _, block := range strings.Split(ft.Tag.Get(string(domain.QueryQbr)), " ") {
+		if block == string(annotation) {
+			return true
+		}
+	}
+
+	// annotation not found
+	return false
+}
+
+// getAnnotationValue returns the value of the "<annotation>=<value>" block in
+// the "qbr" tag of the given struct field, or an empty string if not found.
+func getAnnotationValue(ft reflect.StructField, annotation domain.QueryAnnotationType) string {
+	// get annotations from query builder annotation
+	for _, block := range strings.Split(ft.Tag.Get(string(domain.QueryQbr)), " ") {
+		if v, ok := strings.CutPrefix(block, string(annotation)+"="); ok {
+			return v
+		}
+	}
+
+	// annotation not found
+	return ""
+}
+
 // extractIgnoredOperationOnAnnotations extracts the ignored operations from the given block string.
 //
 // The block string is expected to be in the format "ignore_on=<operation1>,<operation2>,...".
@@ -0,0 +1,188 @@
+package qbr
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+
+	"github.com/tyrenix/qbr/domain"
+	"github.com/tyrenix/qbr/internal/sqlbuilder"
+)
+
+// identifierPart is a single plain or quoted SQL identifier.
+const identifierPart = `(?:[A-Za-z_][A-Za-z0-9_$]*|"[^"]+"|` + "`[^`]+`" + `|\[[^\]]+\])`
+
+// fieldIdentifierRegexp matches field names: qualified identifiers, optionally
+// ending with ".*", a single "*", or a numeric literal such as "1".
+var fieldIdentifierRegexp = regexp.MustCompile(
+	`^(?:\*|[0-9]+(?:\.[0-9]+)?|` + identifierPart + `(?:\.` + identifierPart + `)*(?:\.\*)?)$`,
+)
+
+// tableIdentifierRegexp matches table names: qualified identifiers with an
+// optional alias.
+var tableIdentifierRegexp = regexp.MustCompile(
+	`^` + identifierPart + `(?:\.` + identifierPart + `)*(?i:\s+(?:AS\s+)?` + identifierPart + `)?$`,
+)
+
+// Validate checks the query for structural problems that would produce
+// invalid SQL. It is run by ToSql before the query is built.
+//
+// It returns ErrNoData for create and update queries without data,
+// ErrFullTable for update and delete queries without effective conditions
+// unless AllowFullTable is set, ErrEmptyIn for IN conditions without values,
+// ErrUnsupportedOperator for unknown condition operators and
+// ErrInvalidIdentifier for invalid field names. The errors can be matched with
+// errors.Is and errors.As.
+func (qb *Query) Validate() error {
+	// check builder error
+	if qb.err != nil {
+		return qb.err
+	}
+
+	// check data for write queries
+	if (qb.operation == domain.OperationCreate || qb.operation == domain.OperationUpdate) && len(qb.data) == 0 {
+		return ErrNoData
+	}
+
+	// check conditions for update and delete queries
+	if (qb.operation == domain.OperationUpdate || qb.operation == domain.OperationDelete) &&
+		!qb.allowFullTable && !hasEffectiveConditions(qb.conditions) {
+		return ErrFullTable
+	}
+
+	// validate select and returning fields
+	for _, fields := range [][]domain.Field{qb.selects, qb.returning} {
+		for i := range fields {
+			if err := validateField(&fields[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	// validate data fields
+	for _, data := range qb.data {
+		if err := validateField(data.Field); err != nil {
+			return err
+		}
+
+		// validate modification field
+		if mod, ok := data.Value.(*domain.Modification); ok {
+			if err := validateField(mod.Field); err != nil {
+				return err
+			}
+		}
+	}
+
+	// validate sort fields
+	for _, sort := range qb.sort {
+		if err := validateField(sort.Field); err != nil {
+			return err
+		}
+	}
+
+	// validate limit by fields
+	for i := range qb.limitByFields {
+		if err := validateField(&qb.limitByFields[i]); err != nil {
+			return err
+		}
+	}
+
+	// validate prewhere conditions
+	if err := validateConditions(qb.prewhere); err != nil {
+		return err
+	}
+
+	// validate conditions
+	return validateConditions(qb.conditions)
+}
+
+// validateConditions recursively checks the operators, fields and values of
+// the given conditions.
+func validateConditions(conds []domain.Condition) error {
+	for _, cond := range conds {
+		// check is operator supported
+		if !sqlbuilder.IsOperatorSupported(cond.Operator) {
+			return ErrUnsupportedOperator{Op: cond.Operator}
+		}
+
+		// select operator
+		switch cond.Operator {
+		case domain.OperatorAnd, domain.OperatorOr, domain.OperatorNot:
+			// assert nested conditions
+			nested, ok := cond.Value.([]domain.Condition)
+			if !ok {
+				return fmt.Errorf("invalid value for logical operator %d", cond.Operator)
+			}
+
+			// validate nested conditions
+			if err := validateConditions(nested); err != nil {
+				return err
+			}
+		default:
+			// validate field
+			if err := validateField(cond.Field); err != nil {
+				return err
+			}
+
+			// check is in values not empty
+			if cond.Operator == domain.OperatorIn {
+				v := reflect.ValueOf(cond.Value)
+				if v.Kind() != reflect.Slice || v.Len() == 0 {
+					return fmt.Errorf("%w: %s", ErrEmptyIn, cond.Field.DB)
+				}
+			}
+		}
+	}
+
+	// conditions are valid
+	return nil
+}
+
+// hasEffectiveConditions reports whether the given conditions restrict the
+// affected rows, i.e. contain at least one simple condition. Logical
+// conditions without nested simple conditions are not effective.
+func hasEffectiveConditions(conds []domain.Condition) bool {
+	for _, cond := range conds {
+		// check nested conditions
+		if nested, ok := cond.Value.([]domain.Condition); ok {
+			if hasEffectiveConditions(nested) {
+				return true
+			}
+			continue
+		}
+
+		// simple condition
+		return true
+	}
+
+	// no effective conditions
+	return false
+}
+
+// validateField checks that the field exists and its DB name is a valid
+// identifier.
+func validateField(field *domain.Field) error {
+	// check is field exists
+	if field == nil {
+		return ErrInvalidIdentifier{}
+	}
+
+	// check identifier
+	if !fieldIdentifierRegexp.MatchString(field.DB) {
+		return ErrInvalidIdentifier{Identifier: field.DB}
+	}
+
+	// field is valid
+	return nil
+}
+
+// validateTable checks that the table name is a valid identifier.
+func validateTable(table string) error {
+	// check identifier
+	if !tableIdentifierRegexp.MatchString(table) {
+		return ErrInvalidIdentifier{Identifier: table}
+	}
+
+	// table is valid
+	return nil
+}
@@ -0,0 +1,55 @@
+package qbr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+func TestValidate(t *testing.T) {
+	id := NewField(WithDB("id"))
+	field := func(db string) *domain.Field { return NewField(WithDB(db)) }
+
+	tests := []struct {
+		name    string
+		qb      *Query
+		wantErr error
+	}{
+		{name: "read", qb: NewRead().Where(Eq(id, 1))},
+		{name: "numeric literal", qb: NewRead().Select(field("1")).Limit(1)},
+		{name: "qualified field", qb: NewRead().Select(field(`u."name"`), field("u.*"))},
+		{name: "all fields", qb: NewRead().Select(NewAllField())},
+		{name: "create without data", qb: NewCreate(), wantErr: ErrNoData},
+		{name: "update without data", qb: NewUpdate().Where(Eq(id, 1)), wantErr: ErrNoData},
+		{name: "update full table", qb: NewUpdate().Set(NewData(id, 1)), wantErr: ErrFullTable},
+		{name: "delete full table", qb: NewDelete(), wantErr: ErrFullTable},
+		{name: "delete empty or", qb: NewDelete().Where(Or()), wantErr: ErrFullTable},
+		{name: "delete allowed full table", qb: NewDelete().AllowFullTable()},
+		{name: "empty in", qb: NewRead().Where(In(id)), wantErr: ErrEmptyIn},
+		{name: "nested empty in", qb: NewRead().Where(Or(Eq(id, 1), In(id))), wantErr: ErrEmptyIn},
+		{
+			name:    "unsupported operator",
+			qb:      NewRead().Where(domain.Condition{Field: id, Operator: domain.OperatorType(-1)}),
+			wantErr: ErrUnsupportedOperator{Op: domain.OperatorType(-1)},
+		},
+		{
+			name:    "invalid field",
+			qb:      NewRead().Select(field("id; DROP TABLE users")),
+			wantErr: ErrInvalidIdentifier{Identifier: "id; DROP TABLE users"},
+		},
+		{
+			name:    "invalid expression",
+			qb:      NewRead().Select(field("1 OR 1")),
+			wantErr: ErrInvalidIdentifier{Identifier: "1 OR 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.qb.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package qbr
+
+import (
+	"errors"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+// SkipChildren is returned by a Visitor's Enter callback to skip the nested
+// conditions of a logical condition. It is not returned by Walk.
+var SkipChildren = errors.New("skip children")
+
+// WalkNode is a condition visited by Walk or rewritten by Rewrite, with its
+// position in the condition tree.
+type WalkNode struct {
+	Condition domain.Condition   // Visited condition.
+	Path      []int              // Indexes from the top-level slice down to the condition.
+	Parents   []domain.Condition // Logical conditions enclosing the condition, outermost first.
+}
+
+// Depth returns the nesting depth of the node, 0 for top-level conditions.
+func (n WalkNode) Depth() int {
+	return len(n.Path) - 1
+}
+
+// Parent returns the logical condition directly enclosing the node, or nil
+// for top-level conditions.
+func (n WalkNode) Parent() *domain.Condition {
+	if len(n.Parents) == 0 {
+		return nil
+	}
+	return &n.Parents[len(n.Parents)-1]
+}
+
+// IsLogical reports whether the node is a logical condition (And, Or or Not)
+// with nested conditions.
+func (n WalkNode) IsLogical() bool {
+	_, ok := n.Condition.Value.([]domain.Condition)
+	return ok
+}
+
+// Visitor holds the callbacks of Walk. Either callback can be nil.
+type Visitor struct {
+	// Enter is called for a condition before its nested conditions. Returning
+	// SkipChildren skips the nested conditions, and the Leave callback of the
+	// condition is still called. Returning another error stops the walk.
+	Enter func(node WalkNode) error
+	// Leave is called for a condition after its nested conditions. Returning an
+	// error stops the walk.
+	Leave func(node WalkNode) error
+}
+
+// Walk traverses the condition tree depth-first in order, calling the
+// visitor's Enter and Leave callbacks for every condition, including logical
+// conditions and their nested conditions. Unlike FilterMatchingConditions, the
+// And, Or and Not structure is kept visible through the node's path and
+// parents.
+//
+// It returns the first error returned by a callback, other than SkipChildren.
+func Walk(conds []domain.Condition, visitor Visitor) error {
+	return walkConditions(conds, visitor, nil, nil)
+}
+
+// walkConditions walks the conditions at the given path and parents.
+func walkConditions(conds []domain.Condition, visitor Visitor, path []int, parents []domain.Condition) error {
+	for i, cond := range conds {
+		// create node
+		node := WalkNode{
+			Condition: cond,
+			Path:      append(append([]int{}, path...), i),
+			Parents:   append([]domain.Condition{}, parents...),
+		}
+
+		// enter node
+		skip := false
+		if visitor.Enter != nil {
+			if err := visitor.Enter(node); err != nil {
+				if !errors.Is(err, SkipChildren) {
+					return err
+				}
+				skip = true
+			}
+		}
+
+		// walk nested conditions
+		if nested, ok := cond.Value.([]domain.Condition); ok && !skip {
+			if err := walkConditions(nested, visitor, node.Path, append(node.Parents, cond)); err != nil {
+				return err
+			}
+		}
+
+		// leave node
+		if visitor.Leave != nil {
+			if err := visitor.Leave(node); err != nil {
+				return err
+			}
+		}
+	}
+
+	// walk completed
+	return nil
+}
+
+// RewriteFunc is called by Rewrite for every condition and returns the
+// conditions that replace it: the condition itself to keep it, none to drop
+// it, a different condition to replace or wrap it, or several conditions to
+// splice them into the parent.
+type RewriteFunc func(node WalkNode) ([]domain.Condition, error)
+
+// Rewrite returns a new condition tree with every condition replaced by the
+// result of fn, keeping the And, Or and Not structure. The input conditions
+// are not modified: fn receives copies of the conditions, including their
+// fields and IN values, so it may modify them in place, e.g. to rename fields.
+//
+// Conditions are rewritten bottom-up: nested conditions of a logical condition
+// are rewritten first, and fn receives the logical condition with its
+// rewritten nested conditions. Logical conditions left without nested
+// conditions are dropped without calling fn. Conditions returned by fn are not
+// rewritten again.
+//
+// It returns the first error returned by fn.
+func Rewrite(conds []domain.Condition, fn RewriteFunc) ([]domain.Condition, error) {
+	return rewriteConditions(cloneConditions(conds), fn, nil, nil)
+}
+
+// rewriteConditions rewrites the conditions at the given path and parents.
+func rewriteConditions(conds []domain.Condition, fn RewriteFunc, path []int, parents []domain.Condition) ([]domain.Condition, error) {
+	// result
+	result := []domain.Condition{}
+	for i, cond := range conds {
+		// create node
+		node := WalkNode{
+			Condition: cond,
+			Path:      append(append([]int{}, path...), i),
+			Parents:   append([]domain.Condition{}, parents...),
+		}
+
+		// rewrite nested conditions
+		if nested, ok := cond.Value.([]domain.Condition); ok {
+			rewritten, err := rewriteConditions(nested, fn, node.Path, append(node.Parents, cond))
+			if err != nil {
+				return nil, err
+			}
+
+			// drop empty logical condition
+			if len(rewritten) == 0 {
+				continue
+			}
+			node.Condition.Value = rewritten
+		}
+
+		// rewrite condition
+		replaced, err := fn(node)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, replaced...)
+	}
+
+	// return conditions
+	return result, nil
+}
+
+// RewriteWhere rewrites the conditions of the query with Rewrite, including
+// the ClickHouse PREWHERE conditions. If fn returns an error, it is returned
+// when the query is built.
+//
+// Returns the modified QueryBuilder instance for method chaining.
+func (qb *Query) RewriteWhere(fn RewriteFunc) *Query {
+	// rewrite conditions
+	conds, err := Rewrite(qb.conditions, fn)
+	if err != nil {
+		return qb.setError(err)
+	}
+
+	// rewrite prewhere conditions
+	prewhere := qb.prewhere
+	if len(prewhere) > 0 {
+		if prewhere, err = Rewrite(prewhere, fn); err != nil {
+			return qb.setError(err)
+		}
+	}
+
+	// copy immutable query
+	qb = qb.mutable()
+
+	// set conditions
+	qb.conditions = conds
+	qb.prewhere = prewhere
+
+	// return query
+	return qb
+}
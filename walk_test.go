@@ -0,0 +1,292 @@
+package qbr
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/tyrenix/qbr/domain"
+)
+
+func TestWalk(t *testing.T) {
+	id := NewField(WithDB("id"))
+	name := NewField(WithDB("name"))
+	age := NewField(WithDB("age"))
+
+	conds := []domain.Condition{
+		Eq(id, 1),
+		Or(Eq(name, "a"), Not(Gt(age, 18))),
+	}
+	errStop := errors.New("stop")
+
+	tests := []struct {
+		name    string
+		enter   func(node WalkNode) error
+		leave   bool
+		want    []string
+		wantErr error
+	}{
+		{
+			name:  "enter and leave in order",
+			leave: true,
+			want: []string{
+				"enter [0] depth 0 eq id",
+				"leave [0] depth 0 eq id",
+				"enter [1] depth 0 or",
+				"enter [1 0] depth 1 eq name parent or",
+				"leave [1 0] depth 1 eq name parent or",
+				"enter [1 1] depth 1 not parent or",
+				"enter [1 1 0] depth 2 gt age parent not",
+				"leave [1 1 0] depth 2 gt age parent not",
+				"leave [1 1] depth 1 not parent or",
+				"leave [1] depth 0 or",
+			},
+		},
+		{
+			name: "skip children",
+			enter: func(node WalkNode) error {
+				if node.Condition.Operator == domain.OperatorNot {
+					return SkipChildren
+				}
+				return nil
+			},
+			leave: true,
+			want: []string{
+				"enter [0] depth 0 eq id",
+				"leave [0] depth 0 eq id",
+				"enter [1] depth 0 or",
+				"enter [1 0] depth 1 eq name parent or",
+				"leave [1 0] depth 1 eq name parent or",
+				"enter [1 1] depth 1 not parent or",
+				"leave [1 1] depth 1 not parent or",
+				"leave [1] depth 0 or",
+			},
+		},
+		{
+			name: "stop on error",
+			enter: func(node WalkNode) error {
+				if len(node.Path) > 1 {
+					return errStop
+				}
+				return nil
+			},
+			want: []string{
+				"enter [0] depth 0 eq id",
+				"enter [1] depth 0 or",
+				"enter [1 0] depth 1 eq name parent or",
+			},
+			wantErr: errStop,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// record visits
+			var got []string
+			record := func(event string, node WalkNode) {
+				s := fmt.Sprintf("%s %v depth %d %s", event, node.Path, node.Depth(), OperatorName(node.Condition.Operator))
+				if !node.IsLogical() {
+					s += " " + node.Condition.Field.DB
+				}
+				if parent := node.Parent(); parent != nil {
+					s += " parent " + OperatorName(parent.Operator)
+				}
+				got = append(got, s)
+			}
+
+			// create visitor
+			visitor := Visitor{
+				Enter: func(node WalkNode) error {
+					record("enter", node)
+					if tt.enter != nil {
+						return tt.enter(node)
+					}
+					return nil
+				},
+			}
+			if tt.leave {
+				visitor.Leave = func(node WalkNode) error {
+					record("leave", node)
+					return nil
+				}
+			}
+
+			// walk conditions
+			err := Walk(conds, visitor)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Walk() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Walk() visits = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	id := NewField(WithDB("id"))
+	name := NewField(WithDB("name"))
+	age := NewField(WithDB("age"))
+
+	conds := []domain.Condition{
+		Eq(id, 1),
+		Or(Eq(name, "a"), In(age, 1, 2)),
+	}
+	errRewrite := errors.New("rewrite")
+
+	tests := []struct {
+		name    string
+		fn      RewriteFunc
+		want    []domain.Condition
+		wantErr error
+	}{
+		{
+			name: "keep",
+			fn: func(node WalkNode) ([]domain.Condition, error) {
+				return []domain.Condition{node.Condition}, nil
+			},
+			want: conds,
+		},
+		{
+			name: "rename fields in place",
+			fn: func(node WalkNode) ([]domain.Condition, error) {
+				if !node.IsLogical() {
+					node.Condition.Field.DB = "u." + node.Condition.Field.DB
+				}
+				return []domain.Condition{node.Condition}, nil
+			},
+			want: []domain.Condition{
+				Eq(NewField(WithDB("u.id")), 1),
+				Or(Eq(NewField(WithDB("u.name")), "a"), In(NewField(WithDB("u.age")), 1, 2)),
+			},
+		},
+		{
+			name: "modify in values in place",
+			fn: func(node WalkNode) ([]domain.Condition, error) {
+				if values, ok := node.Condition.Value.([]any); ok {
+					values[0] = 3
+				}
+				return []domain.Condition{node.Condition}, nil
+			},
+			want: []domain.Condition{Eq(id, 1), Or(Eq(name, "a"), In(age, 3, 2))},
+		},
+		{
+			name: "drop and drop empty logical",
+			fn: func(node WalkNode) ([]domain.Condition, error) {
+				if node.Depth() > 0 {
+					return nil, nil
+				}
+				return []domain.Condition{node.Condition}, nil
+			},
+			want: []domain.Condition{Eq(id, 1)},
+		},
+		{
+			name: "wrap and splice",
+			fn: func(node WalkNode) ([]domain.Condition, error) {
+				switch {
+				case node.IsLogical():
+					return []domain.Condition{node.Condition}, nil
+				case node.Condition.Field.DB == "id":
+					return []domain.Condition{Not(node.Condition)}, nil
+				case node.Condition.Field.DB == "name":
+					return []domain.Condition{node.Condition, Eq(age, 3)}, nil
+				}
+				return []domain.Condition{node.Condition}, nil
+			},
+			want: []domain.Condition{
+				Not(Eq(id, 1)),
+				Or(Eq(name, "a"), Eq(age, 3), In(age, 1, 2)),
+			},
+		},
+		{
+			name: "parent has rewritten children",
+			fn: func(node WalkNode) ([]domain.Condition, error) {
+				if node.IsLogical() {
+					return node.Condition.Value.([]domain.Condition), nil
+				}
+				if node.Condition.Field.DB == "age" {
+					return nil, nil
+				}
+				return []domain.Condition{node.Condition}, nil
+			},
+			want: []domain.Condition{Eq(id, 1), Eq(name, "a")},
+		},
+		{
+			name: "error",
+			fn: func(node WalkNode) ([]domain.Condition, error) {
+				return nil, errRewrite
+			},
+			wantErr: errRewrite,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// keep original conditions
+			original := cloneConditions(conds)
+
+			// rewrite conditions
+			got, err := Rewrite(conds, tt.fn)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Rewrite() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Rewrite() = %#v, want %#v", got, tt.want)
+			}
+
+			// check input is not modified
+			if !reflect.DeepEqual(conds, original) || id.DB != "id" {
+				t.Errorf("Rewrite() modified the input conditions")
+			}
+		})
+	}
+}
+
+func TestRewriteWhere(t *testing.T) {
+	id := NewField(WithDB("id"))
+	name := NewField(WithDB("name"))
+
+	// rename fields
+	rename := func(node WalkNode) ([]domain.Condition, error) {
+		if !node.IsLogical() {
+			node.Condition.Field.DB = "u." + node.Condition.Field.DB
+		}
+		return []domain.Condition{node.Condition}, nil
+	}
+
+	tests := []struct {
+		name    string
+		qb      *Query
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "where and prewhere",
+			qb:   NewRead().Prewhere(Eq(name, "a")).Where(Eq(id, 1)).RewriteWhere(rename),
+			want: "SELECT * FROM users PREWHERE u.name = ? WHERE u.id = ?",
+		},
+		{
+			name: "error",
+			qb: NewRead().Where(Eq(id, 1)).RewriteWhere(func(node WalkNode) ([]domain.Condition, error) {
+				return nil, errors.New("rewrite")
+			}),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := tt.qb.ToSqlDialect("users", SqlClickHouse)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ToSqlDialect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ToSqlDialect() = %q, want %q", got, tt.want)
+			}
+			if id.DB != "id" || name.DB != "name" {
+				t.Errorf("RewriteWhere() modified the shared fields")
+			}
+		})
+	}
+}
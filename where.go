@@ -1,6 +1,10 @@
 package qbr
 
-import "github.com/tyrenix/qbr/domain"
+import (
+	"strings"
+
+	"github.com/tyrenix/qbr/domain"
+)
 
 // Or returns a condition that checks if any of the given conditions are true.
 //
@@ -22,6 +26,16 @@ func And(conds ...domain.Condition) domain.Condition {
 	}
 }
 
+// Not returns a condition that checks if the given conditions are not all true.
+//
+// NOT (conds1 AND conds2 AND conds3 and so on)
+func Not(conds ...domain.Condition) domain.Condition {
+	return domain.Condition{
+		Operator: domain.OperatorNot,
+		Value:    conds,
+	}
+}
+
 // Condition for equals.
 //
 // Eq returns a condition that checks if the value of the given field is equal to the given value.
@@ -99,11 +113,50 @@ func In(field *domain.Field, val ...any) domain.Condition {
 	}
 }
 
+// Like returns a condition that checks if the value of the given field matches the specified pattern.
+// A backslash escapes the following wildcard, see EscapeLike.
+//
+// field LIKE val
+func Like(field *domain.Field, val any) domain.Condition {
+	return domain.Condition{
+		Field:    field,
+		Operator: domain.OperatorLike,
+		Value:    val,
+	}
+}
+
+// likeEscaper escapes LIKE wildcards with a backslash.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// EscapeLike escapes the LIKE wildcards "%" and "_" and the backslash in s with a backslash, so that
+// s is matched literally when used in a Like or ILike pattern:
+//
+//	qbr.Like(nameField, qbr.EscapeLike(prefix)+"%")
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// ILike returns a condition that checks if the value of the given field matches the specified pattern,
+// ignoring case. ILIKE is supported by PostgreSQL, ClickHouse uses the ilike function and other
+// dialects compare lowercased values.
+//
+// field ILIKE val
+func ILike(field *domain.Field, val any) domain.Condition {
+	return domain.Condition{
+		Field:    field,
+		Operator: domain.OperatorILike,
+		Value:    val,
+	}
+}
+
 // Where adds the specified conditions to the QueryBuilder's conditions list.
 // If a condition's Value is nil or zero, it is ignored and not added.
 // Additionally, if the condition's Field is ignored for the current query type, it is also ignored and not added.
 // The method returns the modified QueryBuilder instance for method chaining.
 func (qb *Query) Where(conds ...domain.Condition) *Query {
+	// copy immutable query
+	qb = qb.mutable()
+
 	// add remove zero condition s
 	qb.conditions = append(
 		qb.conditions,
@@ -116,12 +169,9 @@ func (qb *Query) Where(conds ...domain.Condition) *Query {
 
 // GetConditions returns the conditions set for the query builder, or an empty slice if no conditions have been set.
 func (qb *Query) GetConditions() []domain.Condition {
-	// conditions for returning
-	conds := make([]domain.Condition, len(qb.conditions))
-
-	// copy query builder conditions
-	copy(conds, qb.conditions)
-
-	// return copy conditions
-	return conds
+	// return copy conditions, including nested conditions
+	if qb.conditions == nil {
+		return []domain.Condition{}
+	}
+	return cloneConditions(qb.conditions)
 }